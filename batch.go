@@ -0,0 +1,120 @@
+package walletscreener
+
+import (
+	"context"
+	"sync"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// ErrBatchTooLarge represents an error returned when batch holds more wallets than configured.
+var ErrBatchTooLarge = errors.New("batch has too many wallets")
+
+// Defaults of batch screening configuration.
+const (
+	DefaultBatchConcurrency = 8   // wallets screened at once
+	DefaultBatchMaxWallets  = 500 // wallets accepted per batch
+)
+
+// BatchConfig represents batch screening configuration.
+type BatchConfig struct {
+	Concurrency int `mapstructure:"concurrency"` // Wallets screened at once, DefaultBatchConcurrency if not set
+	MaxWallets  int `mapstructure:"maxwallets"`  // Wallets accepted per batch, DefaultBatchMaxWallets if not set
+}
+
+// limits returns configured concurrency and maximum number of wallets falling back to defaults, cfg may be nil.
+func (cfg *BatchConfig) limits() (concurrency int, maxWallets int) {
+	concurrency, maxWallets = DefaultBatchConcurrency, DefaultBatchMaxWallets
+	if cfg != nil && cfg.Concurrency > 0 {
+		concurrency = cfg.Concurrency
+	}
+	if cfg != nil && cfg.MaxWallets > 0 {
+		maxWallets = cfg.MaxWallets
+	}
+	return concurrency, maxWallets
+}
+
+// WalletsLimit returns number of wallets accepted per batch falling back to default, cfg may be nil.
+func (cfg *BatchConfig) WalletsLimit() int {
+	_, maxWallets := cfg.limits()
+	return maxWallets
+}
+
+// Wallet identifies a wallet by its address on the chain.
+type Wallet struct {
+	Chain   Chain
+	Address string
+}
+
+// WalletScreening represents outcome of screening a single wallet of a batch, either screening or error is set.
+type WalletScreening struct {
+	Wallet
+	Screening *Screening
+	Err       error
+}
+
+// ScreenWalletFunc screens a single wallet of a batch.
+type ScreenWalletFunc func(ctx context.Context, chain Chain, address string) (*Screening, error)
+
+// ScreenWallets screens wallets of a batch running at most configured number of screenings at once.
+// Wallet failing to be screened does not fail the batch, outcome of every wallet is returned in the order of wallets.
+// ErrBatchTooLarge is returned if batch holds more wallets than configured.
+func ScreenWallets(ctx context.Context, screen ScreenWalletFunc, cfg *BatchConfig, wallets []Wallet) ([]*WalletScreening, error) {
+	results := make([]*WalletScreening, len(wallets))
+	if err := ScreenWalletsEach(ctx, screen, cfg, wallets, func(i int, result *WalletScreening) {
+		results[i] = result
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ScreenWalletsEach screens wallets of a batch same as ScreenWallets reporting outcome of each wallet by its index as soon as it is known.
+// Reports are made one at a time and every one of them is made before ScreenWalletsEach returns.
+func ScreenWalletsEach(ctx context.Context, screen ScreenWalletFunc, cfg *BatchConfig, wallets []Wallet, report func(i int, result *WalletScreening)) error {
+	concurrency, maxWallets := cfg.limits()
+	if len(wallets) > maxWallets {
+		return errors.Wrapf(ErrBatchTooLarge, "got %d wallets, at most %d are accepted", len(wallets), maxWallets)
+	}
+
+	var (
+		sem = make(chan struct{}, concurrency)
+		mu  sync.Mutex // serializes reports
+		wg  sync.WaitGroup
+	)
+
+	for i, wallet := range wallets {
+		// wallets not started yet are reported as failed once caller is gone
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			report(i, &WalletScreening{Wallet: wallet, Err: err})
+			mu.Unlock()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			report(i, &WalletScreening{Wallet: wallet, Err: ctx.Err()})
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, result *WalletScreening) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result.Screening, result.Err = screen(ctx, result.Chain, result.Address)
+
+			mu.Lock()
+			report(i, result)
+			mu.Unlock()
+		}(i, &WalletScreening{Wallet: wallet})
+	}
+
+	wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package walletscreener
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+func TestScreenWallets(t *testing.T) {
+	var wallets []Wallet
+	for i := 0; i < 20; i++ {
+		wallets = append(wallets, Wallet{Chain: ChainEthereum, Address: strconv.Itoa(i)})
+	}
+
+	var running, maxRunning int32
+	screen := func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		if address == "3" {
+			return nil, ErrProviderUnavailable
+		}
+		return &Screening{ID: address, ScreeningResult: ScreeningResult{Chain: chain, Address: address}}, nil
+	}
+
+	results, err := ScreenWallets(context.Background(), screen, &BatchConfig{Concurrency: 4}, wallets)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if maxRunning > 4 {
+		t.Errorf("got %d screenings at once, want at most %d", maxRunning, 4)
+	}
+
+	if len(results) != len(wallets) {
+		t.Fatalf("got %d results, want %d", len(results), len(wallets))
+	}
+
+	for i, v := range results {
+		if v.Wallet != wallets[i] {
+			t.Errorf("#%d got %v, want %v", i, v.Wallet, wallets[i])
+		}
+
+		switch v.Address {
+		case "3":
+			if !errors.Is(v.Err, ErrProviderUnavailable) || v.Screening != nil {
+				t.Errorf("#%d got %v %v, want %v", i, v.Screening, v.Err, ErrProviderUnavailable)
+			}
+		default:
+			if v.Err != nil || v.Screening == nil || v.Screening.ID != v.Address {
+				t.Errorf("#%d got %v %v, want screening %s", i, v.Screening, v.Err, v.Address)
+			}
+		}
+	}
+}
+
+func TestScreenWalletsTooLarge(t *testing.T) {
+	screen := func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+		t.Errorf("unexpected screening of %s", address)
+		return nil, nil
+	}
+
+	wallets := []Wallet{{ChainEthereum, "a"}, {ChainEthereum, "b"}, {ChainEthereum, "c"}}
+
+	if _, err := ScreenWallets(context.Background(), screen, &BatchConfig{MaxWallets: 2}, wallets); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("got %v, want %v", err, ErrBatchTooLarge)
+	}
+}
+
+func TestScreenWalletsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int32
+	screen := func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, ctx.Err()
+	}
+
+	results, err := ScreenWallets(ctx, screen, nil, []Wallet{{ChainEthereum, "a"}, {ChainEthereum, "b"}})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	for i, v := range results {
+		if !errors.Is(v.Err, context.Canceled) {
+			t.Errorf("#%d got %v, want %v", i, v.Err, context.Canceled)
+		}
+	}
+
+	if calls != 0 {
+		t.Errorf("got %d screenings, want %d", calls, 0)
+	}
+}
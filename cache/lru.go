@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry represents cached value along its key and expiry.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is an in-memory cache of fixed size evicting least recently used entries.
+// Entries are expired after their time to live elapses. LRU is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List // front is the most recently used entry
+}
+
+// NewLRU constructs and returns new LRU cache holding up to size entries.
+func NewLRU[K comparable, V any](size int) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:    size,
+		now:     time.Now,
+		entries: make(map[K]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// Get returns value cached under key and whether it was found and is not expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	e := element.Value.(*entry[K, V])
+	if !c.now().Before(e.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return e.value, true
+}
+
+// Set caches value under key for ttl duration evicting least recently used entry if cache is full.
+// Non-positive ttl removes the key from the cache.
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	if ttl <= 0 || c.size < 1 {
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{
+		key:       key,
+		value:     value,
+		expiresAt: c.now().Add(ttl),
+	})
+}
+
+// Remove removes key from the cache.
+func (c *LRU[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Len returns number of cached entries, including expired ones not evicted yet.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove removes element from the cache, caller must hold the lock.
+func (c *LRU[K, V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry[K, V]).key)
+}
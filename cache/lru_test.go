@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+
+	c := NewLRU[string, int](2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+
+	// a becomes the most recently used entry
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("got %v %v, want %v %v", v, ok, 1, true)
+	}
+
+	// b is evicted as the least recently used entry
+	c.Set("c", 3, time.Minute)
+
+	var testcases = []struct {
+		key   string
+		value int
+		ok    bool
+	}{
+		{"a", 1, true},
+		{"b", 0, false},
+		{"c", 3, true},
+	}
+
+	for _, tt := range testcases {
+		if v, ok := c.Get(tt.key); v != tt.value || ok != tt.ok {
+			t.Errorf("key %s got %v %v, want %v %v", tt.key, v, ok, tt.value, tt.ok)
+		}
+	}
+
+	if l := c.Len(); l != 2 {
+		t.Errorf("len got %v, want %v", l, 2)
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	now := time.Now()
+
+	c := NewLRU[string, int](2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, 0) // not cached
+
+	now = now.Add(time.Minute)
+
+	if v, ok := c.Get("a"); ok {
+		t.Errorf("got %v %v, want expired entry", v, ok)
+	}
+
+	if v, ok := c.Get("b"); ok {
+		t.Errorf("got %v %v, want entry not cached", v, ok)
+	}
+
+	if l := c.Len(); l != 0 {
+		t.Errorf("len got %v, want %v", l, 0)
+	}
+}
@@ -0,0 +1,43 @@
+package walletscreener
+
+import (
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// ErrChainNotSupported is returned when operation is requested for a chain which is not supported.
+var ErrChainNotSupported = errors.New("chain is not supported")
+
+// Chain represents blockchain network identifier.
+type Chain string
+
+// Supported chains.
+const (
+	ChainEthereum Chain = "eth"   // Ethereum mainnet
+	ChainBitcoin  Chain = "btc"   // Bitcoin mainnet
+	ChainTron     Chain = "trx"   // Tron mainnet
+	ChainPolygon  Chain = "matic" // Polygon PoS
+	ChainSolana   Chain = "sol"   // Solana mainnet
+)
+
+// chains contains all supported chains.
+var chains = map[Chain]struct{}{
+	ChainEthereum: {},
+	ChainBitcoin:  {},
+	ChainTron:     {},
+	ChainPolygon:  {},
+	ChainSolana:   {},
+}
+
+// ParseChain parses given chain identifier and returns a Chain if it is supported.
+func ParseChain(s string) (Chain, error) {
+	chain := Chain(s)
+	if _, ok := chains[chain]; !ok {
+		return "", errors.Wrapf(ErrChainNotSupported, "chain %q", s)
+	}
+	return chain, nil
+}
+
+// String implements fmt.Stringer.
+func (c Chain) String() string {
+	return string(c)
+}
@@ -0,0 +1,48 @@
+package walletscreener
+
+// RiskChange represents change of wallet risk between its consecutive screenings.
+type RiskChange struct {
+	PreviousID        string   // ID of the previous screening of the wallet
+	PreviousVerdict   Verdict  // Verdict of the previous screening
+	AddedCategories   []string // Categories not reported by the previous screening, sorted
+	RemovedCategories []string // Categories reported by the previous screening only, sorted
+	RiskDelta         int      // Risk score difference from the previous screening
+}
+
+// NewRiskChange returns change of wallet risk from previous to current screening.
+func NewRiskChange(previous, current *Screening) *RiskChange {
+	change := RiskChange{
+		PreviousID:      previous.ID,
+		PreviousVerdict: previous.Verdict,
+		RiskDelta:       current.Risk - previous.Risk,
+	}
+
+	before := previous.Categories()
+	after := current.Categories()
+
+	change.AddedCategories = difference(after, before)
+	change.RemovedCategories = difference(before, after)
+
+	return &change
+}
+
+// Changed returns whether categories or risk score of the wallet have changed.
+func (c *RiskChange) Changed() bool {
+	return len(c.AddedCategories) > 0 || len(c.RemovedCategories) > 0 || c.RiskDelta != 0
+}
+
+// difference returns elements of a not present in b keeping order of a, nil if there are none.
+func difference(a, b []string) []string {
+	present := make(map[string]bool, len(b))
+	for _, v := range b {
+		present[v] = true
+	}
+
+	var result []string
+	for _, v := range a {
+		if !present[v] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package walletscreener
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewRiskChange(t *testing.T) {
+	screening := func(risk int, own []string, sourceOfFunds []string) *Screening {
+		s := &Screening{ID: "id", ScreeningResult: ScreeningResult{Risk: risk, Verdict: VerdictReview}}
+		for _, v := range own {
+			s.OwnCategories = append(s.OwnCategories, &RiskCategory{Name: v})
+		}
+		for _, v := range sourceOfFunds {
+			s.SourceOfFundsCategories = append(s.SourceOfFundsCategories, &RiskCategory{Name: v})
+		}
+		return s
+	}
+
+	var testcases = []struct {
+		previous *Screening
+		current  *Screening
+
+		change  *RiskChange
+		changed bool
+	}{
+		// nothing has changed
+		{
+			previous: screening(50, []string{"Gambling"}, nil),
+			current:  screening(50, []string{"Gambling"}, nil),
+			change:   &RiskChange{PreviousID: "id", PreviousVerdict: VerdictReview},
+			changed:  false,
+		},
+		// category moving between own and source of funds is not a change
+		{
+			previous: screening(50, []string{"Gambling"}, nil),
+			current:  screening(50, nil, []string{"Gambling"}),
+			change:   &RiskChange{PreviousID: "id", PreviousVerdict: VerdictReview},
+			changed:  false,
+		},
+		// categories added and removed
+		{
+			previous: screening(50, []string{"Gambling", "Mixer"}, nil),
+			current:  screening(90, []string{"Darknet", "Sanctions"}, []string{"Mixer"}),
+			change: &RiskChange{
+				PreviousID:        "id",
+				PreviousVerdict:   VerdictReview,
+				AddedCategories:   []string{"Darknet", "Sanctions"},
+				RemovedCategories: []string{"Gambling"},
+				RiskDelta:         40,
+			},
+			changed: true,
+		},
+		// risk score decreased only
+		{
+			previous: screening(50, []string{"Gambling"}, nil),
+			current:  screening(30, []string{"Gambling"}, nil),
+			change:   &RiskChange{PreviousID: "id", PreviousVerdict: VerdictReview, RiskDelta: -20},
+			changed:  true,
+		},
+	}
+
+	for i, tt := range testcases {
+		change := NewRiskChange(tt.previous, tt.current)
+
+		if diff := cmp.Diff(tt.change, change); diff != "" {
+			t.Errorf("#%d change mismatch (-want +got):\n%s", i, diff)
+		}
+
+		if changed := change.Changed(); changed != tt.changed {
+			t.Errorf("#%d changed got %v, want %v", i, changed, tt.changed)
+		}
+	}
+}
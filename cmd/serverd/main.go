@@ -9,11 +9,19 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/config"
+	"github.com/deividaspetraitis/wallet-screener/database"
+	db "github.com/deividaspetraitis/wallet-screener/database/immudb"
+	"github.com/deividaspetraitis/wallet-screener/database/memory"
+	"github.com/deividaspetraitis/wallet-screener/database/sqldb"
 	"github.com/deividaspetraitis/wallet-screener/errors"
 	ihttp "github.com/deividaspetraitis/wallet-screener/http"
+	"github.com/deividaspetraitis/wallet-screener/jobs"
 	"github.com/deividaspetraitis/wallet-screener/log"
 	"github.com/deividaspetraitis/wallet-screener/riskprovider"
+	"github.com/deividaspetraitis/wallet-screener/watchlist"
+	"github.com/deividaspetraitis/wallet-screener/webhook"
 
 	immudb "github.com/codenotary/immudb/pkg/client"
 )
@@ -60,39 +68,67 @@ func run(cfg *config.Config, logger log.Logger) error {
 	// =========================================================================
 	// Construct services
 
-	// even though the server address and port are defaults, setting them as a reference
-	opts := immudb.DefaultOptions().WithAddress(cfg.Database.Host).WithPort(cfg.Database.Port)
-
-	// construct a new immudb client
-	immudbclient := immudb.NewClient().WithOptions(opts)
+	// Construct store of screening results.
+	store, closeStore, err := newStore(cfg.Database)
+	if err != nil {
+		return errors.Wrap(err, "unable to construct screening store")
+	}
 
-	// connect with immudb server (user, password, database)
-	err := immudbclient.OpenSession(context.Background(), []byte(cfg.Database.Username), []byte(cfg.Database.Password), cfg.Database.Database)
+	// Construct risk policy evaluating screening results.
+	policy, err := walletscreener.NewPolicy(cfg.Policy)
 	if err != nil {
-		return errors.Wrap(err, "unable connect to immudb instance")
+		return errors.Wrap(err, "unable to construct risk policy")
 	}
 
-	// Construct risk provider API client.
-	httpclient, err := ihttp.NewClient(
-		"https://api.blockmate.io/v1",                  // API URL ( unlikely to change )
-		ihttp.WithHeader("Accept", "application/json"), // speaks with JSON
-	)
+	// Construct risk provider screening wallets, API clients of providers are kept for their metrics.
+	clients := make(map[string]*ihttp.Client)
+	riskprovider, err := newRiskProviders(cfg, logger, clients)
 	if err != nil {
-		logger.WithError(err).Fatal("unable to construct Blockmate risk provider client")
+		return errors.Wrap(err, "unable to construct risk provider")
 	}
 
-	// Construct specific risk provider.
-	riskprovider, err := riskprovider.NewBlockMate(cfg.RiskProvider.Blockmate.APIKey, httpclient)
+	// Cache screening results if enabled.
+	riskprovider, err = newCache(riskprovider, policy, &cfg.RiskProvider.Cache)
 	if err != nil {
-		logger.WithError(err).Fatal("unable to construct Blockmate risk provider")
+		return errors.Wrap(err, "unable to construct risk provider cache")
+	}
+
+	// Construct dispatcher notifying webhook subscriptions about events of stored screenings.
+	dispatcher := webhook.NewDispatcher(store, cfg.Webhooks, logger)
+	dispatcher.Start()
+
+	// Wallets are screened, stored and notified about same way whether requested or screened in background,
+	// concurrent screenings of the same wallet share a single provider call and stored record across all of them.
+	var screenings walletscreener.ScreeningGroup
+	storeScreening := walletscreener.WithNotify(store.StoreScreening, dispatcher.Notify)
+	screen := func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		return screenings.ScreenWalletRiskCategories(ctx, riskprovider, policy, store.GetLatestScreening, storeScreening, chain, address)
 	}
 
+	// Construct job queue and workers screening wallets in background.
+	queue := jobs.NewQueue(cfg.Jobs)
+	workers := jobs.NewPool(queue, screen, cfg.Batch, cfg.Jobs, logger)
+	workers.Start()
+
+	// Construct scheduler re-screening watched wallets.
+	scheduler := watchlist.NewScheduler(store, screen, cfg.Watchlist, logger)
+	scheduler.Start()
+
 	// =========================================================================
 	// Start HTTP server
 
+	// Metrics of requests made to providers, including retries, are exposed by API.
+	providerMetrics := func() map[string]ihttp.ClientMetrics {
+		metrics := make(map[string]ihttp.ClientMetrics)
+		for name, client := range clients {
+			metrics[name] = client.Metrics()
+		}
+		return metrics
+	}
+
 	api := http.Server{
 		Addr:    cfg.HTTP.Address,
-		Handler: ihttp.API(shutdown, cfg.HTTP, logger, riskprovider, immudbclient),
+		Handler: ihttp.API(shutdown, cfg.HTTP, logger, screen, store, cfg.Batch, queue, store, scheduler.Jitter, store, providerMetrics),
 	}
 
 	go func() {
@@ -115,17 +151,37 @@ func run(cfg *config.Config, logger log.Logger) error {
 		ctx, cancel := context.WithTimeout(context.Background(), shutdowntimeout)
 		defer cancel()
 
-		if err := immudbclient.CloseSession(ctx); err != nil {
-			logger.WithError(err).Error("graceful shutdown did not complete")
-		}
-
-		// Asking listener to shutdown and load shed.
+		// Asking listener to shutdown and load shed, no jobs, watches or webhooks are accepted from now on.
 		err := api.Shutdown(ctx)
 		if err != nil {
 			logger.WithError(err).Error("graceful shutdown did not complete")
 			api.Close()
 		}
 
+		// Drain pending jobs while store is still open, jobs not started in time are canceled and running ones are waited for.
+		if err := workers.Shutdown(context.Background()); err != nil {
+			logger.WithError(err).Error("jobs were not drained")
+		}
+
+		// Interrupt re-screenings in progress, interrupted wallets are re-screened once service is started again.
+		if err := scheduler.Shutdown(context.Background()); err != nil {
+			logger.WithError(err).Error("re-screenings were not interrupted")
+		}
+
+		// Interrupt webhook deliveries in progress, interrupted deliveries are attempted once service is started again.
+		if err := dispatcher.Shutdown(context.Background()); err != nil {
+			logger.WithError(err).Error("webhook deliveries were not interrupted")
+		}
+
+		// Close store once HTTP server, jobs, re-screenings and deliveries are done using it,
+		// store is given its own deadline since draining them may take up the one of outstanding requests.
+		storeCtx, storeCancel := context.WithTimeout(context.Background(), shutdowntimeout)
+		defer storeCancel()
+
+		if err := closeStore(storeCtx); err != nil {
+			logger.WithError(err).Error("store was not closed")
+		}
+
 		// Log the status of this shutdown.
 		switch {
 		case sig == syscall.SIGSTOP:
@@ -137,3 +193,159 @@ func run(cfg *config.Config, logger log.Logger) error {
 
 	return nil
 }
+
+// storage represents storage of screening results, watched wallets and webhooks.
+type storage interface {
+	walletscreener.ScreeningStore
+	walletscreener.WatchlistStore
+	walletscreener.WebhookStore
+}
+
+// newStore constructs store of screening results, watched wallets and webhooks according to configured database driver.
+// Returned function closes connection to the database.
+func newStore(cfg *database.Config) (storage, func(ctx context.Context) error, error) {
+	switch cfg.Driver {
+	case database.DriverMemory:
+		return memory.NewStore(), func(ctx context.Context) error { return nil }, nil
+	case database.DriverSQLite, database.DriverPostgres:
+		store, err := sqldb.Open(context.Background(), cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func(ctx context.Context) error { return store.Close() }, nil
+	case database.DriverImmudb, "":
+		// even though the server address and port are defaults, setting them as a reference
+		opts := immudb.DefaultOptions().WithAddress(cfg.Host).WithPort(cfg.Port)
+
+		// trusted state reads are verified against must survive restarts, otherwise tampering before restart goes unnoticed
+		if cfg.StateDir != "" {
+			if err := os.MkdirAll(cfg.StateDir, 0o700); err != nil {
+				return nil, nil, errors.Wrap(err, "unable to create immudb state directory")
+			}
+			opts = opts.WithDir(cfg.StateDir)
+		}
+
+		// construct a new immudb client
+		immudbclient := immudb.NewClient().WithOptions(opts)
+
+		// connect with immudb server (user, password, database)
+		err := immudbclient.OpenSession(context.Background(), []byte(cfg.Username), []byte(cfg.Password), cfg.Database)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "unable connect to immudb instance")
+		}
+
+		store := db.NewStore(immudbclient)
+
+		// history stored by earlier versions under plain Ethereum addresses is migrated before screenings are stored
+		if err := store.MigrateLegacyKeys(context.Background()); err != nil {
+			return nil, nil, errors.Wrap(err, "unable to migrate legacy immudb keys")
+		}
+
+		// deliveries stored by earlier versions are indexed before dispatcher reads ones due
+		if err := store.IndexPendingDeliveries(context.Background()); err != nil {
+			return nil, nil, errors.Wrap(err, "unable to index pending webhook deliveries")
+		}
+
+		return store, immudbclient.CloseSession, nil
+	default:
+		return nil, nil, errors.Newf("unknown database driver %s", cfg.Driver)
+	}
+}
+
+// newRiskProviders constructs risk provider screening wallets according to configuration.
+// Providers configured for consensus are queried in parallel, otherwise providers are tried in order of failover.
+// Blockmate is used when neither is configured. API clients of constructed providers are registered into clients by provider name.
+func newRiskProviders(cfg *config.Config, logger log.Logger, clients map[string]*ihttp.Client) (walletscreener.WalletRiskScreeningProvider, error) {
+	if consensus := cfg.RiskProvider.Consensus; len(consensus.Providers) > 0 {
+		var providers []*riskprovider.ConsensusProvider
+		for _, name := range consensus.Providers {
+			provider, providerCfg, err := newRiskProvider(cfg, name, clients)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to construct %s risk provider", name)
+			}
+
+			providers = append(providers, &riskprovider.ConsensusProvider{
+				Name:     name,
+				Provider: provider,
+				Weight:   providerCfg.Weight,
+				Timeout:  providerCfg.Timeout,
+			})
+		}
+
+		quorum := consensus.Quorum
+		if quorum < 1 {
+			quorum = len(providers) // all providers must agree by default
+		}
+
+		return riskprovider.NewConsensus(logger, quorum, providers...)
+	}
+
+	failover := cfg.RiskProvider.Failover
+	if len(failover) < 1 {
+		failover = []string{riskprovider.BlockmateName}
+	}
+
+	var providers []*riskprovider.FailoverProvider
+	for _, name := range failover {
+		provider, providerCfg, err := newRiskProvider(cfg, name, clients)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to construct %s risk provider", name)
+		}
+
+		providers = append(providers, &riskprovider.FailoverProvider{
+			Name:     name,
+			Provider: provider,
+			Timeout:  providerCfg.Timeout,
+		})
+	}
+
+	return riskprovider.NewFailover(logger, providers...)
+}
+
+// newRiskProvider constructs risk provider identified by name and returns it along its configuration.
+// API client of the provider is registered into clients.
+func newRiskProvider(cfg *config.Config, name string, clients map[string]*ihttp.Client) (walletscreener.WalletRiskScreeningProvider, *riskprovider.Config, error) {
+	switch name {
+	case riskprovider.BlockmateName:
+		// Construct risk provider API client.
+		httpclient, err := ihttp.NewClient(
+			"https://api.blockmate.io/v1",                  // API URL ( unlikely to change )
+			ihttp.WithHeader("Accept", "application/json"), // speaks with JSON
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		httpclient.SetRetryPolicy(cfg.RiskProvider.Blockmate.Retry)
+		clients[name] = httpclient
+
+		blockmate, err := riskprovider.NewBlockMate(cfg.RiskProvider.Blockmate.APIKey, httpclient)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		provider, err := newCircuitBreaker(name, blockmate, &cfg.RiskProvider.Blockmate)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return provider, &cfg.RiskProvider.Blockmate, nil
+	default:
+		return nil, nil, errors.Newf("unknown risk provider %s", name)
+	}
+}
+
+// newCircuitBreaker guards provider with circuit breaker if it is enabled by provider configuration.
+func newCircuitBreaker(name string, provider walletscreener.WalletRiskScreeningProvider, cfg *riskprovider.Config) (walletscreener.WalletRiskScreeningProvider, error) {
+	if !cfg.Breaker.Enabled() {
+		return provider, nil
+	}
+	return riskprovider.NewCircuitBreaker(name, provider, cfg.Breaker)
+}
+
+// newCache caches results of provider if it is enabled by cache configuration.
+func newCache(provider walletscreener.WalletRiskScreeningProvider, policy *walletscreener.Policy, cfg *riskprovider.CacheConfig) (walletscreener.WalletRiskScreeningProvider, error) {
+	if !cfg.Enabled() {
+		return provider, nil
+	}
+	return riskprovider.NewCache(provider, policy, *cfg)
+}
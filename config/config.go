@@ -1,25 +1,50 @@
 package config
 
 import (
+	"os"
 	"strings"
 
+	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/database"
 	"github.com/deividaspetraitis/wallet-screener/errors"
 	"github.com/deividaspetraitis/wallet-screener/http"
+	"github.com/deividaspetraitis/wallet-screener/jobs"
 	"github.com/deividaspetraitis/wallet-screener/riskprovider"
+	"github.com/deividaspetraitis/wallet-screener/watchlist"
+	"github.com/deividaspetraitis/wallet-screener/webhook"
 
 	"github.com/spf13/viper"
 )
 
+// envMapPrefixes are prefixes of environment variables configuring maps keyed by values such as chains or verdicts.
+// AutomaticEnv overrides only keys known from the configuration file, variables of these maps are bound explicitly instead
+// so that the environment configures keys not present in the configuration file as well.
+var envMapPrefixes = []string{
+	"POLICY_CHAINS_",
+	"RISKPROVIDER_CACHE_VERDICTS_",
+	"RISKPROVIDER_CACHE_CHAINS_",
+}
+
 // ErrConfigNotFound represents an error returned when configuration file was not found.
 var ErrConfigNotFound = errors.New("config file were not found")
 
 // Config represents application configuration.
 type Config struct {
-	HTTP         *http.Config     `mapstructure:"http"` // HTTP server config.
-	Database     *database.Config `mapstructure:"db"`   // Database instance config.
+	HTTP         *http.Config                 `mapstructure:"http"`      // HTTP server config.
+	Database     *database.Config             `mapstructure:"db"`        // Database instance config.
+	Policy       *walletscreener.PolicyConfig `mapstructure:"policy"`    // Risk policy config.
+	Batch        *walletscreener.BatchConfig  `mapstructure:"batch"`     // Batch screening config, defaults are used if not set.
+	Jobs         *jobs.Config                 `mapstructure:"jobs"`      // Background jobs config, defaults are used if not set.
+	Watchlist    *watchlist.Config            `mapstructure:"watchlist"` // Re-screening of watched wallets config, defaults are used if not set.
+	Webhooks     *webhook.Config              `mapstructure:"webhooks"`  // Webhook deliveries config, defaults are used if not set.
 	RiskProvider *struct {
-		Blockmate riskprovider.Config `mapstructure:"blockmate"`
+		Failover  []string `mapstructure:"failover"` // Ordered list of providers names to fail over.
+		Consensus struct {
+			Providers []string `mapstructure:"providers"` // Providers queried in parallel, takes precedence over failover.
+			Quorum    int      `mapstructure:"quorum"`    // Minimum number of providers required to succeed.
+		} `mapstructure:"consensus"`
+		Cache     riskprovider.CacheConfig `mapstructure:"cache"` // Screening results cache, disabled unless size is set.
+		Blockmate riskprovider.Config      `mapstructure:"blockmate"`
 	} `mapstructure:"riskprovider"`
 }
 
@@ -39,6 +64,18 @@ func New(path string) (*Config, error) {
 	// Check and load environment variables
 	parser.AutomaticEnv()
 
+	// Bind environment variables of maps, these are not known from the config file
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		for _, prefix := range envMapPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				if err := parser.BindEnv(strings.ToLower(name), name); err != nil {
+					return nil, errors.Wrapf(err, "failed to bind environment variable: %s", name)
+				}
+			}
+		}
+	}
+
 	// Read configuration values
 	if err := parser.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewEnvMaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("POLICY_REVIEW_SCORE=50\nPOLICY_CHAINS_ETH_REVIEW_SCORE=40\n"), 0o600); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// keys of maps not present in the config file are configured by the environment
+	t.Setenv("POLICY_CHAINS_TRX_REVIEW_SCORE", "30")
+	t.Setenv("RISKPROVIDER_CACHE_VERDICTS_BLOCK", "1h")
+	t.Setenv("RISKPROVIDER_CACHE_CHAINS_TRX", "1m")
+
+	cfg, err := New(path)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if got := cfg.Policy.Review.Score; got != 50 {
+		t.Errorf("got %v, want %v", got, 50)
+	}
+
+	var testcases = []struct {
+		chain    string
+		expected int
+	}{
+		{"eth", 40},
+		{"trx", 30},
+	}
+
+	for i, tt := range testcases {
+		override, ok := cfg.Policy.Chains[tt.chain]
+		if !ok {
+			t.Fatalf("#%d got no override of %s, want %v", i, tt.chain, tt.expected)
+		}
+		if got := override.Review.Score; got != tt.expected {
+			t.Errorf("#%d got %v, want %v", i, got, tt.expected)
+		}
+	}
+
+	if got := cfg.RiskProvider.Cache.Verdicts["block"]; got != time.Hour {
+		t.Errorf("got %v, want %v", got, time.Hour)
+	}
+	if got := cfg.RiskProvider.Cache.Chains["trx"]; got != time.Minute {
+		t.Errorf("got %v, want %v", got, time.Minute)
+	}
+}
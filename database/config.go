@@ -1,10 +1,21 @@
 package database
 
+// Supported database drivers.
+const (
+	DriverImmudb   = "immudb"   // immudb server, default
+	DriverMemory   = "memory"   // in-memory storage for tests and local development, data is lost on shutdown
+	DriverSQLite   = "sqlite"   // SQLite database stored in the file named by Database
+	DriverPostgres = "postgres" // PostgreSQL server
+)
+
 // Config represents database configuration.
 type Config struct {
+	Driver   string `mapstructure:"driver"`   // database driver, DriverImmudb when empty
 	Host     string `mapstructure:"host"`     // server address
 	Port     int    `mapstructure:"port"`     // server port
 	Username string `mapstructure:"username"` // user
 	Password string `mapstructure:"password"` // pass
 	Database string `mapstructure:"database"` // database
+	SSLMode  string `mapstructure:"sslmode"`  // PostgreSQL SSL mode, e.g. disable
+	StateDir string `mapstructure:"statedir"` // directory immudb trusted state is persisted into, working directory when empty
 }
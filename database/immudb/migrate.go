@@ -0,0 +1,167 @@
+package immudb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/validator"
+)
+
+// migratedPrefix is a prefix of keys marking legacy wallet keys whose history was migrated.
+const migratedPrefix = "migrated:"
+
+// legacyMigratedKey is a database key marking that history of every legacy wallet key was migrated.
+// It is not a valid address hence it never collides with keys marking migration of a single legacy key.
+var legacyMigratedKey = []byte(migratedPrefix + "legacy")
+
+// legacyPrefix is a prefix every legacy wallet key has, keys stored since have a prefix of their own.
+const legacyPrefix = "0x"
+
+// migratedKey returns a database key marking that history of the legacy key was migrated.
+// Keys are laid out as migrated:{address}.
+func migratedKey(legacy []byte) []byte {
+	return append([]byte(migratedPrefix), legacy...)
+}
+
+// isLegacyWalletKey returns whether key stores screenings of Ethereum wallet under the layout of earlier versions.
+// Earlier versions stored screenings under the plain wallet address, hence only keys being valid addresses are legacy ones.
+func isLegacyWalletKey(key []byte) bool {
+	return validator.EVMAddress(string(key)) == nil
+}
+
+// MigrateLegacyKeys copies history of Ethereum wallets stored by earlier versions under the plain wallet address
+// to the key of the wallet on Ethereum chain, see walletKey. Legacy keys are kept intact since keys cannot be removed from immudb.
+// Addresses are converted to their EIP-55 checksummed form, history of the same wallet stored under differently cased addresses is merged.
+// Copied screenings keep their IDs and are verified against trusted state before they are copied.
+// Migration is resumed where it stopped if interrupted and migrated keys are skipped, once complete it is recorded so that
+// database is not scanned again, hence it is safe to call on every start.
+// It must complete before new screenings are stored, otherwise migrated history would follow them.
+func (s *Store) MigrateLegacyKeys(ctx context.Context) error {
+	_, err := s.db.Get(ctx, legacyMigratedKey)
+	if err == nil {
+		return nil
+	}
+	if !isKeyNotFound(err) {
+		return errors.Wrap(err, "failed to check legacy wallet keys migration")
+	}
+
+	var (
+		addresses []string
+		legacy    = make(map[string][][]byte) // legacy keys by address they are migrated to
+	)
+	err = s.scan(ctx, []byte(legacyPrefix), func(key, value []byte) error {
+		if !isLegacyWalletKey(key) {
+			return nil
+		}
+
+		address := validator.NormalizeEVMAddress(string(key))
+
+		if _, ok := legacy[address]; !ok {
+			addresses = append(addresses, address)
+		}
+		legacy[address] = append(legacy[address], key)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to scan legacy wallet keys")
+	}
+
+	for _, address := range addresses {
+		if err := s.migrateLegacyKeys(ctx, address, legacy[address]); err != nil {
+			return errors.Wrapf(err, "failed to migrate history of address %s", address)
+		}
+	}
+
+	if _, err := s.db.Set(ctx, legacyMigratedKey, []byte("done")); err != nil {
+		return errors.Wrap(err, "failed to mark legacy wallet keys as migrated")
+	}
+
+	return nil
+}
+
+// migrateLegacyKeys copies history of legacy keys not migrated yet to the key of address, oldest screenings first.
+func (s *Store) migrateLegacyKeys(ctx context.Context, address string, keys [][]byte) error {
+	var pending [][]byte
+	for _, key := range keys {
+		_, err := s.db.Get(ctx, migratedKey(key))
+		if isKeyNotFound(err) {
+			pending = append(pending, key)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pending) < 1 {
+		return nil
+	}
+
+	// screenings copied before migration was interrupted are not copied again
+	copied := make(map[string]bool)
+	page, err := s.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: address})
+	if err != nil {
+		return err
+	}
+	for _, v := range page.Screenings {
+		copied[v.ID] = true
+	}
+
+	var screenings []*walletscreener.Screening
+	for _, key := range pending {
+		entries, err := s.legacyHistory(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		screenings = append(screenings, decodeScreenings(walletscreener.ChainEthereum, address, entries)...)
+	}
+
+	// history of each key is ordered already, histories of several keys are interleaved by time of screenings
+	sort.SliceStable(screenings, func(i, j int) bool {
+		return screenings[i].ScreenedAt.Before(screenings[j].ScreenedAt)
+	})
+
+	for _, v := range screenings {
+		if copied[v.ID] {
+			continue
+		}
+
+		if err := s.StoreScreening(ctx, v); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range pending {
+		if _, err := s.db.Set(ctx, migratedKey(key), []byte(address)); err != nil {
+			return errors.Wrap(err, "failed to mark history as migrated")
+		}
+	}
+
+	return nil
+}
+
+// legacyHistory returns the whole history of the legacy key verified against trusted state, oldest entry first.
+func (s *Store) legacyHistory(ctx context.Context, key []byte) ([]*schema.Entry, error) {
+	var entries []*schema.Entry
+	for offset := uint64(0); ; offset += historyPageSize {
+		history, err := s.db.History(ctx, &schema.HistoryRequest{
+			Key:    key,
+			Offset: offset,
+			Limit:  historyPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, history.GetEntries()...)
+		if len(history.GetEntries()) < historyPageSize {
+			break
+		}
+	}
+
+	return s.verifyEntries(ctx, key, entries)
+}
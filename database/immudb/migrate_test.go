@@ -0,0 +1,84 @@
+package immudb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/validator"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	// categories stored by earlier versions under plain addresses in different case, one screening per transaction
+	for _, v := range []struct {
+		address string
+		value   string
+	}{
+		{testAddress, "Gambling"},
+		{"0x" + strings.ToUpper(testAddress[2:]), `{"category":"Darknet","risk":60,"source":"own","score":60,"screened":"2023-10-04T15:18:23Z"}`},
+		{testAddress, `{"category":"Mixer","risk":80,"source":"own","score":80,"screened":"2023-10-05T15:18:23Z"}`},
+	} {
+		if _, err := s.db.Set(ctx, []byte(v.address), []byte(v.value)); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// keys without prefix which are not addresses are not legacy wallet keys
+	if _, err := s.db.Set(ctx, []byte("settings"), []byte("Gambling")); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	address := validator.NormalizeEVMAddress(testAddress)
+
+	// migration is safe to run again
+	for i := 0; i < 2; i++ {
+		if err := s.MigrateLegacyKeys(ctx); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// database is not scanned once migration completed
+	other := "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+	if _, err := s.db.Set(ctx, []byte(other), []byte("Darknet")); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if err := s.MigrateLegacyKeys(ctx); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	for _, v := range []string{other, "settings"} {
+		if _, err := s.GetLatestScreening(ctx, walletscreener.ChainEthereum, v); !errors.Is(err, walletscreener.ErrScreeningNotFound) {
+			t.Errorf("%s got %v, want %v", v, err, walletscreener.ErrScreeningNotFound)
+		}
+	}
+
+	page, err := s.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: address})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var got [][]string
+	for _, v := range page.Screenings {
+		got = append(got, v.Categories())
+	}
+
+	if expected := [][]string{{"Gambling"}, {"Darknet"}, {"Mixer"}}; !cmp.Equal(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+
+	latest, err := s.GetLatestScreening(ctx, walletscreener.ChainEthereum, address)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if latest.Risk != 80 || latest.ID != page.Screenings[2].ID {
+		t.Errorf("got screening %s with risk %d, want screening %s with risk %d", latest.ID, latest.Risk, page.Screenings[2].ID, 80)
+	}
+}
@@ -0,0 +1,407 @@
+package immudb
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"strings"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ProofFormat identifies format of Proof in walletscreener.ScreeningProof.
+const ProofFormat = "immudb-v1"
+
+// Proof is a self-contained proof of a screening stored in immudb, it is verified by Verify without a database connection.
+//
+// Proof is encoded into JSON object:
+//
+//	{
+//	  "entries": [ ... ], // key values screening is stored as, each one a schema.VerifiableEntry encoded by protojson
+//	  "state": { ... }    // database state entries are proven against, a schema.ImmutableState encoded by protojson
+//	}
+//
+// Each entry holds stored screening record in its value, proof of its inclusion in the transaction it was stored by,
+// and proof of consistency between that transaction and the state. State identifies database by its transaction ID
+// and hash, and is signed when immudb server is configured with a signing key.
+// Screening records are stored as a single entry, screenings stored by earlier versions span an entry per category.
+type Proof struct {
+	Entries []*schema.VerifiableEntry
+	State   *schema.ImmutableState
+}
+
+// proofJSON represents JSON encoding of Proof.
+type proofJSON struct {
+	Entries []json.RawMessage `json:"entries"`
+	State   json.RawMessage   `json:"state"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *Proof) MarshalJSON() ([]byte, error) {
+	var v proofJSON
+	for _, entry := range p.Entries {
+		b, err := protojson.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		v.Entries = append(v.Entries, b)
+	}
+
+	state, err := protojson.Marshal(p.State)
+	if err != nil {
+		return nil, err
+	}
+	v.State = state
+
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	var v proofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*p = Proof{
+		State: &schema.ImmutableState{},
+	}
+
+	for _, b := range v.Entries {
+		var entry schema.VerifiableEntry
+		if err := protojson.Unmarshal(b, &entry); err != nil {
+			return err
+		}
+		p.Entries = append(p.Entries, &entry)
+	}
+
+	return protojson.Unmarshal(v.State, p.State)
+}
+
+// ErrNoTrustAnchor is returned by Proof.Verify if verifier gives no trusted state to verify proof against.
+var ErrNoTrustAnchor = errors.New("proof can not be verified without trust anchor")
+
+// TrustAnchor identifies what verifier of Proof trusts, proof holds only if its state is linked to it.
+// At least one of server signing key or trusted transaction must be given.
+type TrustAnchor struct {
+	Key *ecdsa.PublicKey // public key immudb server signs its state with
+
+	// Transaction verifier obtained from a trusted source, e.g. database state published by the service.
+	// It has to be the transaction of the proof state or one of transactions screening was stored by.
+	TxID   uint64
+	TxHash []byte
+}
+
+// Verify verifies that every entry of the proof is included in its transaction and that transaction is consistent with the proof state.
+// Proof state is then verified against the trust anchor: its signature by the server signing key and/or the trusted transaction.
+// ErrNoTrustAnchor is returned if anchor holds neither of them, since proof verified only against itself proves nothing.
+// Error wrapping walletscreener.ErrScreeningTampered is returned if proof does not hold.
+func (p *Proof) Verify(anchor TrustAnchor) error {
+	if anchor.Key == nil && anchor.TxID == 0 {
+		return ErrNoTrustAnchor
+	}
+
+	if len(p.Entries) < 1 || p.State == nil {
+		return errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("proof is incomplete"))
+	}
+
+	// entries are proven against the state, state of an empty database proves nothing
+	if p.State.GetTxId() == 0 {
+		return errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("proof state is empty"))
+	}
+
+	if anchor.Key != nil {
+		if ok, err := p.State.CheckSignature(anchor.Key); err != nil || !ok {
+			return errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("state signature is not valid"))
+		}
+	}
+
+	for i, v := range p.Entries {
+		if err := verifyEntry(v, p.State); err != nil {
+			return errors.WithReason(walletscreener.ErrScreeningTampered, errors.Wrapf(err, "entry %d", i))
+		}
+	}
+
+	if anchor.TxID > 0 {
+		alh, ok := p.txHash(anchor.TxID)
+		if !ok {
+			return errors.Newf("trusted transaction %d is neither the proof state nor transaction of its entries", anchor.TxID)
+		}
+
+		if !bytes.Equal(alh, anchor.TxHash) {
+			return errors.WithReason(walletscreener.ErrScreeningTampered, errors.Newf("proof does not match trusted transaction %d", anchor.TxID))
+		}
+	}
+
+	return nil
+}
+
+// txHash returns hash of the transaction proof state or its entries were proven with, false is returned if proof holds no such transaction.
+// Proof entries must be verified beforehand, header of entry transaction is trusted only once proven consistent with the state.
+func (p *Proof) txHash(id uint64) ([]byte, bool) {
+	if p.State.GetTxId() == id {
+		return p.State.GetTxHash(), true
+	}
+
+	for _, v := range p.Entries {
+		if v.GetEntry().GetTx() != id {
+			continue
+		}
+
+		// entry transaction is the source of dual proof
+		proof, err := dualProofFromProto(v.GetVerifiableTx().GetDualProof())
+		if err != nil {
+			return nil, false
+		}
+		alh := proof.SourceTxHeader.Alh()
+		return alh[:], true
+	}
+
+	return nil, false
+}
+
+// Screening decodes screening stored in the proof entries.
+// Decoded screening can be trusted only once proof is verified.
+func (p *Proof) Screening() (*walletscreener.Screening, error) {
+	var entries []*schema.Entry
+	for _, v := range p.Entries {
+		if v.GetEntry() == nil {
+			return nil, errors.New("proof entry is empty")
+		}
+		entries = append(entries, v.GetEntry())
+	}
+
+	if len(entries) < 1 {
+		return nil, errors.New("proof has no entries")
+	}
+
+	// keys are laid out as {chain}:{address}
+	key := string(entries[0].GetKey())
+	name, address, _ := strings.Cut(key, ":")
+
+	chain, err := walletscreener.ParseChain(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "proof key %s", key)
+	}
+
+	for _, v := range entries {
+		if string(v.GetKey()) != key {
+			return nil, errors.Newf("proof entries are stored under different keys %s and %s", key, v.GetKey())
+		}
+	}
+
+	groups := groupEntries(entries)
+	if len(groups) != 1 {
+		return nil, errors.Newf("proof entries hold %d screenings, want 1", len(groups))
+	}
+
+	return decodeScreening(chain, address, groups[0]), nil
+}
+
+// verifyEntry verifies that entry is included in its transaction and that transaction is consistent with the state.
+// Entry transaction is the source of dual proof leading to the state, entry is proven against header of the source transaction only,
+// since it is the header hashing to the value proven by dual proof. Entries stored after the state are not proven by it.
+func verifyEntry(entry *schema.VerifiableEntry, state *schema.ImmutableState) error {
+	kv := entry.GetEntry()
+	if kv == nil || entry.GetInclusionProof() == nil {
+		return errors.New("entry proof is incomplete")
+	}
+
+	if kv.GetReferencedBy() != nil {
+		return errors.New("entry is a reference, screenings are never stored as references")
+	}
+
+	if kv.GetTx() == 0 || kv.GetTx() > state.GetTxId() {
+		return errors.Newf("entry transaction %d is not proven by state transaction %d", kv.GetTx(), state.GetTxId())
+	}
+
+	proof, err := dualProofFromProto(entry.GetVerifiableTx().GetDualProof())
+	if err != nil {
+		return err
+	}
+
+	header := proof.SourceTxHeader
+	if !store.VerifyDualProof(proof, kv.GetTx(), state.GetTxId(), header.Alh(), schema.DigestFromProto(state.GetTxHash())) {
+		return errors.New("entry transaction is not consistent with the state")
+	}
+
+	entrySpecDigest, err := store.EntrySpecDigestFor(header.Version)
+	if err != nil {
+		return err
+	}
+
+	spec := database.EncodeEntrySpec(kv.GetKey(), schema.KVMetadataFromProto(kv.GetMetadata()), kv.GetValue())
+	if !store.VerifyInclusion(schema.InclusionProofFromProto(entry.GetInclusionProof()), entrySpecDigest(spec), header.Eh) {
+		return errors.New("entry is not included in its transaction")
+	}
+
+	return nil
+}
+
+// dualProofFromProto converts dual proof decoded from untrusted input, error is returned for proofs missing fields conversion requires.
+func dualProofFromProto(proof *schema.DualProof) (*store.DualProof, error) {
+	if proof.GetSourceTxHeader() == nil || proof.GetTargetTxHeader() == nil || proof.GetLinearProof() == nil {
+		return nil, errors.New("dual proof is incomplete")
+	}
+
+	for _, v := range proof.GetLinearAdvanceProof().GetInclusionProofs() {
+		if v == nil {
+			return nil, errors.New("dual proof is incomplete")
+		}
+	}
+
+	return schema.DualProofFromProto(proof), nil
+}
+
+// GetScreeningProof implements walletscreener.ScreeningProver.
+// Screening is verified against trusted state before it is proven against the current database state.
+func (s *Store) GetScreeningProof(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+	key := walletKey(chain, address)
+
+	group, err := s.findScreening(ctx, key, chain, address, id)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := s.verifyEntries(ctx, key, group)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to verify screening %s", id)
+	}
+
+	state, err := s.currentState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := Proof{
+		State: state,
+	}
+
+	for _, v := range verified {
+		entry, err := s.verifiableEntry(ctx, key, v.GetRevision(), state.GetTxId())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve proof of screening %s", id)
+		}
+		proof.Entries = append(proof.Entries, entry)
+	}
+
+	// state is verified against trusted state of the client already
+	if err := proof.Verify(TrustAnchor{TxID: state.GetTxId(), TxHash: state.GetTxHash()}); err != nil {
+		return nil, errors.Wrapf(err, "screening %s", id)
+	}
+
+	raw, err := json.Marshal(&proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode screening proof")
+	}
+
+	return &walletscreener.ScreeningProof{
+		Screening: decodeScreening(chain, address, verified),
+		Format:    ProofFormat,
+		Proof:     raw,
+	}, nil
+}
+
+// findScreening pages through history of the key and returns entries of the screening identified by id ordered from the oldest one.
+func (s *Store) findScreening(ctx context.Context, key []byte, chain walletscreener.Chain, address string, id string) ([]*schema.Entry, error) {
+	var group []*schema.Entry // entries of the screening being read
+	for offset := uint64(0); ; {
+		entries, err := s.db.History(ctx, &schema.HistoryRequest{
+			Key:    key,
+			Offset: offset,
+			Limit:  historyPageSize,
+		})
+		if isKeyNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", address, chain)
+		}
+
+		for _, v := range entries.GetEntries() {
+			if len(group) > 0 && !sameScreening(group[len(group)-1], v) {
+				if decodeScreening(chain, address, group).ID == id {
+					return group, nil
+				}
+				group = nil
+			}
+			group = append(group, v)
+		}
+
+		offset += uint64(len(entries.GetEntries()))
+		if len(entries.GetEntries()) < historyPageSize {
+			break
+		}
+	}
+
+	if len(group) > 0 && decodeScreening(chain, address, group).ID == id {
+		return group, nil
+	}
+
+	return nil, errors.Wrapf(walletscreener.ErrScreeningNotFound, "screening %s of address %s on chain %s", id, address, chain)
+}
+
+// currentState returns the current database state after verifying it is consistent with the trusted state.
+func (s *Store) currentState(ctx context.Context) (*schema.ImmutableState, error) {
+	state, err := s.db.CurrentState(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve database state")
+	}
+
+	tx, err := s.db.VerifiedTxByID(ctx, state.GetTxId())
+	if err != nil {
+		return nil, verificationError(errors.Wrap(err, "failed to verify database state"))
+	}
+
+	if alh := schema.TxHeaderFromProto(tx.GetHeader()).Alh(); !bytes.Equal(alh[:], state.GetTxHash()) {
+		return nil, errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("database state does not match its transaction"))
+	}
+
+	return state, nil
+}
+
+// verifiableEntry returns entry stored at the given revision of the key along proof of its consistency with the state at stateTx.
+func (s *Store) verifiableEntry(ctx context.Context, key []byte, revision uint64, stateTx uint64) (*schema.VerifiableEntry, error) {
+	service := s.db.GetServiceClient()
+
+	entry, err := service.VerifiableGet(ctx, &schema.VerifiableGetRequest{
+		KeyRequest: &schema.KeyRequest{
+			Key:        key,
+			AtRevision: int64(revision),
+		},
+		ProveSinceTx: stateTx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.GetEntry() == nil {
+		return nil, errors.New("server returned incomplete proof")
+	}
+
+	// server may leave out proof of linear advance expecting it is requested when verifying,
+	// it is filled in so that proof can be verified without a database connection
+	dualProof, err := dualProofFromProto(entry.GetVerifiableTx().GetDualProof())
+	if err != nil {
+		return nil, errors.Wrap(err, "server returned incomplete proof")
+	}
+
+	sourceTx, targetTx := entry.GetEntry().GetTx(), stateTx
+	if targetTx < sourceTx {
+		sourceTx, targetTx = targetTx, sourceTx
+	}
+
+	if err := schema.FillMissingLinearAdvanceProof(ctx, dualProof, sourceTx, targetTx, service); err != nil {
+		return nil, err
+	}
+	entry.VerifiableTx.DualProof = schema.DualProofToProto(dualProof)
+
+	return entry, nil
+}
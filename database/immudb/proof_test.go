@@ -0,0 +1,347 @@
+package immudb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/htree"
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	immudb "github.com/codenotary/immudb/pkg/client"
+	"github.com/codenotary/immudb/pkg/database"
+	"github.com/codenotary/immudb/pkg/server"
+	"github.com/codenotary/immudb/pkg/server/servertest"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestServerStore returns Store connected to in-process immudb server storing data in a temporary directory.
+func newTestServerStore(t *testing.T) *Store {
+	t.Helper()
+
+	srv := servertest.NewBufconnServer(server.DefaultOptions().WithDir(t.TempDir()).WithMetricsServer(false).WithPgsqlServer(false))
+	if err := srv.Start(); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	t.Cleanup(func() { srv.Stop() })
+
+	client, err := srv.NewAuthenticatedClient(immudb.DefaultOptions().WithDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	t.Cleanup(func() { client.CloseSession(context.Background()) })
+
+	return NewStore(client)
+}
+
+func TestGetScreeningProof(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	screenings := []*walletscreener.Screening{
+		{ID: "a", ScreenedAt: time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC), ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress, Risk: 10, OwnCategories: []*walletscreener.RiskCategory{{Name: "Gambling", Risk: 10}}}},
+		{ID: "b", ScreenedAt: time.Date(2023, 10, 5, 15, 18, 23, 0, time.UTC), ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress}},
+		{ID: "c", ScreenedAt: time.Date(2023, 10, 5, 15, 18, 23, 0, time.UTC), ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"}},
+	}
+	for _, v := range screenings {
+		if err := s.StoreScreening(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	if _, err := s.GetScreeningProof(ctx, walletscreener.ChainEthereum, testAddress, "c"); !errors.Is(err, walletscreener.ErrScreeningNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrScreeningNotFound)
+	}
+
+	result, err := s.GetScreeningProof(ctx, walletscreener.ChainEthereum, testAddress, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if result.Format != ProofFormat {
+		t.Errorf("got %v, want %v", result.Format, ProofFormat)
+	}
+
+	if result.Screening.ID != "a" || result.Screening.Revision != 1 || !result.Screening.Verified {
+		t.Errorf("got %+v, want verified screening a at revision 1", result.Screening)
+	}
+
+	// proof is verified offline from its JSON encoding only
+	var proof Proof
+	if err := json.Unmarshal(result.Proof, &proof); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// trusted state is obtained independently from the proof
+	state, err := s.db.CurrentState(ctx)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var testcases = []struct {
+		name   string
+		anchor TrustAnchor
+		err    error
+	}{
+		{name: "none", anchor: TrustAnchor{}, err: ErrNoTrustAnchor},
+		{name: "state", anchor: TrustAnchor{TxID: state.GetTxId(), TxHash: state.GetTxHash()}},
+		{name: "state hash", anchor: TrustAnchor{TxID: state.GetTxId(), TxHash: make([]byte, len(state.GetTxHash()))}, err: walletscreener.ErrScreeningTampered},
+	}
+
+	for _, tt := range testcases {
+		if err := proof.Verify(tt.anchor); !errors.Is(err, tt.err) {
+			t.Errorf("%s got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	// screening transaction is trusted as well
+	tx, err := s.db.VerifiedTxByID(ctx, proof.Entries[0].GetEntry().GetTx())
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	alh := schema.TxHeaderFromProto(tx.GetHeader()).Alh()
+
+	if err := proof.Verify(TrustAnchor{TxID: tx.GetHeader().GetId(), TxHash: alh[:]}); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+
+	// transactions proof is not linked with are not trusted
+	if err := proof.Verify(TrustAnchor{TxID: state.GetTxId() + 1, TxHash: state.GetTxHash()}); err == nil {
+		t.Errorf("got %v, want error", err)
+	}
+
+	screening, err := proof.Screening()
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if !cmp.Equal(screening, result.Screening) {
+		t.Errorf("got %+v, want %+v", screening, result.Screening)
+	}
+
+	// proof is relative to the state following the screening
+	if proof.State.GetTxId() < proof.Entries[0].GetEntry().GetTx() {
+		t.Errorf("state tx got %v, want at least %v", proof.State.GetTxId(), proof.Entries[0].GetEntry().GetTx())
+	}
+}
+
+func TestProofVerifyTampered(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	for _, id := range []string{"a", "b"} {
+		screening := &walletscreener.Screening{ID: id, ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress, Risk: 10}}
+		if err := s.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	result, err := s.GetScreeningProof(ctx, walletscreener.ChainEthereum, testAddress, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	state, err := s.db.CurrentState(ctx)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	anchor := TrustAnchor{TxID: state.GetTxId(), TxHash: append([]byte(nil), state.GetTxHash()...)}
+
+	var testcases = []struct {
+		name   string
+		tamper func(p *Proof)
+	}{
+		{
+			name: "value",
+			tamper: func(p *Proof) {
+				p.Entries[0].Entry.Value = []byte(`{"id":"a","risk":0}`)
+			},
+		},
+		{
+			name: "key",
+			tamper: func(p *Proof) {
+				p.Entries[0].Entry.Key = walletKey(walletscreener.ChainEthereum, "0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+			},
+		},
+		{
+			name: "state",
+			tamper: func(p *Proof) {
+				p.State.TxHash[0] ^= 0xff
+			},
+		},
+		{
+			name: "entries",
+			tamper: func(p *Proof) {
+				p.Entries = nil
+			},
+		},
+		{
+			name: "empty state",
+			tamper: func(p *Proof) {
+				p.State.TxId = 0
+			},
+		},
+		{
+			name: "entry tx",
+			tamper: func(p *Proof) {
+				p.Entries[0].Entry.Tx = p.State.TxId + 1
+			},
+		},
+		{
+			name: "entries hash",
+			tamper: func(p *Proof) {
+				p.Entries[0].VerifiableTx.DualProof.SourceTxHeader.EH[0] ^= 0xff
+				p.Entries[0].VerifiableTx.DualProof.TargetTxHeader.EH[0] ^= 0xff
+			},
+		},
+		{
+			name: "dual proof",
+			tamper: func(p *Proof) {
+				p.Entries[0].VerifiableTx.DualProof = nil
+			},
+		},
+		{
+			name: "linear proof",
+			tamper: func(p *Proof) {
+				p.Entries[0].VerifiableTx.DualProof.LinearProof = nil
+			},
+		},
+		{
+			name: "headers",
+			tamper: func(p *Proof) {
+				p.Entries[0].VerifiableTx.DualProof.SourceTxHeader = nil
+				p.Entries[0].VerifiableTx.DualProof.TargetTxHeader = nil
+			},
+		},
+		{
+			name: "linear advance proof",
+			tamper: func(p *Proof) {
+				p.Entries[0].VerifiableTx.DualProof.LinearAdvanceProof = &schema.LinearAdvanceProof{InclusionProofs: []*schema.InclusionProof{nil}}
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		var proof Proof
+		if err := json.Unmarshal(result.Proof, &proof); err != nil {
+			t.Fatalf("%s got %v, want %v", tt.name, err, nil)
+		}
+
+		tt.tamper(&proof)
+
+		if err := proof.Verify(anchor); !errors.Is(err, walletscreener.ErrScreeningTampered) {
+			t.Errorf("%s got %v, want %v", tt.name, err, walletscreener.ErrScreeningTampered)
+		}
+	}
+}
+
+func TestProofVerifyForged(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	screening := &walletscreener.Screening{ID: "a", ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress, Verdict: walletscreener.VerdictBlock}}
+	if err := s.StoreScreening(ctx, screening); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	result, err := s.GetScreeningProof(ctx, walletscreener.ChainEthereum, testAddress, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var proof Proof
+	if err := json.Unmarshal(result.Proof, &proof); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	entry := proof.Entries[0]
+	genuine := schema.TxHeaderFromProto(entry.GetVerifiableTx().GetDualProof().GetSourceTxHeader())
+	alh := genuine.Alh()
+
+	// invented value is made the only entry of an invented transaction header, genuine header is kept as the source
+	entry.Entry.Value = []byte(`{"id":"forged","verdict":"allow"}`)
+
+	entrySpecDigest, err := store.EntrySpecDigestFor(genuine.Version)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	digest := entrySpecDigest(database.EncodeEntrySpec(entry.Entry.Key, schema.KVMetadataFromProto(entry.Entry.Metadata), entry.Entry.Value))
+	eh := sha256.Sum256(append([]byte{htree.LeafPrefix}, digest[:]...))
+
+	forged := *genuine
+	forged.Eh = eh
+
+	entry.InclusionProof = &schema.InclusionProof{Leaf: 0, Width: 1}
+	entry.VerifiableTx.DualProof.TargetTxHeader = schema.TxHeaderToProto(&forged)
+	entry.VerifiableTx.DualProof.LinearProof = &schema.LinearProof{}
+
+	var testcases = []struct {
+		name  string
+		state *schema.ImmutableState
+	}{
+		{name: "empty state", state: &schema.ImmutableState{}},
+		{name: "entry state", state: &schema.ImmutableState{TxId: genuine.ID, TxHash: alh[:]}},
+		{name: "proof state", state: proof.State},
+	}
+
+	for _, tt := range testcases {
+		proof.State = tt.state
+
+		if err := proof.Verify(TrustAnchor{TxID: genuine.ID, TxHash: alh[:]}); !errors.Is(err, walletscreener.ErrScreeningTampered) {
+			t.Errorf("%s got %v, want %v", tt.name, err, walletscreener.ErrScreeningTampered)
+		}
+	}
+}
+
+func TestFindScreeningAcrossHistoryPages(t *testing.T) {
+	client := &testClient{}
+	s := NewStore(client)
+
+	for i := 1; i < historyPageSize+10; i++ {
+		if err := s.StoreScreening(context.Background(), &walletscreener.Screening{
+			ID:              strconv.Itoa(i),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress},
+		}); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// categories stored by earlier versions spanning history pages
+	tx := uint64(len(client.entries) + 1)
+	for _, v := range []string{"Darknet", "Mixer"} {
+		client.entries = append(client.entries, &schema.Entry{Tx: tx, Revision: uint64(len(client.entries) + 1), Value: []byte(v)})
+	}
+
+	var testcases = []struct {
+		id       string
+		revision uint64
+		entries  int
+		err      error
+	}{
+		{id: "1", revision: 1, entries: 1},
+		{id: strconv.Itoa(historyPageSize + 5), revision: historyPageSize + 5, entries: 1},
+		{id: "tx:" + strconv.FormatUint(tx, 10), revision: tx, entries: 2},
+		{id: "unknown", err: walletscreener.ErrScreeningNotFound},
+	}
+
+	for _, tt := range testcases {
+		group, err := s.findScreening(context.Background(), walletKey(walletscreener.ChainEthereum, testAddress), walletscreener.ChainEthereum, testAddress, tt.id)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s got %v, want %v", tt.id, err, tt.err)
+		}
+
+		if len(group) != tt.entries {
+			t.Fatalf("%s got %d entries, want %d", tt.id, len(group), tt.entries)
+		}
+
+		if tt.entries > 0 && group[0].GetRevision() != tt.revision {
+			t.Errorf("%s revision got %v, want %v", tt.id, group[0].GetRevision(), tt.revision)
+		}
+	}
+}
@@ -1,50 +1,601 @@
 package immudb
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	immudb "github.com/codenotary/immudb/pkg/client"
+	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/errors"
+	"golang.org/x/exp/slices"
 )
 
-// StoreWalletCategories implements StoreWalletRiskCategoriesFunc.
-func StoreWalletRiskCategories(ctx context.Context, db immudb.ImmuClient, address string, categories []string) error {
-	var kvs []*schema.KeyValue
+// Store is an implementation of walletscreener.ScreeningStore backed by immudb.
+// Screenings are written and read along server-generated proofs verified against the trusted state kept by the client,
+// hence returned screenings are marked as verified.
+type Store struct {
+	db immudb.ImmuClient
+}
+
+// NewStore constructs and returns new Store using given immudb client.
+func NewStore(db immudb.ImmuClient) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// walletKey returns a database key under which screenings of given wallet on given chain are stored.
+// Keys are laid out as {chain}:{address}, e.g. eth:0x71C7656EC7ab88b098defB751B7401B5f6d8976F.
+// Every screening is stored as a new revision of the key.
+// Earlier versions stored Ethereum screenings under the plain address, see MigrateLegacyKeys.
+func walletKey(chain walletscreener.Chain, address string) []byte {
+	return []byte(chain.String() + ":" + address)
+}
+
+// riskCategory represents a risk category of stored screening.
+type riskCategory struct {
+	Name      string   `json:"name"`
+	Entity    string   `json:"entity,omitempty"`
+	Risk      int      `json:"risk"`
+	Providers []string `json:"providers,omitempty"`
+}
+
+// screeningRecord represents a single screening value stored in the database.
+type screeningRecord struct {
+	ID                      string                 `json:"id"`
+	ScreenedAt              time.Time              `json:"screened_at"`
+	Chain                   walletscreener.Chain   `json:"chain"`
+	Address                 string                 `json:"address"`
+	Provider                string                 `json:"provider,omitempty"`
+	Entity                  string                 `json:"entity,omitempty"`
+	Risk                    int                    `json:"risk"`
+	OwnCategories           []*riskCategory        `json:"own_categories"`
+	SourceOfFundsCategories []*riskCategory        `json:"source_of_funds_categories"`
+	CaseID                  string                 `json:"case_id,omitempty"`
+	RequestedAt             time.Time              `json:"requested_at"`
+	RespondedAt             time.Time              `json:"responded_at"`
+	Verdict                 walletscreener.Verdict `json:"verdict,omitempty"`
+	MatchedRules            []string               `json:"matched_rules,omitempty"`
+	Change                  *riskChange            `json:"change,omitempty"` // absent for the first screening of the wallet
+}
+
+// riskChange represents change of stored screening from the previous one.
+type riskChange struct {
+	PreviousID        string                 `json:"previous_id"`
+	PreviousVerdict   walletscreener.Verdict `json:"previous_verdict,omitempty"`
+	AddedCategories   []string               `json:"added_categories,omitempty"`
+	RemovedCategories []string               `json:"removed_categories,omitempty"`
+	RiskDelta         int                    `json:"risk_delta"`
+}
+
+// newRiskChange converts walletscreener.RiskChange into riskChange, nil change is kept nil.
+func newRiskChange(change *walletscreener.RiskChange) *riskChange {
+	if change == nil {
+		return nil
+	}
+	return &riskChange{
+		PreviousID:        change.PreviousID,
+		PreviousVerdict:   change.PreviousVerdict,
+		AddedCategories:   change.AddedCategories,
+		RemovedCategories: change.RemovedCategories,
+		RiskDelta:         change.RiskDelta,
+	}
+}
+
+// change converts riskChange into walletscreener.RiskChange, nil change is kept nil.
+func (c *riskChange) change() *walletscreener.RiskChange {
+	if c == nil {
+		return nil
+	}
+	return &walletscreener.RiskChange{
+		PreviousID:        c.PreviousID,
+		PreviousVerdict:   c.PreviousVerdict,
+		AddedCategories:   c.AddedCategories,
+		RemovedCategories: c.RemovedCategories,
+		RiskDelta:         c.RiskDelta,
+	}
+}
+
+// legacyRiskCategory represents a single risk category value stored by earlier versions, one value per category.
+type legacyRiskCategory struct {
+	Category  string                            `json:"category"`
+	Providers []string                          `json:"providers,omitempty"`
+	Entity    string                            `json:"entity,omitempty"`
+	Risk      int                               `json:"risk"`
+	Source    walletscreener.RiskCategorySource `json:"source"`
+	Score     int                               `json:"score"`
+	Provider  string                            `json:"provider,omitempty"`
+	CaseID    string                            `json:"case_id,omitempty"`
+	Verdict   walletscreener.Verdict            `json:"verdict,omitempty"`
+	Screened  time.Time                         `json:"screened"`
+}
+
+// newRiskCategories converts walletscreener.RiskCategory into stored riskCategory.
+func newRiskCategories(categories []*walletscreener.RiskCategory) []*riskCategory {
+	result := []*riskCategory{}
+	for _, v := range categories {
+		result = append(result, &riskCategory{
+			Name:      v.Name,
+			Entity:    v.Entity,
+			Risk:      v.Risk,
+			Providers: v.Providers,
+		})
+	}
+	return result
+}
+
+// riskCategories converts stored riskCategory into walletscreener.RiskCategory.
+func riskCategories(categories []*riskCategory) []*walletscreener.RiskCategory {
+	var result []*walletscreener.RiskCategory
 	for _, v := range categories {
-		kvs = append(kvs, &schema.KeyValue{
-			Key:   []byte(address),
-			Value: []byte(v),
+		result = append(result, &walletscreener.RiskCategory{
+			Name:      v.Name,
+			Entity:    v.Entity,
+			Risk:      v.Risk,
+			Providers: v.Providers,
 		})
 	}
+	return result
+}
 
-	_, err := db.SetAll(ctx, &schema.SetRequest{
-		KVs: kvs,
+// StoreScreening implements walletscreener.ScreeningStore.
+func (s *Store) StoreScreening(ctx context.Context, screening *walletscreener.Screening) error {
+	value, err := json.Marshal(&screeningRecord{
+		ID:                      screening.ID,
+		ScreenedAt:              screening.ScreenedAt,
+		Chain:                   screening.Chain,
+		Address:                 screening.Address,
+		Provider:                screening.Provider,
+		Entity:                  screening.Entity,
+		Risk:                    screening.Risk,
+		OwnCategories:           newRiskCategories(screening.OwnCategories),
+		SourceOfFundsCategories: newRiskCategories(screening.SourceOfFundsCategories),
+		CaseID:                  screening.CaseID,
+		RequestedAt:             screening.RequestedAt,
+		RespondedAt:             screening.RespondedAt,
+		Verdict:                 screening.Verdict,
+		MatchedRules:            screening.MatchedRules,
+		Change:                  newRiskChange(screening.Change),
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to store address scores")
+		return errors.Wrap(err, "failed to encode screening")
 	}
 
+	if _, err := s.db.VerifiedSet(ctx, walletKey(screening.Chain, screening.Address), value); err != nil {
+		return verificationError(errors.Wrap(err, "failed to store screening"))
+	}
+
+	screening.Verified = true
+
 	return nil
 }
 
-// GetWalletRiskCategories implements GetWalletRiskCategoriesFunc.
-func GetWalletRiskCategories(ctx context.Context, db immudb.ImmuClient, address string) ([]string, []uint64, error) {
-	entries, err := db.History(ctx, &schema.HistoryRequest{
-		Key: []byte(address),
+// historyPageSize is a number of history entries read per request.
+// It is kept below the server maximum result size, requests reaching it fail once key has that many entries.
+const historyPageSize = 500
+
+// GetWalletScreenings implements walletscreener.ScreeningStore.
+// Cursor is a revision boundary of the last screening of the previous page, history is read from the next revision on.
+// History is ordered by the time screenings were made, hence reading seeks to the start of the query time range
+// and stops once past its end instead of scanning the whole history.
+func (s *Store) GetWalletScreenings(ctx context.Context, query *walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+	key := walletKey(query.Chain, query.Address)
+
+	offset, ok, err := s.historyOffset(ctx, key, query)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &walletscreener.ScreeningsPage{}, nil
+	}
+
+	start, err := s.seekOffset(ctx, key, query)
+	if err != nil {
+		return nil, err
+	}
+	if start > offset {
+		offset = start
+	}
+
+	var (
+		page    walletscreener.ScreeningsPage
+		matched [][]*schema.Entry // groups of entries of matching screenings ordered from the oldest entry
+		group   []*schema.Entry   // entries of the screening being read in history order
+		full    bool              // whether page is full and there are more matching screenings
+		past    bool              // whether history is read past the end of the query time range
+	)
+
+	// collect collects screening stored in group of entries in case it matches the query
+	collect := func(group []*schema.Entry) {
+		group = append([]*schema.Entry(nil), group...)
+		if query.Descending {
+			slices.Reverse(group)
+		}
+
+		screening := decodeScreening(query.Chain, query.Address, group)
+		if past = pastRange(query, screening); past {
+			return
+		}
+
+		if !query.Matches(screening) {
+			return
+		}
+
+		if query.Limit > 0 && len(matched) == query.Limit {
+			full = true
+			return
+		}
+
+		matched = append(matched, group)
+	}
+
+	for !full && !past {
+		entries, err := s.db.History(ctx, &schema.HistoryRequest{
+			Key:    key,
+			Offset: offset,
+			Limit:  historyPageSize,
+			Desc:   query.Descending,
+		})
+		if isKeyNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", query.Address, query.Chain)
+		}
+
+		for _, v := range entries.GetEntries() {
+			if len(group) > 0 && !sameScreening(group[len(group)-1], v) {
+				if collect(group); full || past {
+					break
+				}
+				group = nil
+			}
+			group = append(group, v)
+		}
+
+		offset += uint64(len(entries.GetEntries()))
+		if len(entries.GetEntries()) < historyPageSize {
+			break
+		}
+	}
+
+	if !full && !past && len(group) > 0 {
+		collect(group)
+	}
+
+	for _, v := range matched {
+		verified, err := s.verifyEntries(ctx, key, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to verify screening history for address %s on chain %s", query.Address, query.Chain)
+		}
+		page.Screenings = append(page.Screenings, decodeScreening(query.Chain, query.Address, verified))
+	}
+
+	if full {
+		last := matched[len(matched)-1]
+		if query.Descending {
+			page.Next = strconv.FormatUint(last[0].GetRevision(), 10)
+		} else {
+			page.Next = strconv.FormatUint(last[len(last)-1].GetRevision(), 10)
+		}
+	}
+
+	return &page, nil
+}
+
+// historyOffset returns offset of history entry query continues from, false is returned if there are no more entries to read.
+func (s *Store) historyOffset(ctx context.Context, key []byte, query *walletscreener.ScreeningsQuery) (uint64, bool, error) {
+	if query.Cursor == "" {
+		return 0, true, nil
+	}
+
+	cursor, err := strconv.ParseUint(query.Cursor, 10, 64)
+	if err != nil || cursor < 1 {
+		return 0, false, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is not a revision", query.Cursor))
+	}
+
+	// cursor of either order is a revision of the key, it is not valid unless the key has reached it
+	latest, err := s.db.Get(ctx, key)
+	if isKeyNotFound(err) {
+		return 0, false, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is past history of the key", query.Cursor))
+	}
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", query.Address, query.Chain)
+	}
+
+	if cursor > latest.GetRevision() {
+		return 0, false, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is past history of the key", query.Cursor))
+	}
+
+	// ascending history is offset from the first revision
+	if !query.Descending {
+		return cursor, cursor < latest.GetRevision(), nil
+	}
+
+	// descending history is offset from the latest revision
+	return latest.GetRevision() - cursor + 1, cursor > 1, nil
+}
+
+// seekOffset returns offset of the first history entry in query order which is not before the query time range.
+// History is binary searched reading a single entry per probe, zero offset is returned if the range is unbounded at its start.
+func (s *Store) seekOffset(ctx context.Context, key []byte, query *walletscreener.ScreeningsQuery) (uint64, error) {
+	var before func(screening *walletscreener.Screening) bool // whether screening is before the range in query order
+	switch {
+	case !query.Descending && !query.Since.IsZero():
+		before = func(screening *walletscreener.Screening) bool { return screening.ScreenedAt.Before(query.Since) }
+	case query.Descending && !query.Until.IsZero():
+		before = func(screening *walletscreener.Screening) bool { return !screening.ScreenedAt.Before(query.Until) }
+	default:
+		return 0, nil
+	}
+
+	latest, err := s.db.Get(ctx, key)
+	if isKeyNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", query.Address, query.Chain)
+	}
+
+	// categories stored by earlier versions share the time of their screening, search lands on the first of them
+	low, high := uint64(0), latest.GetRevision()
+	for low < high {
+		mid := low + (high-low)/2
+
+		entries, err := s.db.History(ctx, &schema.HistoryRequest{
+			Key:    key,
+			Offset: mid,
+			Limit:  1,
+			Desc:   query.Descending,
+		})
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", query.Address, query.Chain)
+		}
+
+		if len(entries.GetEntries()) > 0 && before(decodeScreening(query.Chain, query.Address, entries.GetEntries())) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// pastRange returns whether screening read in query order is past the end of the query time range.
+func pastRange(query *walletscreener.ScreeningsQuery, screening *walletscreener.Screening) bool {
+	if query.Descending {
+		return !query.Since.IsZero() && screening.ScreenedAt.Before(query.Since)
+	}
+	return !query.Until.IsZero() && !screening.ScreenedAt.Before(query.Until)
+}
+
+// latestHistoryLimit is a maximum number of the most recent history entries scanned for the latest screening.
+// Screenings stored by earlier versions span multiple entries, one per category.
+const latestHistoryLimit = historyPageSize
+
+// GetLatestScreening implements walletscreener.ScreeningStore.
+func (s *Store) GetLatestScreening(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+	entries, err := s.db.History(ctx, &schema.HistoryRequest{
+		Key:   walletKey(chain, address),
+		Desc:  true,
+		Limit: latestHistoryLimit,
 	})
+	if isKeyNotFound(err) {
+		return nil, errors.Wrapf(walletscreener.ErrScreeningNotFound, "address %s on chain %s", address, chain)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve latest screening for address %s on chain %s", address, chain)
+	}
+
+	latest := latestEntries(entries.GetEntries())
+	if len(latest) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrScreeningNotFound, "address %s on chain %s", address, chain)
+	}
+
+	verified, err := s.verifyEntries(ctx, walletKey(chain, address), latest)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to retrieve category history for address %s", address)
+		return nil, errors.Wrapf(err, "failed to verify latest screening for address %s on chain %s", address, chain)
 	}
 
+	screenings := decodeScreenings(chain, address, verified)
+	return screenings[len(screenings)-1], nil
+}
+
+// latestEntries returns entries of the most recent screening from entries ordered from the most recent one.
+// Returned entries are ordered from the oldest one.
+func latestEntries(entries []*schema.Entry) []*schema.Entry {
+	// legacy screenings span all entries stored by the same transaction
+	var latest []*schema.Entry
+	for _, v := range entries {
+		if v.GetTx() != entries[0].GetTx() {
+			break
+		}
+		latest = append([]*schema.Entry{v}, latest...)
+	}
+	return latest
+}
+
+// verifyEntries reads each of history entries of the key again along its proof and verifies it against trusted state.
+// Verified entries are returned, ErrScreeningTampered is returned if any of entries fails verification.
+func (s *Store) verifyEntries(ctx context.Context, key []byte, entries []*schema.Entry) ([]*schema.Entry, error) {
+	var verified []*schema.Entry
+	for _, v := range entries {
+		entry, err := s.db.VerifiedGetAtRevision(ctx, key, int64(v.GetRevision()))
+		if err != nil {
+			return nil, verificationError(errors.Wrapf(err, "revision %d", v.GetRevision()))
+		}
+
+		// entry is read at the requested revision
+		entry.Revision = v.GetRevision()
+
+		verified = append(verified, entry)
+	}
+	return verified, nil
+}
+
+// verificationError reports err as walletscreener.ErrScreeningTampered if it is caused by data failing verification.
+func verificationError(err error) error {
+	if errors.Is(err, store.ErrCorruptedData) {
+		return errors.WithReason(walletscreener.ErrScreeningTampered, err)
+	}
+	return err
+}
+
+// decodeScreenings decodes screenings stored in verified entries ordered from the oldest one.
+func decodeScreenings(chain walletscreener.Chain, address string, entries []*schema.Entry) []*walletscreener.Screening {
+	var screenings []*walletscreener.Screening
+	for _, v := range groupEntries(entries) {
+		screenings = append(screenings, decodeScreening(chain, address, v))
+	}
+	return screenings
+}
+
+// decodeRecord decodes screening record stored in entry, false is returned if entry was stored by earlier versions.
+func decodeRecord(entry *schema.Entry) (*screeningRecord, bool) {
+	var record screeningRecord
+	if err := json.Unmarshal(entry.GetValue(), &record); err != nil || record.ID == "" {
+		return nil, false
+	}
+	return &record, true
+}
+
+// groupEntries groups entries ordered from the oldest one by screening they store.
+// Screening record is stored as a single entry, categories stored by earlier versions as separate entries are grouped by their transaction.
+func groupEntries(entries []*schema.Entry) [][]*schema.Entry {
+	var groups [][]*schema.Entry
+	for i, v := range entries {
+		if i > 0 && sameScreening(entries[i-1], v) {
+			groups[len(groups)-1] = append(groups[len(groups)-1], v)
+			continue
+		}
+
+		groups = append(groups, []*schema.Entry{v})
+	}
+	return groups
+}
+
+// sameScreening returns whether adjacent history entries store the same screening.
+// Only categories stored by earlier versions by the same transaction belong to the same screening.
+func sameScreening(a, b *schema.Entry) bool {
+	_, aRecord := decodeRecord(a)
+	_, bRecord := decodeRecord(b)
+	return !aRecord && !bRecord && a.GetTx() == b.GetTx()
+}
+
+// decodeScreening decodes screening stored in verified entries grouped by groupEntries.
+func decodeScreening(chain walletscreener.Chain, address string, entries []*schema.Entry) *walletscreener.Screening {
+	if record, ok := decodeRecord(entries[0]); ok {
+		return &walletscreener.Screening{
+			ID:         record.ID,
+			Revision:   entries[0].GetRevision(),
+			ScreenedAt: record.ScreenedAt,
+			Verified:   true,
+			ScreeningResult: walletscreener.ScreeningResult{
+				Chain:                   chain,
+				Address:                 address,
+				Provider:                record.Provider,
+				Entity:                  record.Entity,
+				Risk:                    record.Risk,
+				OwnCategories:           riskCategories(record.OwnCategories),
+				SourceOfFundsCategories: riskCategories(record.SourceOfFundsCategories),
+				CaseID:                  record.CaseID,
+				RequestedAt:             record.RequestedAt,
+				RespondedAt:             record.RespondedAt,
+				Verdict:                 record.Verdict,
+				MatchedRules:            record.MatchedRules,
+			},
+			Change: record.Change.change(),
+		}
+	}
+
+	var screening *walletscreener.Screening
+	for _, v := range entries {
+		var category legacyRiskCategory
+		if err := json.Unmarshal(v.GetValue(), &category); err != nil {
+			category = legacyRiskCategory{Category: string(v.GetValue())} // plain category name stored by the earliest versions
+		}
+
+		if screening == nil {
+			screening = &walletscreener.Screening{
+				ID:         fmt.Sprintf("tx:%d", v.GetTx()),
+				ScreenedAt: category.Screened,
+				Verified:   true,
+				ScreeningResult: walletscreener.ScreeningResult{
+					Chain:       chain,
+					Address:     address,
+					Provider:    category.Provider,
+					Risk:        category.Score,
+					CaseID:      category.CaseID,
+					RespondedAt: category.Screened,
+					Verdict:     category.Verdict,
+				},
+			}
+		}
+
+		screening.Revision = v.GetRevision()
+
+		riskCategory := &walletscreener.RiskCategory{
+			Name:      category.Category,
+			Entity:    category.Entity,
+			Risk:      category.Risk,
+			Providers: category.Providers,
+		}
+
+		switch category.Source {
+		case walletscreener.RiskCategorySourceSourceOfFunds:
+			screening.SourceOfFundsCategories = append(screening.SourceOfFundsCategories, riskCategory)
+		default:
+			screening.OwnCategories = append(screening.OwnCategories, riskCategory)
+			if screening.Entity == "" {
+				screening.Entity = category.Entity
+			}
+		}
+	}
+
+	return screening
+}
+
+// listWalletsPageSize is a number of keys scanned per request when listing wallets.
+const listWalletsPageSize = 1000
+
+// ListWallets implements walletscreener.ScreeningStore.
+func (s *Store) ListWallets(ctx context.Context, chain walletscreener.Chain) ([]string, error) {
+	prefix := walletKey(chain, "")
+
 	var (
-		categories []string
-		revisions  []uint64
+		addresses []string
+		seekKey   []byte
 	)
-	for _, v := range entries.GetEntries() {
-		categories = append(categories, string(v.GetValue()))
-		revisions = append(revisions, v.GetRevision())
+	for {
+		entries, err := s.db.Scan(ctx, &schema.ScanRequest{
+			Prefix:  prefix,
+			SeekKey: seekKey,
+			Limit:   listWalletsPageSize,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list wallets on chain %s", chain)
+		}
+
+		for _, v := range entries.GetEntries() {
+			addresses = append(addresses, string(bytes.TrimPrefix(v.GetKey(), prefix)))
+			seekKey = v.GetKey()
+		}
+
+		if len(entries.GetEntries()) < listWalletsPageSize {
+			return addresses, nil
+		}
 	}
+}
 
-	return categories, revisions, nil
+// isKeyNotFound returns whether err reports that requested key does not exist.
+func isKeyNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "key not found")
 }
@@ -0,0 +1,577 @@
+package immudb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	immudb "github.com/codenotary/immudb/pkg/client"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/exp/slices"
+)
+
+const testAddress = "0xe9e9afac38e64728f1afbb2b65dec7be7c704c05"
+
+// testClient is immudb client keeping history of a single key in memory.
+// Methods not used by Store are not implemented.
+type testClient struct {
+	immudb.ImmuClient
+
+	entries  []*schema.Entry // history, oldest first
+	tampered map[uint64]bool // revisions failing verification
+	read     int             // history entries read
+}
+
+// VerifiedSet implements immudb.ImmuClient.
+func (c *testClient) VerifiedSet(ctx context.Context, key []byte, value []byte) (*schema.TxHeader, error) {
+	tx := uint64(len(c.entries) + 1)
+	c.entries = append(c.entries, &schema.Entry{Tx: tx, Key: key, Value: value, Revision: tx})
+	return &schema.TxHeader{Id: tx}, nil
+}
+
+// History implements immudb.ImmuClient.
+func (c *testClient) History(ctx context.Context, req *schema.HistoryRequest) (*schema.Entries, error) {
+	if len(c.entries) < 1 {
+		return nil, errors.New("key not found")
+	}
+
+	var entries []*schema.Entry
+	for i := range c.entries {
+		if req.Desc {
+			i = len(c.entries) - 1 - i
+		}
+		entries = append(entries, &schema.Entry{Tx: c.entries[i].Tx, Key: c.entries[i].Key, Value: c.entries[i].Value, Revision: c.entries[i].Revision})
+	}
+
+	if req.Offset >= uint64(len(entries)) {
+		return &schema.Entries{}, nil
+	}
+	entries = entries[req.Offset:]
+
+	if req.Limit > 0 && len(entries) > int(req.Limit) {
+		entries = entries[:req.Limit]
+	}
+
+	c.read += len(entries)
+
+	return &schema.Entries{Entries: entries}, nil
+}
+
+// Get implements immudb.ImmuClient.
+func (c *testClient) Get(ctx context.Context, key []byte, opts ...immudb.GetOption) (*schema.Entry, error) {
+	if len(c.entries) < 1 {
+		return nil, errors.New("key not found")
+	}
+
+	latest := c.entries[len(c.entries)-1]
+	return &schema.Entry{Tx: latest.Tx, Key: latest.Key, Value: latest.Value, Revision: latest.Revision}, nil
+}
+
+// VerifiedGetAtRevision implements immudb.ImmuClient.
+func (c *testClient) VerifiedGetAtRevision(ctx context.Context, key []byte, rev int64) (*schema.Entry, error) {
+	if c.tampered[uint64(rev)] {
+		return nil, store.ErrCorruptedData
+	}
+
+	for _, v := range c.entries {
+		if v.Revision == uint64(rev) {
+			return &schema.Entry{Tx: v.Tx, Key: v.Key, Value: v.Value}, nil
+		}
+	}
+
+	return nil, errors.New("key not found")
+}
+
+func TestGetLatestScreening(t *testing.T) {
+	screened := time.Date(2023, 10, 4, 15, 18, 22, 0, time.UTC)
+
+	entries := []*schema.Entry{
+		{Tx: 1, Revision: 1, Value: []byte(`Darknet`)},
+		{Tx: 3, Revision: 2, Value: []byte(`{"category":"Gambling","risk":10,"source":"own","score":10,"provider":"blockmate","case_id":"case1","verdict":"allow","screened":"2023-10-03T15:18:22Z"}`)},
+		{Tx: 7, Revision: 3, Value: []byte(`{"category":"Mixer","risk":50,"source":"source_of_funds","score":100,"provider":"blockmate","case_id":"case2","verdict":"block","screened":"2023-10-04T15:18:22Z"}`)},
+		{Tx: 7, Revision: 4, Value: []byte(`{"category":"Sanctions","entity":"Lazarus","risk":100,"source":"own","score":100,"provider":"blockmate","case_id":"case2","verdict":"block","screened":"2023-10-04T15:18:22Z"}`)},
+		{Tx: 9, Revision: 5, Value: []byte(`{"id":"8c0f1e2a","screened_at":"2023-10-05T15:18:23Z","chain":"eth","address":"` + testAddress + `","provider":"blockmate","risk":0,"own_categories":[],"source_of_funds_categories":[],"case_id":"case3","requested_at":"2023-10-05T15:18:21Z","responded_at":"2023-10-05T15:18:22Z","verdict":"allow"}`)},
+	}
+
+	var testcases = []struct {
+		entries   []*schema.Entry
+		tampered  map[uint64]bool
+		screening *walletscreener.Screening
+		err       error
+	}{
+		// no entries
+		{
+			entries: nil,
+			err:     walletscreener.ErrScreeningNotFound,
+		},
+		// single screening record, including clean result
+		{
+			entries: entries,
+			screening: &walletscreener.Screening{
+				ID:         "8c0f1e2a",
+				Revision:   5,
+				ScreenedAt: time.Date(2023, 10, 5, 15, 18, 23, 0, time.UTC),
+				Verified:   true,
+				ScreeningResult: walletscreener.ScreeningResult{
+					Chain:       walletscreener.ChainEthereum,
+					Address:     testAddress,
+					Provider:    "blockmate",
+					CaseID:      "case3",
+					RequestedAt: time.Date(2023, 10, 5, 15, 18, 21, 0, time.UTC),
+					RespondedAt: time.Date(2023, 10, 5, 15, 18, 22, 0, time.UTC),
+					Verdict:     walletscreener.VerdictAllow,
+				},
+			},
+		},
+		// categories of the most recent transaction stored by earlier versions
+		{
+			entries: entries[:4],
+			screening: &walletscreener.Screening{
+				ID:         "tx:7",
+				Revision:   4,
+				ScreenedAt: screened,
+				Verified:   true,
+				ScreeningResult: walletscreener.ScreeningResult{
+					Chain:    walletscreener.ChainEthereum,
+					Address:  testAddress,
+					Provider: "blockmate",
+					Entity:   "Lazarus",
+					Risk:     100,
+					OwnCategories: []*walletscreener.RiskCategory{
+						{Name: "Sanctions", Entity: "Lazarus", Risk: 100},
+					},
+					SourceOfFundsCategories: []*walletscreener.RiskCategory{
+						{Name: "Mixer", Risk: 50},
+					},
+					CaseID:      "case2",
+					RespondedAt: screened,
+					Verdict:     walletscreener.VerdictBlock,
+				},
+			},
+		},
+		// plain category names stored by the earliest versions
+		{
+			entries: entries[:1],
+			screening: &walletscreener.Screening{
+				ID:       "tx:1",
+				Revision: 1,
+				Verified: true,
+				ScreeningResult: walletscreener.ScreeningResult{
+					Chain:   walletscreener.ChainEthereum,
+					Address: testAddress,
+					OwnCategories: []*walletscreener.RiskCategory{
+						{Name: "Darknet"},
+					},
+				},
+			},
+		},
+		// latest screening fails verification
+		{
+			entries:  entries,
+			tampered: map[uint64]bool{5: true},
+			err:      walletscreener.ErrScreeningTampered,
+		},
+	}
+
+	for i, tt := range testcases {
+		s := NewStore(&testClient{entries: tt.entries, tampered: tt.tampered})
+
+		screening, err := s.GetLatestScreening(context.Background(), walletscreener.ChainEthereum, testAddress)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("#%d got %v, want %v", i, err, tt.err)
+		}
+
+		if !cmp.Equal(screening, tt.screening) {
+			t.Errorf("#%d got %+v, want %+v", i, screening, tt.screening)
+		}
+	}
+}
+
+func TestGetWalletScreenings(t *testing.T) {
+	client := &testClient{
+		entries: []*schema.Entry{
+			{Tx: 1, Revision: 1, Value: []byte(`Darknet`)},
+			{Tx: 1, Revision: 2, Value: []byte(`Mixer`)},
+			{Tx: 3, Revision: 3, Value: []byte(`{"category":"Gambling","risk":10,"source":"own","score":10,"provider":"blockmate","case_id":"case1","verdict":"allow","screened":"2023-10-03T15:18:22Z"}`)},
+		},
+	}
+	s := NewStore(client)
+
+	for _, v := range []*walletscreener.Screening{
+		{ID: "a", ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress}},
+		{ID: "b", ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress, Risk: 10, OwnCategories: []*walletscreener.RiskCategory{{Name: "Gambling", Risk: 10}}}},
+	} {
+		if err := s.StoreScreening(context.Background(), v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if !v.Verified {
+			t.Errorf("stored screening %s got unverified, want verified", v.ID)
+		}
+	}
+
+	type screening struct {
+		ID         string
+		Revision   uint64
+		Verified   bool
+		Categories []string
+	}
+
+	page, err := s.GetWalletScreenings(context.Background(), &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: testAddress})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var got []screening
+	for _, v := range page.Screenings {
+		s := screening{ID: v.ID, Revision: v.Revision, Verified: v.Verified}
+		for _, c := range v.OwnCategories {
+			s.Categories = append(s.Categories, c.Name)
+		}
+		got = append(got, s)
+	}
+
+	expected := []screening{
+		{ID: "tx:1", Revision: 2, Verified: true, Categories: []string{"Darknet", "Mixer"}},
+		{ID: "tx:3", Revision: 3, Verified: true, Categories: []string{"Gambling"}},
+		{ID: "a", Revision: 4, Verified: true},
+		{ID: "b", Revision: 5, Verified: true, Categories: []string{"Gambling"}},
+	}
+	if !cmp.Equal(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+
+	// any tampered revision fails the whole history
+	client.tampered = map[uint64]bool{3: true}
+
+	if _, err := s.GetWalletScreenings(context.Background(), &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: testAddress}); !errors.Is(err, walletscreener.ErrScreeningTampered) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrScreeningTampered)
+	}
+
+	// screenings not returned are not verified
+	page, err = s.GetWalletScreenings(context.Background(), &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: testAddress, Cursor: "3"})
+	if err != nil || len(page.Screenings) != 2 {
+		t.Errorf("got %+v %v, want 2 screenings", page, err)
+	}
+}
+
+func TestGetWalletScreeningsQuery(t *testing.T) {
+	at := time.Date(2023, 10, 4, 15, 0, 0, 0, time.UTC)
+
+	client := &testClient{
+		entries: []*schema.Entry{
+			{Tx: 1, Revision: 1, Value: []byte(`{"category":"Darknet","risk":10,"source":"own","screened":"2023-10-04T15:01:00Z"}`)},
+			{Tx: 1, Revision: 2, Value: []byte(`{"category":"Mixer","risk":10,"source":"own","screened":"2023-10-04T15:01:00Z"}`)},
+		},
+	}
+	s := NewStore(client)
+
+	for i := 2; i <= 5; i++ {
+		if err := s.StoreScreening(context.Background(), &walletscreener.Screening{
+			ID:              strconv.Itoa(i),
+			ScreenedAt:      at.Add(time.Duration(i) * time.Minute),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress},
+		}); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var testcases = []struct {
+		query      walletscreener.ScreeningsQuery
+		screenings []string
+		next       string
+		err        error
+	}{
+		{
+			query:      walletscreener.ScreeningsQuery{Limit: 2},
+			screenings: []string{"tx:1", "2"},
+			next:       "3",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "3", Limit: 2},
+			screenings: []string{"3", "4"},
+			next:       "5",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "5", Limit: 2},
+			screenings: []string{"5"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Descending: true, Limit: 3},
+			screenings: []string{"5", "4", "3"},
+			next:       "4",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "4", Descending: true},
+			screenings: []string{"2", "tx:1"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "1", Descending: true},
+			screenings: nil,
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Since: at.Add(2 * time.Minute), Until: at.Add(4 * time.Minute)},
+			screenings: []string{"2", "3"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Until: at.Add(2 * time.Minute), Descending: true, Limit: 1},
+			screenings: []string{"tx:1"},
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "x"},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "7", Descending: true},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+	}
+
+	for i, tt := range testcases {
+		tt.query.Chain, tt.query.Address = walletscreener.ChainEthereum, testAddress
+
+		page, err := s.GetWalletScreenings(context.Background(), &tt.query)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		var screenings []string
+		for _, v := range page.Screenings {
+			screenings = append(screenings, v.ID)
+		}
+
+		if !cmp.Equal(screenings, tt.screenings) || page.Next != tt.next {
+			t.Errorf("#%d got %v %q, want %v %q", i, screenings, page.Next, tt.screenings, tt.next)
+		}
+	}
+}
+
+func TestGetWalletScreeningsAcrossHistoryPages(t *testing.T) {
+	client := &testClient{}
+	s := NewStore(client)
+
+	for i := 1; i < historyPageSize; i++ {
+		if err := s.StoreScreening(context.Background(), &walletscreener.Screening{
+			ID:              strconv.Itoa(i),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress},
+		}); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// categories stored by earlier versions spanning history pages
+	for _, v := range []string{"Darknet", "Mixer", "Gambling"} {
+		client.entries = append(client.entries, &schema.Entry{Tx: historyPageSize, Revision: uint64(len(client.entries) + 1), Value: []byte(v)})
+	}
+
+	for _, descending := range []bool{false, true} {
+		page, err := s.GetWalletScreenings(context.Background(), &walletscreener.ScreeningsQuery{
+			Chain:      walletscreener.ChainEthereum,
+			Address:    testAddress,
+			Descending: descending,
+		})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if len(page.Screenings) != historyPageSize {
+			t.Fatalf("descending %v got %d screenings, want %d", descending, len(page.Screenings), historyPageSize)
+		}
+
+		legacy := page.Screenings[len(page.Screenings)-1]
+		if descending {
+			legacy = page.Screenings[0]
+		}
+
+		if expected := []string{"Darknet", "Gambling", "Mixer"}; !cmp.Equal(legacy.Categories(), expected) {
+			t.Errorf("descending %v got %v, want %v", descending, legacy.Categories(), expected)
+		}
+	}
+}
+
+func TestGetWalletScreeningsTimeRange(t *testing.T) {
+	client := &testClient{}
+	s := NewStore(client)
+
+	start := time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2*historyPageSize; i++ {
+		if err := s.StoreScreening(context.Background(), &walletscreener.Screening{
+			ID:              strconv.Itoa(i),
+			ScreenedAt:      start.Add(time.Duration(i) * time.Hour),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress},
+		}); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var testcases = []struct {
+		since, until int // hours since start, unbounded if negative
+		descending   bool
+		expected     []string
+	}{
+		{since: 600, until: 603, expected: []string{"600", "601", "602"}},
+		{since: 600, until: 603, descending: true, expected: []string{"602", "601", "600"}},
+		{since: -1, until: 2, expected: []string{"0", "1"}},
+		{since: -1, until: 2, descending: true, expected: []string{"1", "0"}},
+		{since: 998, until: -1, expected: []string{"998", "999"}},
+		{since: 998, until: -1, descending: true, expected: []string{"999", "998"}},
+		{since: 2000, until: -1, expected: nil},
+	}
+
+	for i, tt := range testcases {
+		query := walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: testAddress, Descending: tt.descending}
+		if tt.since >= 0 {
+			query.Since = start.Add(time.Duration(tt.since) * time.Hour)
+		}
+		if tt.until >= 0 {
+			query.Until = start.Add(time.Duration(tt.until) * time.Hour)
+		}
+
+		client.read = 0
+
+		page, err := s.GetWalletScreenings(context.Background(), &query)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		var ids []string
+		for _, v := range page.Screenings {
+			ids = append(ids, v.ID)
+		}
+
+		if !cmp.Equal(ids, tt.expected) {
+			t.Errorf("#%d got %v, want %v", i, ids, tt.expected)
+		}
+
+		// history is sought instead of scanned from its start
+		if client.read > historyPageSize+32 {
+			t.Errorf("#%d read entries got %v, want at most %v", i, client.read, historyPageSize+32)
+		}
+	}
+}
+
+func TestGetWalletScreeningsPages(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	var expected []string
+	for i := 1; i <= 5; i++ {
+		screening := &walletscreener.Screening{
+			ID:              strconv.Itoa(i),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: testAddress},
+		}
+		if err := s.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		expected = append(expected, screening.ID)
+	}
+
+	for _, descending := range []bool{false, true} {
+		query := walletscreener.ScreeningsQuery{
+			Chain:      walletscreener.ChainEthereum,
+			Address:    testAddress,
+			Descending: descending,
+			Limit:      2,
+		}
+
+		var screenings []string
+		for {
+			page, err := s.GetWalletScreenings(ctx, &query)
+			if err != nil {
+				t.Fatalf("got %v, want %v", err, nil)
+			}
+
+			for _, v := range page.Screenings {
+				if !v.Verified {
+					t.Errorf("screening %s got unverified, want verified", v.ID)
+				}
+				screenings = append(screenings, v.ID)
+			}
+
+			if page.Next == "" {
+				break
+			}
+			query.Cursor = page.Next
+		}
+
+		want := append([]string(nil), expected...)
+		if descending {
+			slices.Reverse(want)
+		}
+
+		if !cmp.Equal(screenings, want) {
+			t.Errorf("descending %v got %v, want %v", descending, screenings, want)
+		}
+	}
+}
+
+func TestStoreRiskChange(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServerStore(t)
+
+	first := &walletscreener.Screening{
+		ID: "1",
+		ScreeningResult: walletscreener.ScreeningResult{
+			Chain:         walletscreener.ChainEthereum,
+			Address:       testAddress,
+			Risk:          10,
+			OwnCategories: []*walletscreener.RiskCategory{{Name: "Gambling"}},
+			Verdict:       walletscreener.VerdictAllow,
+		},
+	}
+	second := &walletscreener.Screening{
+		ID: "2",
+		ScreeningResult: walletscreener.ScreeningResult{
+			Chain:         walletscreener.ChainEthereum,
+			Address:       testAddress,
+			Risk:          100,
+			OwnCategories: []*walletscreener.RiskCategory{{Name: "Sanctions"}},
+			Verdict:       walletscreener.VerdictBlock,
+		},
+	}
+	second.Change = walletscreener.NewRiskChange(first, second)
+
+	for _, v := range []*walletscreener.Screening{first, second} {
+		if err := s.StoreScreening(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	page, err := s.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: testAddress})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var changes []*walletscreener.RiskChange
+	for _, v := range page.Screenings {
+		changes = append(changes, v.Change)
+	}
+
+	expected := []*walletscreener.RiskChange{nil, {
+		PreviousID:        "1",
+		PreviousVerdict:   walletscreener.VerdictAllow,
+		AddedCategories:   []string{"Sanctions"},
+		RemovedCategories: []string{"Gambling"},
+		RiskDelta:         90,
+	}}
+
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScreeningsCursor(t *testing.T) {
+	storetest.TestScreeningsCursor(t, func(t *testing.T) walletscreener.ScreeningStore {
+		return newTestServerStore(t)
+	})
+}
@@ -0,0 +1,155 @@
+package immudb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// watchPrefix prefixes keys of watched wallets keeping them apart from keys of screenings.
+const watchPrefix = "watch:"
+
+// watchKey returns a database key under which watch of given wallet on given chain is stored.
+// Keys are laid out as watch:{chain}:{address}, e.g. watch:eth:0x71C7656EC7ab88b098defB751B7401B5f6d8976F.
+func watchKey(chain walletscreener.Chain, address string) []byte {
+	return append([]byte(watchPrefix), walletKey(chain, address)...)
+}
+
+// watchRecord represents a single watch value stored in the database.
+// Keys cannot be removed from immudb, deleted watches are stored as removed instead.
+type watchRecord struct {
+	Chain          walletscreener.Chain `json:"chain"`
+	Address        string               `json:"address"`
+	Interval       time.Duration        `json:"interval"`
+	CreatedAt      time.Time            `json:"created_at"`
+	NextAt         time.Time            `json:"next_at"`
+	LastScreenedAt time.Time            `json:"last_screened_at"`
+	LastError      string               `json:"last_error,omitempty"`
+	Removed        bool                 `json:"removed,omitempty"`
+}
+
+// watch converts record into walletscreener.Watch.
+func (r *watchRecord) watch() *walletscreener.Watch {
+	return &walletscreener.Watch{
+		Chain:          r.Chain,
+		Address:        r.Address,
+		Interval:       r.Interval,
+		CreatedAt:      r.CreatedAt,
+		NextAt:         r.NextAt,
+		LastScreenedAt: r.LastScreenedAt,
+		LastError:      r.LastError,
+	}
+}
+
+// setWatch stores record under its watch key.
+// Watches are configuration rather than audit records, they are written and read without proofs.
+func (s *Store) setWatch(ctx context.Context, record *watchRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode watch")
+	}
+
+	if _, err := s.db.Set(ctx, watchKey(record.Chain, record.Address), value); err != nil {
+		return errors.Wrap(err, "failed to store watch")
+	}
+
+	return nil
+}
+
+// getWatch returns record of watch of the wallet, removed watches are not found.
+func (s *Store) getWatch(ctx context.Context, chain walletscreener.Chain, address string) (*watchRecord, error) {
+	entry, err := s.db.Get(ctx, watchKey(chain, address))
+	if isKeyNotFound(err) {
+		return nil, errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get watch")
+	}
+
+	var record watchRecord
+	if err := json.Unmarshal(entry.GetValue(), &record); err != nil {
+		return nil, errors.Wrap(err, "failed to decode watch")
+	}
+
+	if record.Removed {
+		return nil, errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return &record, nil
+}
+
+// PutWatch implements walletscreener.WatchlistStore.
+func (s *Store) PutWatch(ctx context.Context, watch *walletscreener.Watch) error {
+	return s.setWatch(ctx, &watchRecord{
+		Chain:          watch.Chain,
+		Address:        watch.Address,
+		Interval:       watch.Interval,
+		CreatedAt:      watch.CreatedAt,
+		NextAt:         watch.NextAt,
+		LastScreenedAt: watch.LastScreenedAt,
+		LastError:      watch.LastError,
+	})
+}
+
+// GetWatch implements walletscreener.WatchlistStore.
+func (s *Store) GetWatch(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+	record, err := s.getWatch(ctx, chain, address)
+	if err != nil {
+		return nil, err
+	}
+	return record.watch(), nil
+}
+
+// DeleteWatch implements walletscreener.WatchlistStore.
+func (s *Store) DeleteWatch(ctx context.Context, chain walletscreener.Chain, address string) error {
+	record, err := s.getWatch(ctx, chain, address)
+	if err != nil {
+		return err
+	}
+
+	record.Removed = true
+
+	return s.setWatch(ctx, record)
+}
+
+// ListWatches implements walletscreener.WatchlistStore.
+func (s *Store) ListWatches(ctx context.Context) ([]*walletscreener.Watch, error) {
+	prefix := []byte(watchPrefix)
+
+	var (
+		watches = []*walletscreener.Watch{}
+		seekKey []byte
+	)
+	for {
+		entries, err := s.db.Scan(ctx, &schema.ScanRequest{
+			Prefix:  prefix,
+			SeekKey: seekKey,
+			Limit:   listWalletsPageSize,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list watches")
+		}
+
+		for _, v := range entries.GetEntries() {
+			seekKey = v.GetKey()
+
+			var record watchRecord
+			if err := json.Unmarshal(v.GetValue(), &record); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode watch %s", bytes.TrimPrefix(v.GetKey(), prefix))
+			}
+
+			if !record.Removed {
+				watches = append(watches, record.watch())
+			}
+		}
+
+		if len(entries.GetEntries()) < listWalletsPageSize {
+			return watches, nil
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package immudb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// Prefixes of webhook keys keeping them apart from keys of screenings.
+const (
+	subscriptionPrefix = "webhook:"
+	deliveryPrefix     = "delivery:"
+	pendingPrefix      = "pending:"
+)
+
+// indexPageSize is a number of pending deliveries indexed per transaction.
+const indexPageSize = 500
+
+// errScanDone stops scan before all keys having prefix are scanned.
+var errScanDone = errors.New("scan done")
+
+// subscriptionKey returns a database key under which subscription identified by id is stored.
+// Keys are laid out as webhook:{id}.
+func subscriptionKey(id string) []byte {
+	return []byte(subscriptionPrefix + id)
+}
+
+// deliveryKey returns a database key under which delivery identified by id of the given subscription is stored.
+// Keys are laid out as delivery:{subscription}:{id} so that deliveries of a subscription are scanned by prefix.
+func deliveryKey(subscriptionID, id string) []byte {
+	return []byte(deliveryPrefix + subscriptionID + ":" + id)
+}
+
+// pendingKey returns a database key indexing delivery pending until its next attempt.
+// Keys are laid out as pending:{next attempt}:{subscription}:{id} with zero padded nanoseconds of the next attempt
+// so that pending deliveries are scanned by prefix in order of their next attempts.
+func pendingKey(delivery *walletscreener.Delivery) []byte {
+	nanos := delivery.NextAttemptAt.UnixNano()
+	if nanos < 0 {
+		nanos = 0
+	}
+	return []byte(fmt.Sprintf("%s%020d:%s:%s", pendingPrefix, nanos, delivery.SubscriptionID, delivery.ID))
+}
+
+// subscriptionRecord represents a single subscription value stored in the database.
+// Keys cannot be removed from immudb, deleted subscriptions are stored as removed instead.
+type subscriptionRecord struct {
+	ID        string                        `json:"id"`
+	URL       string                        `json:"url"`
+	Secret    string                        `json:"secret"`
+	Events    []walletscreener.WebhookEvent `json:"events,omitempty"`
+	CreatedAt time.Time                     `json:"created_at"`
+	Removed   bool                          `json:"removed,omitempty"`
+}
+
+// subscription converts record into walletscreener.Subscription.
+func (r *subscriptionRecord) subscription() *walletscreener.Subscription {
+	return &walletscreener.Subscription{
+		ID:        r.ID,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Events:    r.Events,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// deliveryRecord represents a single delivery value stored in the database.
+type deliveryRecord struct {
+	ID             string                        `json:"id"`
+	SubscriptionID string                        `json:"subscription_id"`
+	Event          walletscreener.WebhookEvent   `json:"event"`
+	ScreeningID    string                        `json:"screening_id"`
+	Payload        []byte                        `json:"payload"`
+	Status         walletscreener.DeliveryStatus `json:"status"`
+	Attempts       int                           `json:"attempts"`
+	Log            []deliveryAttemptRecord       `json:"log,omitempty"`
+	NextAttemptAt  time.Time                     `json:"next_attempt_at"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	DeliveredAt    time.Time                     `json:"delivered_at"`
+}
+
+// deliveryAttemptRecord represents a single attempt of a delivery.
+type deliveryAttemptRecord struct {
+	At         time.Time     `json:"at"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// newDeliveryRecord converts walletscreener.Delivery into deliveryRecord.
+func newDeliveryRecord(delivery *walletscreener.Delivery) *deliveryRecord {
+	record := deliveryRecord{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		Event:          delivery.Event,
+		ScreeningID:    delivery.ScreeningID,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	for _, v := range delivery.Log {
+		record.Log = append(record.Log, deliveryAttemptRecord(v))
+	}
+	return &record
+}
+
+// delivery converts record into walletscreener.Delivery.
+func (r *deliveryRecord) delivery() *walletscreener.Delivery {
+	delivery := walletscreener.Delivery{
+		ID:             r.ID,
+		SubscriptionID: r.SubscriptionID,
+		Event:          r.Event,
+		ScreeningID:    r.ScreeningID,
+		Payload:        r.Payload,
+		Status:         r.Status,
+		Attempts:       r.Attempts,
+		NextAttemptAt:  r.NextAttemptAt,
+		CreatedAt:      r.CreatedAt,
+		DeliveredAt:    r.DeliveredAt,
+	}
+	for _, v := range r.Log {
+		delivery.Log = append(delivery.Log, walletscreener.DeliveryAttempt(v))
+	}
+	return &delivery
+}
+
+// set stores value encoded as JSON under key.
+// Webhooks are configuration and operational state rather than audit records, they are written and read without proofs.
+func (s *Store) set(ctx context.Context, key []byte, value any) error {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode value")
+	}
+
+	if _, err := s.db.Set(ctx, key, v); err != nil {
+		return errors.Wrap(err, "failed to store value")
+	}
+
+	return nil
+}
+
+// scan calls fn with every key and value stored under keys having prefix in order of keys.
+// Scan is stopped without error once fn returns errScanDone.
+func (s *Store) scan(ctx context.Context, prefix []byte, fn func(key, value []byte) error) error {
+	var seekKey []byte
+	for {
+		entries, err := s.db.Scan(ctx, &schema.ScanRequest{
+			Prefix:  prefix,
+			SeekKey: seekKey,
+			Limit:   listWalletsPageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, v := range entries.GetEntries() {
+			seekKey = v.GetKey()
+
+			if err := fn(v.GetKey(), v.GetValue()); err != nil {
+				if errors.Is(err, errScanDone) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if len(entries.GetEntries()) < listWalletsPageSize {
+			return nil
+		}
+	}
+}
+
+// getSubscription returns record of subscription identified by id, removed subscriptions are not found.
+func (s *Store) getSubscription(ctx context.Context, id string) (*subscriptionRecord, error) {
+	entry, err := s.db.Get(ctx, subscriptionKey(id))
+	if isKeyNotFound(err) {
+		return nil, errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get subscription")
+	}
+
+	var record subscriptionRecord
+	if err := json.Unmarshal(entry.GetValue(), &record); err != nil {
+		return nil, errors.Wrap(err, "failed to decode subscription")
+	}
+
+	if record.Removed {
+		return nil, errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+
+	return &record, nil
+}
+
+// PutSubscription implements walletscreener.WebhookStore.
+func (s *Store) PutSubscription(ctx context.Context, subscription *walletscreener.Subscription) error {
+	err := s.set(ctx, subscriptionKey(subscription.ID), &subscriptionRecord{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Secret:    subscription.Secret,
+		Events:    subscription.Events,
+		CreatedAt: subscription.CreatedAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store subscription")
+	}
+	return nil
+}
+
+// GetSubscription implements walletscreener.WebhookStore.
+func (s *Store) GetSubscription(ctx context.Context, id string) (*walletscreener.Subscription, error) {
+	record, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return record.subscription(), nil
+}
+
+// DeleteSubscription implements walletscreener.WebhookStore.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	record, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	record.Removed = true
+
+	if err := s.set(ctx, subscriptionKey(id), record); err != nil {
+		return errors.Wrap(err, "failed to delete subscription")
+	}
+	return nil
+}
+
+// ListSubscriptions implements walletscreener.WebhookStore.
+// Subscriptions are sorted by their creation.
+func (s *Store) ListSubscriptions(ctx context.Context) ([]*walletscreener.Subscription, error) {
+	prefix := []byte(subscriptionPrefix)
+
+	subscriptions := []*walletscreener.Subscription{}
+	err := s.scan(ctx, prefix, func(key, value []byte) error {
+		var record subscriptionRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return errors.Wrapf(err, "failed to decode subscription %s", bytes.TrimPrefix(key, prefix))
+		}
+
+		if !record.Removed {
+			subscriptions = append(subscriptions, record.subscription())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list subscriptions")
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}
+
+// PutDelivery implements walletscreener.WebhookStore.
+// Delivery is stored together with its pending index in a single transaction,
+// index of the previous next attempt is deleted once delivery is attempted again or is not pending anymore.
+func (s *Store) PutDelivery(ctx context.Context, delivery *walletscreener.Delivery) error {
+	key := deliveryKey(delivery.SubscriptionID, delivery.ID)
+
+	value, err := json.Marshal(newDeliveryRecord(delivery))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode delivery")
+	}
+
+	ops := []*schema.Op{kvOp(&schema.KeyValue{Key: key, Value: value})}
+
+	var next []byte
+	if delivery.Status == walletscreener.DeliveryStatusPending {
+		next = pendingKey(delivery)
+		ops = append(ops, kvOp(&schema.KeyValue{Key: next, Value: key}))
+	}
+
+	previous, err := s.GetDelivery(ctx, delivery.SubscriptionID, delivery.ID)
+	switch {
+	case errors.Is(err, walletscreener.ErrDeliveryNotFound):
+	case err != nil:
+		return errors.Wrap(err, "failed to store delivery")
+	case previous.Status == walletscreener.DeliveryStatusPending:
+		if prev := pendingKey(previous); !bytes.Equal(prev, next) {
+			ops = append(ops, kvOp(&schema.KeyValue{Key: prev, Value: key, Metadata: &schema.KVMetadata{Deleted: true}}))
+		}
+	}
+
+	if _, err := s.db.ExecAll(ctx, &schema.ExecAllRequest{Operations: ops}); err != nil {
+		return errors.Wrap(err, "failed to store delivery")
+	}
+	return nil
+}
+
+// IndexPendingDeliveries indexes pending deliveries stored by earlier versions without the pending index, see pendingKey.
+// Indexed deliveries are skipped, hence it is safe to call on every start.
+func (s *Store) IndexPendingDeliveries(ctx context.Context) error {
+	prefix := []byte(deliveryPrefix)
+
+	var ops []*schema.Op
+	err := s.scan(ctx, prefix, func(key, value []byte) error {
+		var record deliveryRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return errors.Wrapf(err, "failed to decode delivery %s", bytes.TrimPrefix(key, prefix))
+		}
+
+		if record.Status != walletscreener.DeliveryStatusPending {
+			return nil
+		}
+
+		pending := pendingKey(record.delivery())
+
+		_, err := s.db.Get(ctx, pending)
+		if isKeyNotFound(err) {
+			ops = append(ops, kvOp(&schema.KeyValue{Key: pending, Value: key}))
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to scan deliveries")
+	}
+
+	// transactions are kept within limit of entries immudb accepts at once
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > indexPageSize {
+			n = indexPageSize
+		}
+
+		if _, err := s.db.ExecAll(ctx, &schema.ExecAllRequest{Operations: ops[:n]}); err != nil {
+			return errors.Wrap(err, "failed to index pending deliveries")
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+// kvOp returns operation setting kv within a transaction.
+func kvOp(kv *schema.KeyValue) *schema.Op {
+	return &schema.Op{Operation: &schema.Op_Kv{Kv: kv}}
+}
+
+// GetDelivery implements walletscreener.WebhookStore.
+func (s *Store) GetDelivery(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+	entry, err := s.db.Get(ctx, deliveryKey(subscriptionID, id))
+	if isKeyNotFound(err) {
+		return nil, errors.Wrapf(walletscreener.ErrDeliveryNotFound, "id %s of subscription %s", id, subscriptionID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get delivery")
+	}
+
+	var record deliveryRecord
+	if err := json.Unmarshal(entry.GetValue(), &record); err != nil {
+		return nil, errors.Wrap(err, "failed to decode delivery")
+	}
+
+	return record.delivery(), nil
+}
+
+// ListDeliveries implements walletscreener.WebhookStore.
+// Deliveries are keyed by their random IDs, matching ones are sorted by their creation once scanned.
+func (s *Store) ListDeliveries(ctx context.Context, query *walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+	prefix := []byte(deliveryPrefix)
+	if query.SubscriptionID != "" {
+		prefix = deliveryKey(query.SubscriptionID, "")
+	}
+
+	deliveries := []*walletscreener.Delivery{}
+	err := s.scan(ctx, prefix, func(key, value []byte) error {
+		var record deliveryRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return errors.Wrapf(err, "failed to decode delivery %s", bytes.TrimPrefix(key, prefix))
+		}
+
+		if query.Status == "" || record.Status == query.Status {
+			deliveries = append(deliveries, record.delivery())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list deliveries")
+	}
+
+	walletscreener.SortDeliveries(deliveries, query.Descending)
+
+	if query.Limit > 0 && len(deliveries) > query.Limit {
+		deliveries = deliveries[:query.Limit]
+	}
+
+	return deliveries, nil
+}
+
+// ListDueDeliveries implements walletscreener.WebhookStore.
+// Pending index is scanned in order of next attempts until deliveries due by the given time or limit are exhausted,
+// index entries not matching stored deliveries are skipped.
+func (s *Store) ListDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]*walletscreener.Delivery, error) {
+	due := pendingKey(&walletscreener.Delivery{NextAttemptAt: dueBy.Add(time.Nanosecond)})
+
+	deliveries := []*walletscreener.Delivery{}
+	err := s.scan(ctx, []byte(pendingPrefix), func(key, value []byte) error {
+		if bytes.Compare(key, due) >= 0 || (limit > 0 && len(deliveries) >= limit) {
+			return errScanDone
+		}
+
+		entry, err := s.db.Get(ctx, value)
+		if isKeyNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get delivery %s", bytes.TrimPrefix(value, []byte(deliveryPrefix)))
+		}
+
+		var record deliveryRecord
+		if err := json.Unmarshal(entry.GetValue(), &record); err != nil {
+			return errors.Wrapf(err, "failed to decode delivery %s", bytes.TrimPrefix(value, []byte(deliveryPrefix)))
+		}
+
+		delivery := record.delivery()
+		if delivery.Status != walletscreener.DeliveryStatusPending || !bytes.Equal(pendingKey(delivery), key) {
+			return nil
+		}
+
+		deliveries = append(deliveries, delivery)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list due deliveries")
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,58 @@
+package immudb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWebhooks(t *testing.T) {
+	storetest.TestWebhooks(t, func(t *testing.T) walletscreener.WebhookStore {
+		return newTestServerStore(t)
+	})
+}
+
+func TestDueDeliveries(t *testing.T) {
+	storetest.TestDueDeliveries(t, func(t *testing.T) walletscreener.WebhookStore {
+		return newTestServerStore(t)
+	})
+}
+
+func TestIndexPendingDeliveries(t *testing.T) {
+	ctx := context.Background()
+	store := newTestServerStore(t)
+
+	due := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	deliveries := []*walletscreener.Delivery{
+		{ID: "1", SubscriptionID: "a", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: due, CreatedAt: due},
+		{ID: "2", SubscriptionID: "a", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusDead, Attempts: 1, NextAttemptAt: due, CreatedAt: due},
+	}
+
+	// deliveries are stored the way earlier versions stored them, without the pending index
+	for _, v := range deliveries {
+		if err := store.set(ctx, deliveryKey(v.SubscriptionID, v.ID), newDeliveryRecord(v)); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// indexing is repeated on every start
+	for i := 0; i < 2; i++ {
+		if err := store.IndexPendingDeliveries(ctx); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		got, err := store.ListDueDeliveries(ctx, due, 0)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		if diff := cmp.Diff([]*walletscreener.Delivery{deliveries[0]}, got); diff != "" {
+			t.Errorf("ListDueDeliveries() mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// walletKey returns a key under which screenings of given wallet on given chain are stored.
+// Keys are laid out as {chain}:{address} same as in immudb backend.
+func walletKey(chain walletscreener.Chain, address string) string {
+	return chain.String() + ":" + address
+}
+
+// Store is an in-memory implementation of walletscreener.ScreeningStore intended for tests and local development.
+// Store mirrors revision and history semantics of immudb backend: every stored screening is a new revision of wallet key.
+// Store is safe for concurrent use.
+type Store struct {
+	mu         sync.RWMutex
+	screenings map[string][]walletscreener.Screening // revisions per key, oldest first
+	watches    map[string]walletscreener.Watch       // watched wallets by key
+
+	subscriptions map[string]walletscreener.Subscription // webhook subscriptions by ID
+	deliveries    map[string]walletscreener.Delivery     // webhook deliveries by ID
+}
+
+// NewStore constructs and returns new empty Store.
+func NewStore() *Store {
+	return &Store{
+		screenings: make(map[string][]walletscreener.Screening),
+		watches:    make(map[string]walletscreener.Watch),
+
+		subscriptions: make(map[string]walletscreener.Subscription),
+		deliveries:    make(map[string]walletscreener.Delivery),
+	}
+}
+
+// StoreScreening implements walletscreener.ScreeningStore.
+func (s *Store) StoreScreening(ctx context.Context, screening *walletscreener.Screening) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := walletKey(screening.Chain, screening.Address)
+	s.screenings[key] = append(s.screenings[key], *screening)
+
+	return nil
+}
+
+// GetWalletScreenings implements walletscreener.ScreeningStore.
+// Cursor is a revision of the last screening of the previous page.
+func (s *Store) GetWalletScreenings(ctx context.Context, query *walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+	var cursor uint64
+	if query.Cursor != "" {
+		var err error
+		if cursor, err = strconv.ParseUint(query.Cursor, 10, 64); err != nil || cursor < 1 {
+			return nil, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is not a revision", query.Cursor))
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	screenings := s.screenings[walletKey(query.Chain, query.Address)]
+
+	if cursor > uint64(len(screenings)) {
+		return nil, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is past history of the wallet", query.Cursor))
+	}
+
+	var page walletscreener.ScreeningsPage
+	for i := range screenings {
+		if query.Descending {
+			i = len(screenings) - 1 - i
+		}
+
+		screening := revision(screenings[i], i)
+
+		// skip screenings up to the cursor in query order
+		if query.Cursor != "" && (!query.Descending && screening.Revision <= cursor || query.Descending && screening.Revision >= cursor) {
+			continue
+		}
+
+		if !query.Matches(screening) {
+			continue
+		}
+
+		if query.Limit > 0 && len(page.Screenings) == query.Limit {
+			page.Next = strconv.FormatUint(page.Screenings[len(page.Screenings)-1].Revision, 10)
+			break
+		}
+
+		page.Screenings = append(page.Screenings, screening)
+	}
+
+	return &page, nil
+}
+
+// GetLatestScreening implements walletscreener.ScreeningStore.
+func (s *Store) GetLatestScreening(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	screenings := s.screenings[walletKey(chain, address)]
+	if len(screenings) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrScreeningNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return revision(screenings[len(screenings)-1], len(screenings)-1), nil
+}
+
+// ListWallets implements walletscreener.ScreeningStore.
+// Addresses are sorted in the same order as keys are scanned by immudb backend.
+func (s *Store) ListWallets(ctx context.Context, chain walletscreener.Chain) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := walletKey(chain, "")
+
+	var addresses []string
+	for key := range s.screenings {
+		if strings.HasPrefix(key, prefix) {
+			addresses = append(addresses, strings.TrimPrefix(key, prefix))
+		}
+	}
+
+	sort.Strings(addresses)
+
+	return addresses, nil
+}
+
+// revision returns copy of screening stored at the given index of wallet history along its revision.
+func revision(screening walletscreener.Screening, i int) *walletscreener.Screening {
+	screening.Revision = uint64(i + 1)
+	return &screening
+}
@@ -0,0 +1,243 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newScreeningResult(address string, risk int, own ...string) *walletscreener.ScreeningResult {
+	result := &walletscreener.ScreeningResult{
+		Chain:       walletscreener.ChainEthereum,
+		Address:     address,
+		Provider:    "blockmate",
+		Risk:        risk,
+		CaseID:      "case",
+		RespondedAt: time.Date(2023, 10, 4, 15, 18, 22, 0, time.UTC),
+		Verdict:     walletscreener.VerdictAllow,
+	}
+	for _, v := range own {
+		result.OwnCategories = append(result.OwnCategories, &walletscreener.RiskCategory{Name: v, Risk: risk})
+	}
+	return result
+}
+
+// newScreening returns screening of result identified by id.
+func newScreening(id string, result *walletscreener.ScreeningResult) *walletscreener.Screening {
+	return &walletscreener.Screening{
+		ID:              id,
+		ScreenedAt:      time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC),
+		ScreeningResult: *result,
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	if _, err := store.GetLatestScreening(ctx, walletscreener.ChainEthereum, "a"); !errors.Is(err, walletscreener.ErrScreeningNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrScreeningNotFound)
+	}
+
+	for _, screening := range []*walletscreener.Screening{
+		newScreening("1", newScreeningResult("b", 10, "Gambling")),
+		newScreening("2", newScreeningResult("a", 10, "Gambling")),
+		newScreening("3", newScreeningResult("a", 0)),
+		newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet")),
+	} {
+		if err := store.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	t.Run("GetWalletScreenings", func(t *testing.T) {
+		page, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: "a"})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		expected := []*walletscreener.Screening{
+			newScreening("2", newScreeningResult("a", 10, "Gambling")),
+			newScreening("3", newScreeningResult("a", 0)),
+			newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet")),
+		}
+		for i, v := range expected {
+			v.Revision = uint64(i + 1)
+		}
+
+		if !cmp.Equal(page.Screenings, expected) || page.Next != "" {
+			t.Errorf("got %+v, want %+v", page, expected)
+		}
+	})
+
+	t.Run("GetLatestScreening", func(t *testing.T) {
+		screening, err := store.GetLatestScreening(ctx, walletscreener.ChainEthereum, "a")
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		expected := newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet"))
+		expected.Revision = 3
+
+		if !cmp.Equal(screening, expected) {
+			t.Errorf("got %+v, want %+v", screening, expected)
+		}
+	})
+
+	t.Run("ListWallets", func(t *testing.T) {
+		addresses, err := store.ListWallets(ctx, walletscreener.ChainEthereum)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if expected := []string{"a", "b"}; !cmp.Equal(addresses, expected) {
+			t.Errorf("got %v, want %v", addresses, expected)
+		}
+
+		addresses, err = store.ListWallets(ctx, walletscreener.ChainBitcoin)
+		if err != nil || len(addresses) != 0 {
+			t.Errorf("got %v %v, want no addresses", addresses, err)
+		}
+	})
+}
+
+func TestStoreConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.StoreScreening(ctx, walletscreener.NewScreening(newScreeningResult("a", 10, "Gambling"))); err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+			if _, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: "a"}); err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	page, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: "a"})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	screenings := page.Screenings
+	if len(screenings) != 10 {
+		t.Fatalf("got %d screenings, want %d", len(screenings), 10)
+	}
+
+	for i, v := range screenings {
+		if v.Revision != uint64(i+1) {
+			t.Errorf("#%d revision got %v, want %v", i, v.Revision, i+1)
+		}
+	}
+}
+
+func TestGetWalletScreeningsQuery(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	at := time.Date(2023, 10, 4, 15, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		screening := newScreening(strconv.Itoa(i), newScreeningResult("a", 10))
+		screening.ScreenedAt = at.Add(time.Duration(i) * time.Minute)
+		if err := store.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var testcases = []struct {
+		query      walletscreener.ScreeningsQuery
+		screenings []string
+		next       string
+		err        error
+	}{
+		{
+			query:      walletscreener.ScreeningsQuery{Limit: 2},
+			screenings: []string{"1", "2"},
+			next:       "2",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "2", Limit: 2},
+			screenings: []string{"3", "4"},
+			next:       "4",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "4", Limit: 2},
+			screenings: []string{"5"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Descending: true, Limit: 2},
+			screenings: []string{"5", "4"},
+			next:       "4",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "4", Descending: true},
+			screenings: []string{"3", "2", "1"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Since: at.Add(2 * time.Minute), Until: at.Add(4 * time.Minute)},
+			screenings: []string{"2", "3"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Since: at.Add(2 * time.Minute), Descending: true, Limit: 1},
+			screenings: []string{"5"},
+			next:       "5",
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "x"},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "0"},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "6"},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "6", Descending: true},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+	}
+
+	for i, tt := range testcases {
+		tt.query.Chain, tt.query.Address = walletscreener.ChainEthereum, "a"
+
+		page, err := store.GetWalletScreenings(ctx, &tt.query)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		var screenings []string
+		for _, v := range page.Screenings {
+			screenings = append(screenings, v.ID)
+		}
+
+		if !cmp.Equal(screenings, tt.screenings) || page.Next != tt.next {
+			t.Errorf("#%d got %v %q, want %v %q", i, screenings, page.Next, tt.screenings, tt.next)
+		}
+	}
+}
+
+func TestScreeningsCursor(t *testing.T) {
+	storetest.TestScreeningsCursor(t, func(t *testing.T) walletscreener.ScreeningStore {
+		return NewStore()
+	})
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// PutWatch implements walletscreener.WatchlistStore.
+func (s *Store) PutWatch(ctx context.Context, watch *walletscreener.Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watches[walletKey(watch.Chain, watch.Address)] = *watch
+
+	return nil
+}
+
+// GetWatch implements walletscreener.WatchlistStore.
+func (s *Store) GetWatch(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	watch, ok := s.watches[walletKey(chain, address)]
+	if !ok {
+		return nil, errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return &watch, nil
+}
+
+// DeleteWatch implements walletscreener.WatchlistStore.
+func (s *Store) DeleteWatch(ctx context.Context, chain walletscreener.Chain, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := walletKey(chain, address)
+	if _, ok := s.watches[key]; !ok {
+		return errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+
+	delete(s.watches, key)
+
+	return nil
+}
+
+// ListWatches implements walletscreener.WatchlistStore.
+// Watches are sorted by their keys same as listed by immudb backend.
+func (s *Store) ListWatches(ctx context.Context) ([]*walletscreener.Watch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.watches))
+	for key := range s.watches {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	watches := make([]*walletscreener.Watch, 0, len(keys))
+	for _, key := range keys {
+		watch := s.watches[key]
+		watches = append(watches, &watch)
+	}
+
+	return watches, nil
+}
@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// PutSubscription implements walletscreener.WebhookStore.
+func (s *Store) PutSubscription(ctx context.Context, subscription *walletscreener.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[subscription.ID] = *subscription
+
+	return nil
+}
+
+// GetSubscription implements walletscreener.WebhookStore.
+func (s *Store) GetSubscription(ctx context.Context, id string) (*walletscreener.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return nil, errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+
+	return &subscription, nil
+}
+
+// DeleteSubscription implements walletscreener.WebhookStore.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+
+	delete(s.subscriptions, id)
+
+	return nil
+}
+
+// ListSubscriptions implements walletscreener.WebhookStore.
+// Subscriptions are sorted by their creation.
+func (s *Store) ListSubscriptions(ctx context.Context) ([]*walletscreener.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]*walletscreener.Subscription, 0, len(s.subscriptions))
+	for _, v := range s.subscriptions {
+		subscription := v
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}
+
+// PutDelivery implements walletscreener.WebhookStore.
+func (s *Store) PutDelivery(ctx context.Context, delivery *walletscreener.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := *delivery
+	v.Log = append([]walletscreener.DeliveryAttempt(nil), delivery.Log...)
+	s.deliveries[delivery.ID] = v
+
+	return nil
+}
+
+// GetDelivery implements walletscreener.WebhookStore.
+func (s *Store) GetDelivery(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok || delivery.SubscriptionID != subscriptionID {
+		return nil, errors.Wrapf(walletscreener.ErrDeliveryNotFound, "id %s of subscription %s", id, subscriptionID)
+	}
+
+	delivery.Log = append([]walletscreener.DeliveryAttempt(nil), delivery.Log...)
+
+	return &delivery, nil
+}
+
+// ListDeliveries implements walletscreener.WebhookStore.
+func (s *Store) ListDeliveries(ctx context.Context, query *walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []*walletscreener.Delivery{}
+	for _, v := range s.deliveries {
+		if query.SubscriptionID != "" && v.SubscriptionID != query.SubscriptionID {
+			continue
+		}
+		if query.Status != "" && v.Status != query.Status {
+			continue
+		}
+
+		delivery := v
+		delivery.Log = append([]walletscreener.DeliveryAttempt(nil), v.Log...)
+		deliveries = append(deliveries, &delivery)
+	}
+
+	walletscreener.SortDeliveries(deliveries, query.Descending)
+
+	if query.Limit > 0 && len(deliveries) > query.Limit {
+		deliveries = deliveries[:query.Limit]
+	}
+
+	return deliveries, nil
+}
+
+// ListDueDeliveries implements walletscreener.WebhookStore.
+func (s *Store) ListDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]*walletscreener.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []*walletscreener.Delivery{}
+	for _, v := range s.deliveries {
+		if v.Status != walletscreener.DeliveryStatusPending || v.NextAttemptAt.After(dueBy) {
+			continue
+		}
+
+		delivery := v
+		delivery.Log = append([]walletscreener.DeliveryAttempt(nil), v.Log...)
+		deliveries = append(deliveries, &delivery)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
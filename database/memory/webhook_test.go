@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+)
+
+func TestWebhooks(t *testing.T) {
+	storetest.TestWebhooks(t, func(t *testing.T) walletscreener.WebhookStore {
+		return NewStore()
+	})
+}
+
+func TestDueDeliveries(t *testing.T) {
+	storetest.TestDueDeliveries(t, func(t *testing.T) walletscreener.WebhookStore {
+		return NewStore()
+	})
+}
@@ -0,0 +1,76 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// migrations contains schema migrations per dialect, migrations are applied in order of their file names.
+//
+//go:embed migrations
+var migrations embed.FS
+
+// migrate applies schema migrations of the dialect not applied yet, each migration is applied within its own transaction.
+func migrate(ctx context.Context, db *sql.DB, dialect string) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)`); err != nil {
+		return errors.Wrap(err, "failed to create migrations table")
+	}
+
+	dir := path.Join("migrations", dialect)
+
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s migrations", dialect)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	for _, entry := range entries {
+		if err := applyMigration(ctx, db, path.Join(dir, entry.Name()), entry.Name()); err != nil {
+			return errors.Wrapf(err, "failed to apply migration %s", entry.Name())
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies migration stored in file under given version unless it was already applied.
+func applyMigration(ctx context.Context, db *sql.DB, file, version string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, version).Scan(&applied); err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		return nil
+	}
+
+	schema, err := migrations.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, string(schema)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
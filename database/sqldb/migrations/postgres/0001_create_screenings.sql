@@ -0,0 +1,40 @@
+-- Screenings are append-only, every screening of a wallet is a new row.
+CREATE TABLE screenings (
+    id            BIGSERIAL   PRIMARY KEY,
+    chain         TEXT        NOT NULL,
+    address       TEXT        NOT NULL,
+    provider      TEXT        NOT NULL DEFAULT '',
+    entity        TEXT        NOT NULL DEFAULT '',
+    risk          INTEGER     NOT NULL DEFAULT 0,
+    case_id       TEXT        NOT NULL DEFAULT '',
+    verdict       TEXT        NOT NULL DEFAULT '',
+    matched_rules TEXT        NOT NULL DEFAULT '[]',
+    requested_at  TIMESTAMPTZ NOT NULL,
+    responded_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX screenings_wallet_idx ON screenings (chain, address, id);
+
+CREATE TABLE screening_categories (
+    id           BIGSERIAL PRIMARY KEY,
+    screening_id BIGINT    NOT NULL REFERENCES screenings (id),
+    source       TEXT      NOT NULL,
+    name         TEXT      NOT NULL,
+    entity       TEXT      NOT NULL DEFAULT '',
+    risk         INTEGER   NOT NULL DEFAULT 0,
+    providers    TEXT      NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX screening_categories_screening_idx ON screening_categories (screening_id, id);
+
+CREATE FUNCTION screenings_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'screenings are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER screenings_append_only BEFORE UPDATE OR DELETE ON screenings
+    FOR EACH ROW EXECUTE FUNCTION screenings_append_only();
+
+CREATE TRIGGER screening_categories_append_only BEFORE UPDATE OR DELETE ON screening_categories
+    FOR EACH ROW EXECUTE FUNCTION screenings_append_only();
@@ -0,0 +1,4 @@
+-- Screenings are identified by a unique ID and the time they were recorded at.
+-- Rows stored before have neither, they are read as identified by their row ID and screened when provider responded.
+ALTER TABLE screenings ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+ALTER TABLE screenings ADD COLUMN screened_at TIMESTAMPTZ;
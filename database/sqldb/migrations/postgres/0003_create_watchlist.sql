@@ -0,0 +1,12 @@
+-- Watched wallets are re-screened periodically, a wallet is watched at most once per chain.
+-- Interval is stored in nanoseconds same as time.Duration.
+CREATE TABLE watchlist (
+    chain            TEXT        NOT NULL,
+    address          TEXT        NOT NULL,
+    interval_ns      BIGINT      NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL,
+    next_at          TIMESTAMPTZ NOT NULL,
+    last_screened_at TIMESTAMPTZ,
+    last_error       TEXT        NOT NULL DEFAULT '',
+    PRIMARY KEY (chain, address)
+);
@@ -0,0 +1,27 @@
+-- Webhook subscriptions are notified about screening events they filter, every event if events are empty.
+CREATE TABLE webhook_subscriptions (
+    id         TEXT        PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT        NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Deliveries are kept once delivered or dead, log of their attempts is stored as JSON.
+-- Deliveries of deleted subscriptions are kept, hence no reference to subscriptions.
+CREATE TABLE webhook_deliveries (
+    id              TEXT        PRIMARY KEY,
+    subscription_id TEXT        NOT NULL,
+    event           TEXT        NOT NULL,
+    screening_id    TEXT        NOT NULL,
+    payload         TEXT        NOT NULL,
+    status          TEXT        NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    log             TEXT        NOT NULL DEFAULT '[]',
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at);
+CREATE INDEX webhook_deliveries_status_idx ON webhook_deliveries (status, next_attempt_at);
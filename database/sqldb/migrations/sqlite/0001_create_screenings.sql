@@ -0,0 +1,48 @@
+-- Screenings are append-only, every screening of a wallet is a new row.
+CREATE TABLE screenings (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain         TEXT     NOT NULL,
+    address       TEXT     NOT NULL,
+    provider      TEXT     NOT NULL DEFAULT '',
+    entity        TEXT     NOT NULL DEFAULT '',
+    risk          INTEGER  NOT NULL DEFAULT 0,
+    case_id       TEXT     NOT NULL DEFAULT '',
+    verdict       TEXT     NOT NULL DEFAULT '',
+    matched_rules TEXT     NOT NULL DEFAULT '[]',
+    requested_at  DATETIME NOT NULL,
+    responded_at  DATETIME NOT NULL
+);
+
+CREATE INDEX screenings_wallet_idx ON screenings (chain, address, id);
+
+CREATE TABLE screening_categories (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    screening_id INTEGER NOT NULL REFERENCES screenings (id),
+    source       TEXT    NOT NULL,
+    name         TEXT    NOT NULL,
+    entity       TEXT    NOT NULL DEFAULT '',
+    risk         INTEGER NOT NULL DEFAULT 0,
+    providers    TEXT    NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX screening_categories_screening_idx ON screening_categories (screening_id, id);
+
+CREATE TRIGGER screenings_append_only_update BEFORE UPDATE ON screenings
+BEGIN
+    SELECT RAISE(ABORT, 'screenings are append-only');
+END;
+
+CREATE TRIGGER screenings_append_only_delete BEFORE DELETE ON screenings
+BEGIN
+    SELECT RAISE(ABORT, 'screenings are append-only');
+END;
+
+CREATE TRIGGER screening_categories_append_only_update BEFORE UPDATE ON screening_categories
+BEGIN
+    SELECT RAISE(ABORT, 'screenings are append-only');
+END;
+
+CREATE TRIGGER screening_categories_append_only_delete BEFORE DELETE ON screening_categories
+BEGIN
+    SELECT RAISE(ABORT, 'screenings are append-only');
+END;
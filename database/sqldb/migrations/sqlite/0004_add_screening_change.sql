@@ -0,0 +1,3 @@
+-- Screenings record their change from the previous screening of the wallet as JSON.
+-- The first screening of a wallet and rows stored before have none.
+ALTER TABLE screenings ADD COLUMN risk_change TEXT;
@@ -0,0 +1,470 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	_ "github.com/lib/pq"  // registers postgres driver
+	_ "modernc.org/sqlite" // registers sqlite driver
+)
+
+// Store is an implementation of walletscreener.ScreeningStore backed by SQLite or PostgreSQL database.
+// Screenings are stored into append-only tables, every screening is a new row along rows of its categories.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens database selected by cfg.Driver, either database.DriverSQLite or database.DriverPostgres, and migrates its schema.
+// SQLite database is stored in the file cfg.Database.
+func Open(ctx context.Context, cfg *database.Config) (*Store, error) {
+	var (
+		driverName string
+		dsn        string
+	)
+
+	switch cfg.Driver {
+	case database.DriverSQLite:
+		driverName, dsn = "sqlite", cfg.Database
+	case database.DriverPostgres:
+		driverName, dsn = "postgres", postgresDSN(cfg)
+	default:
+		return nil, errors.Newf("sqldb: unsupported driver %s", cfg.Driver)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "sqldb: unable to open %s database", cfg.Driver)
+	}
+
+	if cfg.Driver == database.DriverSQLite {
+		// SQLite allows a single writer, serialising connections avoids busy errors
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := migrate(ctx, db, cfg.Driver); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "sqldb: unable to migrate database schema")
+	}
+
+	return &Store{
+		db: db,
+	}, nil
+}
+
+// postgresDSN returns PostgreSQL connection URL from cfg.
+func postgresDSN(cfg *database.Config) string {
+	query := url.Values{}
+	if cfg.SSLMode != "" {
+		query.Set("sslmode", cfg.SSLMode)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     cfg.Host + ":" + strconv.Itoa(cfg.Port),
+		Path:     cfg.Database,
+		RawQuery: query.Encode(),
+	}
+
+	return dsn.String()
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// StoreScreening implements walletscreener.ScreeningStore.
+func (s *Store) StoreScreening(ctx context.Context, screening *walletscreener.Screening) error {
+	matchedRules, err := json.Marshal(nonNil(screening.MatchedRules))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode matched rules")
+	}
+
+	change, err := newRiskChange(screening.Change)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode risk change")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO screenings (uid, screened_at, chain, address, provider, entity, risk, case_id, verdict, matched_rules, requested_at, responded_at, risk_change)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`,
+		screening.ID, screening.ScreenedAt.UTC(), screening.Chain.String(), screening.Address, screening.Provider, screening.Entity, screening.Risk,
+		screening.CaseID, string(screening.Verdict), string(matchedRules), screening.RequestedAt.UTC(), screening.RespondedAt.UTC(), change,
+	).Scan(&id)
+	if err != nil {
+		return errors.Wrap(err, "failed to store screening")
+	}
+
+	insertCategories := func(source walletscreener.RiskCategorySource, categories []*walletscreener.RiskCategory) error {
+		for _, v := range categories {
+			providers, err := json.Marshal(nonNil(v.Providers))
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO screening_categories (screening_id, source, name, entity, risk, providers)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				id, string(source), v.Name, v.Entity, v.Risk, string(providers),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := insertCategories(walletscreener.RiskCategorySourceOwn, screening.OwnCategories); err != nil {
+		return errors.Wrap(err, "failed to store own categories")
+	}
+
+	if err := insertCategories(walletscreener.RiskCategorySourceSourceOfFunds, screening.SourceOfFundsCategories); err != nil {
+		return errors.Wrap(err, "failed to store source of funds categories")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit screening")
+	}
+
+	return nil
+}
+
+// selectScreenings selects screenings of the wallet given by $1 chain and $2 address along their revisions.
+// Rows stored before screenings were identified by unique ID are identified by their row ID and have no screened_at.
+const selectScreenings = `
+	SELECT id, uid, revision, screened_at, provider, entity, risk, case_id, verdict, matched_rules, requested_at, responded_at, risk_change
+	FROM (
+		SELECT id, COALESCE(NULLIF(uid, ''), CAST(id AS TEXT)) AS uid, ROW_NUMBER() OVER (ORDER BY id) AS revision,
+			screened_at, COALESCE(screened_at, responded_at) AS recorded_at, provider, entity, risk, case_id, verdict, matched_rules, requested_at, responded_at, risk_change
+		FROM screenings
+		WHERE chain = $1 AND address = $2
+	) wallet_screenings`
+
+// GetWalletScreenings implements walletscreener.ScreeningStore.
+// Cursor is a revision of the last screening of the previous page.
+func (s *Store) GetWalletScreenings(ctx context.Context, query *walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	// arg adds query argument returning its placeholder, $1 and $2 are taken by chain and address
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args)+2)
+	}
+
+	order := "ASC"
+	if query.Descending {
+		order = "DESC"
+	}
+
+	if query.Cursor != "" {
+		cursor, err := strconv.ParseUint(query.Cursor, 10, 64)
+		if err != nil || cursor < 1 {
+			return nil, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is not a revision", query.Cursor))
+		}
+
+		// revisions are numbered from 1 up to the number of wallet screenings
+		var revisions uint64
+		err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM screenings WHERE chain = $1 AND address = $2`, query.Chain.String(), query.Address).Scan(&revisions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count screenings of address %s on chain %s", query.Address, query.Chain)
+		}
+
+		if cursor > revisions {
+			return nil, errors.WithReason(walletscreener.ErrInvalidCursor, errors.Newf("cursor %q is past history of the wallet", query.Cursor))
+		}
+
+		if query.Descending {
+			conditions = append(conditions, "revision < "+arg(cursor))
+		} else {
+			conditions = append(conditions, "revision > "+arg(cursor))
+		}
+	}
+
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "recorded_at >= "+arg(query.Since.UTC()))
+	}
+
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "recorded_at < "+arg(query.Until.UTC()))
+	}
+
+	limit := int64(math.MaxInt64) // portable way of no limit across SQLite and PostgreSQL
+	if query.Limit > 0 {
+		limit = int64(query.Limit) + 1 // one more screening tells whether there is a next page
+	}
+
+	statement := selectScreenings
+	if len(conditions) > 0 {
+		statement += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	statement += `
+		ORDER BY id ` + order + `
+		LIMIT ` + arg(limit)
+
+	screenings, err := s.queryScreenings(ctx, query.Chain, query.Address, statement, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve screening history for address %s on chain %s", query.Address, query.Chain)
+	}
+
+	var page walletscreener.ScreeningsPage
+	if query.Limit > 0 && len(screenings) > query.Limit {
+		screenings = screenings[:query.Limit]
+		page.Next = strconv.FormatUint(screenings[len(screenings)-1].Revision, 10)
+	}
+	page.Screenings = screenings
+
+	return &page, nil
+}
+
+// GetLatestScreening implements walletscreener.ScreeningStore.
+func (s *Store) GetLatestScreening(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+	screenings, err := s.queryScreenings(ctx, chain, address, selectScreenings+`
+		ORDER BY id DESC
+		LIMIT 1`,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve latest screening for address %s on chain %s", address, chain)
+	}
+
+	if len(screenings) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrScreeningNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return screenings[0], nil
+}
+
+// queryScreenings queries screenings of the wallet using query built on selectScreenings along their categories.
+// Additional query arguments are numbered from $3.
+func (s *Store) queryScreenings(ctx context.Context, chain walletscreener.Chain, address string, query string, args ...any) ([]*walletscreener.Screening, error) {
+	rows, err := s.db.QueryContext(ctx, query, append([]any{chain.String(), address}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		screenings []*walletscreener.Screening
+		byID       = make(map[int64]*walletscreener.Screening)
+		minID      int64
+		maxID      int64
+	)
+	for rows.Next() {
+		var (
+			id           int64
+			screenedAt   sql.NullTime
+			matchedRules string
+			change       sql.NullString
+			screening    = walletscreener.Screening{
+				ScreeningResult: walletscreener.ScreeningResult{
+					Chain:   chain,
+					Address: address,
+				},
+			}
+		)
+
+		err := rows.Scan(&id, &screening.ID, &screening.Revision, &screenedAt, &screening.Provider, &screening.Entity, &screening.Risk,
+			&screening.CaseID, &screening.Verdict, &matchedRules, &screening.RequestedAt, &screening.RespondedAt, &change)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan screening")
+		}
+
+		screening.RequestedAt = screening.RequestedAt.UTC()
+		screening.RespondedAt = screening.RespondedAt.UTC()
+		screening.ScreenedAt = screening.RespondedAt
+		if screenedAt.Valid {
+			screening.ScreenedAt = screenedAt.Time.UTC()
+		}
+
+		if err := json.Unmarshal([]byte(matchedRules), &screening.MatchedRules); err != nil {
+			return nil, errors.Wrap(err, "failed to decode matched rules")
+		}
+		screening.MatchedRules = nilIfEmpty(screening.MatchedRules)
+
+		if screening.Change, err = riskChange(change); err != nil {
+			return nil, errors.Wrap(err, "failed to decode risk change")
+		}
+
+		if len(screenings) < 1 || id < minID {
+			minID = id
+		}
+		if len(screenings) < 1 || id > maxID {
+			maxID = id
+		}
+
+		screenings = append(screenings, &screening)
+		byID[id] = &screening
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve screenings")
+	}
+
+	if len(screenings) < 1 {
+		return nil, nil
+	}
+
+	if err := s.queryCategories(ctx, chain, address, minID, maxID, byID); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve screening categories")
+	}
+
+	return screenings, nil
+}
+
+// queryCategories queries categories of the wallet screenings stored between minID and maxID and appends them to screenings by their ID.
+func (s *Store) queryCategories(ctx context.Context, chain walletscreener.Chain, address string, minID, maxID int64, screenings map[int64]*walletscreener.Screening) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.screening_id, c.source, c.name, c.entity, c.risk, c.providers
+		FROM screening_categories c
+		JOIN screenings s ON s.id = c.screening_id
+		WHERE s.chain = $1 AND s.address = $2 AND c.screening_id BETWEEN $3 AND $4
+		ORDER BY c.id`,
+		chain.String(), address, minID, maxID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id        int64
+			category  walletscreener.RiskCategory
+			source    walletscreener.RiskCategorySource
+			providers string
+		)
+
+		if err := rows.Scan(&id, &source, &category.Name, &category.Entity, &category.Risk, &providers); err != nil {
+			return errors.Wrap(err, "failed to scan category")
+		}
+
+		screening, ok := screenings[id]
+		if !ok {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(providers), &category.Providers); err != nil {
+			return errors.Wrap(err, "failed to decode category providers")
+		}
+		category.Providers = nilIfEmpty(category.Providers)
+
+		switch source {
+		case walletscreener.RiskCategorySourceSourceOfFunds:
+			screening.SourceOfFundsCategories = append(screening.SourceOfFundsCategories, &category)
+		default:
+			screening.OwnCategories = append(screening.OwnCategories, &category)
+		}
+	}
+
+	return rows.Err()
+}
+
+// ListWallets implements walletscreener.ScreeningStore.
+func (s *Store) ListWallets(ctx context.Context, chain walletscreener.Chain) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT address FROM screenings WHERE chain = $1 ORDER BY address`, chain.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list wallets on chain %s", chain)
+	}
+	defer rows.Close()
+
+	var addresses []string
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			return nil, errors.Wrap(err, "failed to scan address")
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to list wallets on chain %s", chain)
+	}
+
+	return addresses, nil
+}
+
+// riskChangeRecord represents change of a screening from the previous one stored as JSON.
+type riskChangeRecord struct {
+	PreviousID        string                 `json:"previous_id"`
+	PreviousVerdict   walletscreener.Verdict `json:"previous_verdict,omitempty"`
+	AddedCategories   []string               `json:"added_categories,omitempty"`
+	RemovedCategories []string               `json:"removed_categories,omitempty"`
+	RiskDelta         int                    `json:"risk_delta"`
+}
+
+// newRiskChange encodes change into stored value, screenings without change are stored as NULL.
+func newRiskChange(change *walletscreener.RiskChange) (sql.NullString, error) {
+	if change == nil {
+		return sql.NullString{}, nil
+	}
+
+	value, err := json.Marshal(&riskChangeRecord{
+		PreviousID:        change.PreviousID,
+		PreviousVerdict:   change.PreviousVerdict,
+		AddedCategories:   change.AddedCategories,
+		RemovedCategories: change.RemovedCategories,
+		RiskDelta:         change.RiskDelta,
+	})
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(value), Valid: true}, nil
+}
+
+// riskChange decodes stored value into change, NULL values are screenings without change.
+func riskChange(value sql.NullString) (*walletscreener.RiskChange, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var record riskChangeRecord
+	if err := json.Unmarshal([]byte(value.String), &record); err != nil {
+		return nil, err
+	}
+
+	return &walletscreener.RiskChange{
+		PreviousID:        record.PreviousID,
+		PreviousVerdict:   record.PreviousVerdict,
+		AddedCategories:   record.AddedCategories,
+		RemovedCategories: record.RemovedCategories,
+		RiskDelta:         record.RiskDelta,
+	}, nil
+}
+
+// nonNil returns s or empty slice if s is nil, so that it is encoded as JSON array.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nilIfEmpty returns nil for empty s, mirroring values of results before they were stored.
+func nilIfEmpty(s []string) []string {
+	if len(s) < 1 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,285 @@
+package sqldb
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestStore returns Store backed by SQLite database in a temporary directory.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(context.Background(), &database.Config{
+		Driver:   database.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "screenings.db"),
+	})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func newScreeningResult(address string, risk int, own ...string) *walletscreener.ScreeningResult {
+	result := &walletscreener.ScreeningResult{
+		Chain:        walletscreener.ChainEthereum,
+		Address:      address,
+		Provider:     "blockmate",
+		Entity:       "unknown",
+		Risk:         risk,
+		CaseID:       "case",
+		RequestedAt:  time.Date(2023, 10, 4, 15, 18, 21, 0, time.UTC),
+		RespondedAt:  time.Date(2023, 10, 4, 15, 18, 22, 0, time.UTC),
+		Verdict:      walletscreener.VerdictReview,
+		MatchedRules: []string{"review: risk score"},
+	}
+	for _, v := range own {
+		result.OwnCategories = append(result.OwnCategories, &walletscreener.RiskCategory{Name: v, Risk: risk, Providers: []string{"blockmate"}})
+	}
+	result.SourceOfFundsCategories = []*walletscreener.RiskCategory{{Name: "Exchange", Entity: "Binance", Risk: 0}}
+	return result
+}
+
+// newScreening returns screening of result identified by id.
+func newScreening(id string, result *walletscreener.ScreeningResult) *walletscreener.Screening {
+	return &walletscreener.Screening{
+		ID:              id,
+		ScreenedAt:      time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC),
+		ScreeningResult: *result,
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	if _, err := store.GetLatestScreening(ctx, walletscreener.ChainEthereum, "a"); !errors.Is(err, walletscreener.ErrScreeningNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrScreeningNotFound)
+	}
+
+	for _, screening := range []*walletscreener.Screening{
+		newScreening("1", newScreeningResult("b", 10, "Gambling")),
+		newScreening("2", newScreeningResult("a", 10, "Gambling")),
+		newScreening("3", newScreeningResult("a", 0)),
+		newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet")),
+	} {
+		if err := store.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	t.Run("GetWalletScreenings", func(t *testing.T) {
+		page, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: "a"})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		expected := []*walletscreener.Screening{
+			newScreening("2", newScreeningResult("a", 10, "Gambling")),
+			newScreening("3", newScreeningResult("a", 0)),
+			newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet")),
+		}
+		for i, v := range expected {
+			v.Revision = uint64(i + 1)
+		}
+
+		if !cmp.Equal(page.Screenings, expected) || page.Next != "" {
+			t.Errorf("got %+v, want %+v", page, expected)
+		}
+	})
+
+	t.Run("GetLatestScreening", func(t *testing.T) {
+		screening, err := store.GetLatestScreening(ctx, walletscreener.ChainEthereum, "a")
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		expected := newScreening("4", newScreeningResult("a", 60, "Mixer", "Darknet"))
+		expected.Revision = 3
+
+		if !cmp.Equal(screening, expected) {
+			t.Errorf("got %+v, want %+v", screening, expected)
+		}
+	})
+
+	t.Run("ListWallets", func(t *testing.T) {
+		addresses, err := store.ListWallets(ctx, walletscreener.ChainEthereum)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if expected := []string{"a", "b"}; !cmp.Equal(addresses, expected) {
+			t.Errorf("got %v, want %v", addresses, expected)
+		}
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		if _, err := store.db.ExecContext(ctx, `DELETE FROM screenings`); err == nil {
+			t.Errorf("got %v, want error", err)
+		}
+	})
+}
+
+func TestStoreRiskChange(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	first := newScreening("1", newScreeningResult("a", 10, "Gambling"))
+	second := newScreening("2", newScreeningResult("a", 60, "Mixer"))
+	second.Change = walletscreener.NewRiskChange(first, second)
+
+	for _, v := range []*walletscreener.Screening{first, second} {
+		if err := store.StoreScreening(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	page, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{Chain: walletscreener.ChainEthereum, Address: "a"})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var changes []*walletscreener.RiskChange
+	for _, v := range page.Screenings {
+		changes = append(changes, v.Change)
+	}
+
+	expected := []*walletscreener.RiskChange{nil, {
+		PreviousID:        "1",
+		PreviousVerdict:   walletscreener.VerdictReview,
+		AddedCategories:   []string{"Mixer"},
+		RemovedCategories: []string{"Gambling"},
+		RiskDelta:         50,
+	}}
+
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetWalletScreeningsQuery(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	at := time.Date(2023, 10, 4, 15, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		screening := newScreening(strconv.Itoa(i), newScreeningResult("a", 10))
+		screening.ScreenedAt = at.Add(time.Duration(i) * time.Minute)
+		if err := store.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var testcases = []struct {
+		query      walletscreener.ScreeningsQuery
+		screenings []string
+		next       string
+		err        error
+	}{
+		{
+			query:      walletscreener.ScreeningsQuery{Limit: 2},
+			screenings: []string{"1", "2"},
+			next:       "2",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "2", Limit: 2},
+			screenings: []string{"3", "4"},
+			next:       "4",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "4", Limit: 2},
+			screenings: []string{"5"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Descending: true, Limit: 2},
+			screenings: []string{"5", "4"},
+			next:       "4",
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Cursor: "4", Descending: true},
+			screenings: []string{"3", "2", "1"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Since: at.Add(2 * time.Minute), Until: at.Add(4 * time.Minute)},
+			screenings: []string{"2", "3"},
+		},
+		{
+			query:      walletscreener.ScreeningsQuery{Since: at.Add(2 * time.Minute), Descending: true, Limit: 1},
+			screenings: []string{"5"},
+			next:       "5",
+		},
+		{
+			query: walletscreener.ScreeningsQuery{Cursor: "x"},
+			err:   walletscreener.ErrInvalidCursor,
+		},
+	}
+
+	for i, tt := range testcases {
+		tt.query.Chain, tt.query.Address = walletscreener.ChainEthereum, "a"
+
+		page, err := store.GetWalletScreenings(ctx, &tt.query)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		var screenings []string
+		for _, v := range page.Screenings {
+			screenings = append(screenings, v.ID)
+		}
+
+		if !cmp.Equal(screenings, tt.screenings) || page.Next != tt.next {
+			t.Errorf("#%d got %v %q, want %v %q", i, screenings, page.Next, tt.screenings, tt.next)
+		}
+	}
+}
+
+func TestOpenMigratesOnce(t *testing.T) {
+	cfg := &database.Config{
+		Driver:   database.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "screenings.db"),
+	}
+
+	for i := 0; i < 2; i++ {
+		store, err := Open(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+		store.Close()
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	dsn := postgresDSN(&database.Config{
+		Driver:   database.DriverPostgres,
+		Host:     "db",
+		Port:     5432,
+		Username: "screener",
+		Password: "p@ss",
+		Database: "screenings",
+		SSLMode:  "disable",
+	})
+
+	if expected := "postgres://screener:p%40ss@db:5432/screenings?sslmode=disable"; dsn != expected {
+		t.Errorf("got %v, want %v", dsn, expected)
+	}
+}
+
+func TestScreeningsCursor(t *testing.T) {
+	storetest.TestScreeningsCursor(t, func(t *testing.T) walletscreener.ScreeningStore {
+		return newTestStore(t)
+	})
+}
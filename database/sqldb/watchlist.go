@@ -0,0 +1,120 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// PutWatch implements walletscreener.WatchlistStore.
+func (s *Store) PutWatch(ctx context.Context, watch *walletscreener.Watch) error {
+	var lastScreenedAt sql.NullTime
+	if !watch.LastScreenedAt.IsZero() {
+		lastScreenedAt = sql.NullTime{Time: watch.LastScreenedAt.UTC(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO watchlist (chain, address, interval_ns, created_at, next_at, last_screened_at, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (chain, address) DO UPDATE SET
+			interval_ns = excluded.interval_ns,
+			created_at = excluded.created_at,
+			next_at = excluded.next_at,
+			last_screened_at = excluded.last_screened_at,
+			last_error = excluded.last_error`,
+		watch.Chain.String(), watch.Address, int64(watch.Interval), watch.CreatedAt.UTC(), watch.NextAt.UTC(), lastScreenedAt, watch.LastError,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store watch")
+	}
+
+	return nil
+}
+
+// GetWatch implements walletscreener.WatchlistStore.
+func (s *Store) GetWatch(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+	watches, err := s.queryWatches(ctx, `
+		SELECT chain, address, interval_ns, created_at, next_at, last_screened_at, last_error
+		FROM watchlist
+		WHERE chain = $1 AND address = $2`,
+		chain.String(), address,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(watches) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return watches[0], nil
+}
+
+// DeleteWatch implements walletscreener.WatchlistStore.
+func (s *Store) DeleteWatch(ctx context.Context, chain walletscreener.Chain, address string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM watchlist WHERE chain = $1 AND address = $2`, chain.String(), address)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete watch")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to delete watch")
+	}
+
+	if deleted < 1 {
+		return errors.Wrapf(walletscreener.ErrWatchNotFound, "address %s on chain %s", address, chain)
+	}
+
+	return nil
+}
+
+// ListWatches implements walletscreener.WatchlistStore.
+func (s *Store) ListWatches(ctx context.Context) ([]*walletscreener.Watch, error) {
+	return s.queryWatches(ctx, `
+		SELECT chain, address, interval_ns, created_at, next_at, last_screened_at, last_error
+		FROM watchlist
+		ORDER BY chain, address`,
+	)
+}
+
+// queryWatches returns watches selected by query.
+func (s *Store) queryWatches(ctx context.Context, query string, args ...any) ([]*walletscreener.Watch, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query watches")
+	}
+	defer rows.Close()
+
+	watches := []*walletscreener.Watch{}
+	for rows.Next() {
+		var (
+			watch          walletscreener.Watch
+			interval       int64
+			lastScreenedAt sql.NullTime
+		)
+
+		err := rows.Scan(&watch.Chain, &watch.Address, &interval, &watch.CreatedAt, &watch.NextAt, &lastScreenedAt, &watch.LastError)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan watch")
+		}
+
+		watch.Interval = time.Duration(interval)
+		watch.CreatedAt = watch.CreatedAt.UTC()
+		watch.NextAt = watch.NextAt.UTC()
+		if lastScreenedAt.Valid {
+			watch.LastScreenedAt = lastScreenedAt.Time.UTC()
+		}
+
+		watches = append(watches, &watch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve watches")
+	}
+
+	return watches, nil
+}
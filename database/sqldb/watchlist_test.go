@@ -0,0 +1,14 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+)
+
+func TestWatchlist(t *testing.T) {
+	storetest.TestWatchlist(t, func(t *testing.T) walletscreener.WatchlistStore {
+		return newTestStore(t)
+	})
+}
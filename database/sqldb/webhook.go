@@ -0,0 +1,307 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// PutSubscription implements walletscreener.WebhookStore.
+func (s *Store) PutSubscription(ctx context.Context, subscription *walletscreener.Subscription) error {
+	events := subscription.Events
+	if events == nil {
+		events = []walletscreener.WebhookEvent{}
+	}
+
+	value, err := json.Marshal(events)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode subscription events")
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (id, url, secret, events, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			url = excluded.url,
+			secret = excluded.secret,
+			events = excluded.events,
+			created_at = excluded.created_at`,
+		subscription.ID, subscription.URL, subscription.Secret, string(value), subscription.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store subscription")
+	}
+
+	return nil
+}
+
+// GetSubscription implements walletscreener.WebhookStore.
+func (s *Store) GetSubscription(ctx context.Context, id string) (*walletscreener.Subscription, error) {
+	subscriptions, err := s.querySubscriptions(ctx, `
+		SELECT id, url, secret, events, created_at
+		FROM webhook_subscriptions
+		WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subscriptions) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+
+	return subscriptions[0], nil
+}
+
+// DeleteSubscription implements walletscreener.WebhookStore.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete subscription")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to delete subscription")
+	}
+
+	if deleted < 1 {
+		return errors.Wrapf(walletscreener.ErrSubscriptionNotFound, "id %s", id)
+	}
+
+	return nil
+}
+
+// ListSubscriptions implements walletscreener.WebhookStore.
+func (s *Store) ListSubscriptions(ctx context.Context) ([]*walletscreener.Subscription, error) {
+	return s.querySubscriptions(ctx, `
+		SELECT id, url, secret, events, created_at
+		FROM webhook_subscriptions
+		ORDER BY created_at, id`,
+	)
+}
+
+// querySubscriptions returns subscriptions selected by query.
+func (s *Store) querySubscriptions(ctx context.Context, query string, args ...any) ([]*walletscreener.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query subscriptions")
+	}
+	defer rows.Close()
+
+	subscriptions := []*walletscreener.Subscription{}
+	for rows.Next() {
+		var (
+			subscription walletscreener.Subscription
+			events       string
+		)
+
+		err := rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &events, &subscription.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan subscription")
+		}
+
+		if err := json.Unmarshal([]byte(events), &subscription.Events); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode events of subscription %s", subscription.ID)
+		}
+
+		// subscriptions filtering no events were stored without them
+		if len(subscription.Events) < 1 {
+			subscription.Events = nil
+		}
+
+		subscription.CreatedAt = subscription.CreatedAt.UTC()
+
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+// deliveryAttemptRecord represents an attempt of a delivery stored as JSON.
+type deliveryAttemptRecord struct {
+	At         time.Time     `json:"at"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// PutDelivery implements walletscreener.WebhookStore.
+func (s *Store) PutDelivery(ctx context.Context, delivery *walletscreener.Delivery) error {
+	records := make([]deliveryAttemptRecord, 0, len(delivery.Log))
+	for _, v := range delivery.Log {
+		records = append(records, deliveryAttemptRecord(v))
+	}
+
+	log, err := json.Marshal(records)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode delivery log")
+	}
+
+	var deliveredAt sql.NullTime
+	if !delivery.DeliveredAt.IsZero() {
+		deliveredAt = sql.NullTime{Time: delivery.DeliveredAt.UTC(), Valid: true}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, event, screening_id, payload, status, attempts, log, next_attempt_at, created_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			log = excluded.log,
+			next_attempt_at = excluded.next_attempt_at,
+			delivered_at = excluded.delivered_at`,
+		delivery.ID, delivery.SubscriptionID, string(delivery.Event), delivery.ScreeningID, string(delivery.Payload),
+		string(delivery.Status), delivery.Attempts, string(log), delivery.NextAttemptAt.UTC(), delivery.CreatedAt.UTC(), deliveredAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store delivery")
+	}
+
+	return nil
+}
+
+// selectDeliveries selects columns of deliveries scanned by queryDeliveries.
+const selectDeliveries = `
+		SELECT id, subscription_id, event, screening_id, payload, status, attempts, log, next_attempt_at, created_at, delivered_at
+		FROM webhook_deliveries`
+
+// GetDelivery implements walletscreener.WebhookStore.
+func (s *Store) GetDelivery(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+	deliveries, err := s.queryDeliveries(ctx, selectDeliveries+`
+		WHERE subscription_id = $1 AND id = $2`,
+		subscriptionID, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) < 1 {
+		return nil, errors.Wrapf(walletscreener.ErrDeliveryNotFound, "id %s of subscription %s", id, subscriptionID)
+	}
+
+	return deliveries[0], nil
+}
+
+// ListDeliveries implements walletscreener.WebhookStore.
+func (s *Store) ListDeliveries(ctx context.Context, query *walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	// arg adds query argument returning its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if query.SubscriptionID != "" {
+		conditions = append(conditions, "subscription_id = "+arg(query.SubscriptionID))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = "+arg(string(query.Status)))
+	}
+
+	order := "ASC"
+	if query.Descending {
+		order = "DESC"
+	}
+
+	limit := int64(math.MaxInt64) // portable way of no limit across SQLite and PostgreSQL
+	if query.Limit > 0 {
+		limit = int64(query.Limit)
+	}
+
+	statement := selectDeliveries
+	if len(conditions) > 0 {
+		statement += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	statement += `
+		ORDER BY created_at ` + order + `, id ` + order + `
+		LIMIT ` + arg(limit)
+
+	return s.queryDeliveries(ctx, statement, args...)
+}
+
+// ListDueDeliveries implements walletscreener.WebhookStore.
+// Pending deliveries are looked up by index of their status and next attempt.
+func (s *Store) ListDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]*walletscreener.Delivery, error) {
+	if limit < 1 {
+		limit = math.MaxInt32
+	}
+
+	return s.queryDeliveries(ctx, selectDeliveries+`
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT $3`,
+		string(walletscreener.DeliveryStatusPending), dueBy.UTC(), int64(limit),
+	)
+}
+
+// queryDeliveries returns deliveries selected by query built on selectDeliveries.
+func (s *Store) queryDeliveries(ctx context.Context, query string, args ...any) ([]*walletscreener.Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query deliveries")
+	}
+	defer rows.Close()
+
+	deliveries := []*walletscreener.Delivery{}
+	for rows.Next() {
+		var (
+			delivery    walletscreener.Delivery
+			payload     string
+			log         string
+			deliveredAt sql.NullTime
+		)
+
+		err := rows.Scan(
+			&delivery.ID, &delivery.SubscriptionID, &delivery.Event, &delivery.ScreeningID, &payload, &delivery.Status,
+			&delivery.Attempts, &log, &delivery.NextAttemptAt, &delivery.CreatedAt, &deliveredAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan delivery")
+		}
+
+		var records []deliveryAttemptRecord
+		if err := json.Unmarshal([]byte(log), &records); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode log of delivery %s", delivery.ID)
+		}
+
+		for _, v := range records {
+			v.At = v.At.UTC()
+			delivery.Log = append(delivery.Log, walletscreener.DeliveryAttempt(v))
+		}
+
+		delivery.Payload = []byte(payload)
+		delivery.NextAttemptAt = delivery.NextAttemptAt.UTC()
+		delivery.CreatedAt = delivery.CreatedAt.UTC()
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = deliveredAt.Time.UTC()
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve deliveries")
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,20 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/storetest"
+)
+
+func TestWebhooks(t *testing.T) {
+	storetest.TestWebhooks(t, func(t *testing.T) walletscreener.WebhookStore {
+		return newTestStore(t)
+	})
+}
+
+func TestDueDeliveries(t *testing.T) {
+	storetest.TestDueDeliveries(t, func(t *testing.T) walletscreener.WebhookStore {
+		return newTestStore(t)
+	})
+}
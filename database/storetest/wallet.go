@@ -0,0 +1,81 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// TestScreeningsCursor tests that walletscreener.ScreeningStore constructed by newStore accepts only cursors it returns,
+// cursors are revisions of wallet screenings in every store.
+func TestScreeningsCursor(t *testing.T, newStore func(t *testing.T) walletscreener.ScreeningStore) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	const address = "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"
+	screened := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	for i, id := range []string{"a", "b", "c"} {
+		screening := &walletscreener.Screening{
+			ID:              id,
+			ScreenedAt:      screened.Add(time.Duration(i) * time.Hour),
+			ScreeningResult: walletscreener.ScreeningResult{Chain: walletscreener.ChainEthereum, Address: address},
+		}
+		if err := store.StoreScreening(ctx, screening); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var testcases = []struct {
+		address    string
+		cursor     string
+		descending bool
+		ids        []string
+		err        error
+	}{
+		{address: address, cursor: "1", ids: []string{"b", "c"}},
+		{address: address, cursor: "3", descending: true, ids: []string{"b", "a"}},
+		{address: address, cursor: "3", ids: []string{}},
+		{address: address, cursor: "1", descending: true, ids: []string{}},
+		{address: address, cursor: "0", err: walletscreener.ErrInvalidCursor},
+		{address: address, cursor: "0", descending: true, err: walletscreener.ErrInvalidCursor},
+		{address: address, cursor: "-1", err: walletscreener.ErrInvalidCursor},
+		{address: address, cursor: "next", err: walletscreener.ErrInvalidCursor},
+		{address: address, cursor: "4", err: walletscreener.ErrInvalidCursor},
+		{address: address, cursor: "4", descending: true, err: walletscreener.ErrInvalidCursor},
+		{address: "0x71C7656EC7ab88b098defB751B7401B5f6d8976F", cursor: "1", err: walletscreener.ErrInvalidCursor},
+	}
+
+	for i, tt := range testcases {
+		page, err := store.GetWalletScreenings(ctx, &walletscreener.ScreeningsQuery{
+			Chain:      walletscreener.ChainEthereum,
+			Address:    tt.address,
+			Cursor:     tt.cursor,
+			Descending: tt.descending,
+		})
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		ids := []string{}
+		for _, v := range page.Screenings {
+			ids = append(ids, v.ID)
+		}
+
+		if len(ids) != len(tt.ids) {
+			t.Fatalf("#%d got %v, want %v", i, ids, tt.ids)
+		}
+		for j := range ids {
+			if ids[j] != tt.ids[j] {
+				t.Errorf("#%d got %v, want %v", i, ids, tt.ids)
+				break
+			}
+		}
+	}
+}
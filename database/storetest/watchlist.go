@@ -0,0 +1,68 @@
+// Package storetest provides tests shared by implementations of walletscreener storage interfaces.
+// Each test is run against a store constructed by the given constructor, every store implementation is expected to pass it.
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWatchlist tests walletscreener.WatchlistStore constructed by newStore.
+func TestWatchlist(t *testing.T, newStore func(t *testing.T) walletscreener.WatchlistStore) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	created := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	watches := []*walletscreener.Watch{
+		{Chain: walletscreener.ChainTron, Address: "a", Interval: time.Hour, CreatedAt: created, NextAt: created.Add(time.Hour)},
+		{Chain: walletscreener.ChainEthereum, Address: "b", Interval: 24 * time.Hour, CreatedAt: created, NextAt: created.Add(24 * time.Hour)},
+		{Chain: walletscreener.ChainEthereum, Address: "a", Interval: time.Hour, CreatedAt: created, NextAt: created.Add(time.Hour)},
+	}
+	for _, v := range watches {
+		if err := store.PutWatch(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// replaced watch is kept once
+	updated := *watches[2]
+	updated.LastScreenedAt, updated.LastError = created.Add(time.Hour), "provider unavailable"
+	if err := store.PutWatch(ctx, &updated); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	watch, err := store.GetWatch(ctx, walletscreener.ChainEthereum, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if diff := cmp.Diff(&updated, watch); diff != "" {
+		t.Errorf("GetWatch() mismatch (-want +got):\n%s", diff)
+	}
+
+	list, err := store.ListWatches(ctx)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if diff := cmp.Diff([]*walletscreener.Watch{&updated, watches[1], watches[0]}, list); diff != "" {
+		t.Errorf("ListWatches() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := store.DeleteWatch(ctx, walletscreener.ChainEthereum, "a"); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if _, err := store.GetWatch(ctx, walletscreener.ChainEthereum, "a"); !errors.Is(err, walletscreener.ErrWatchNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrWatchNotFound)
+	}
+
+	if err := store.DeleteWatch(ctx, walletscreener.ChainEthereum, "a"); !errors.Is(err, walletscreener.ErrWatchNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrWatchNotFound)
+	}
+}
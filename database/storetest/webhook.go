@@ -0,0 +1,172 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWebhooks tests walletscreener.WebhookStore constructed by newStore.
+func TestWebhooks(t *testing.T, newStore func(t *testing.T) walletscreener.WebhookStore) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	created := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	subscriptions := []*walletscreener.Subscription{
+		{ID: "b", URL: "https://example.com/b", Secret: "secret", CreatedAt: created},
+		{ID: "a", URL: "https://example.com/a", Secret: "secret", Events: []walletscreener.WebhookEvent{walletscreener.WebhookEventBlocked}, CreatedAt: created.Add(time.Second)},
+	}
+	for _, v := range subscriptions {
+		if err := store.PutSubscription(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	subscription, err := store.GetSubscription(ctx, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if diff := cmp.Diff(subscriptions[1], subscription); diff != "" {
+		t.Errorf("GetSubscription() mismatch (-want +got):\n%s", diff)
+	}
+
+	list, err := store.ListSubscriptions(ctx)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if diff := cmp.Diff(subscriptions, list); diff != "" {
+		t.Errorf("ListSubscriptions() mismatch (-want +got):\n%s", diff)
+	}
+
+	deliveries := []*walletscreener.Delivery{
+		{ID: "1", SubscriptionID: "a", Event: walletscreener.WebhookEventBlocked, ScreeningID: "s1", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: created, CreatedAt: created},
+		{ID: "2", SubscriptionID: "a", Event: walletscreener.WebhookEventBlocked, ScreeningID: "s2", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: created, CreatedAt: created.Add(time.Minute)},
+		{ID: "3", SubscriptionID: "b", Event: walletscreener.WebhookEventCategoriesChanged, ScreeningID: "s2", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: created, CreatedAt: created.Add(time.Minute)},
+	}
+	for _, v := range deliveries {
+		if err := store.PutDelivery(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// replaced delivery is kept once
+	dead := *deliveries[0]
+	dead.Status, dead.Attempts = walletscreener.DeliveryStatusDead, 1
+	dead.Log = []walletscreener.DeliveryAttempt{{At: created, StatusCode: 500, Error: "request resulted in 500 response code", Duration: time.Second}}
+	if err := store.PutDelivery(ctx, &dead); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	delivery, err := store.GetDelivery(ctx, "a", "1")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if diff := cmp.Diff(&dead, delivery); diff != "" {
+		t.Errorf("GetDelivery() mismatch (-want +got):\n%s", diff)
+	}
+
+	// delivery is found only along its subscription
+	if _, err := store.GetDelivery(ctx, "b", "1"); !errors.Is(err, walletscreener.ErrDeliveryNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrDeliveryNotFound)
+	}
+
+	testcases := []struct {
+		query walletscreener.DeliveriesQuery
+		want  []*walletscreener.Delivery
+	}{
+		{walletscreener.DeliveriesQuery{}, []*walletscreener.Delivery{&dead, deliveries[1], deliveries[2]}},
+		{walletscreener.DeliveriesQuery{SubscriptionID: "a", Descending: true}, []*walletscreener.Delivery{deliveries[1], &dead}},
+		{walletscreener.DeliveriesQuery{Status: walletscreener.DeliveryStatusPending}, []*walletscreener.Delivery{deliveries[1], deliveries[2]}},
+		{walletscreener.DeliveriesQuery{Status: walletscreener.DeliveryStatusDead, SubscriptionID: "b"}, []*walletscreener.Delivery{}},
+		{walletscreener.DeliveriesQuery{Limit: 1, Descending: true}, []*walletscreener.Delivery{deliveries[2]}},
+	}
+
+	for _, tc := range testcases {
+		got, err := store.ListDeliveries(ctx, &tc.query)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ListDeliveries(%+v) mismatch (-want +got):\n%s", tc.query, diff)
+		}
+	}
+
+	if err := store.DeleteSubscription(ctx, "a"); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if _, err := store.GetSubscription(ctx, "a"); !errors.Is(err, walletscreener.ErrSubscriptionNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrSubscriptionNotFound)
+	}
+
+	if err := store.DeleteSubscription(ctx, "a"); !errors.Is(err, walletscreener.ErrSubscriptionNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrSubscriptionNotFound)
+	}
+
+	// deliveries of deleted subscription are kept
+	if _, err := store.GetDelivery(ctx, "a", "1"); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+}
+
+// TestDueDeliveries tests that walletscreener.WebhookStore constructed by newStore lists only pending deliveries due.
+func TestDueDeliveries(t *testing.T, newStore func(t *testing.T) walletscreener.WebhookStore) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	due := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	deliveries := []*walletscreener.Delivery{
+		{ID: "1", SubscriptionID: "a", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: due.Add(-time.Second), CreatedAt: due},
+		{ID: "2", SubscriptionID: "a", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: due.Add(-time.Minute), CreatedAt: due},
+		{ID: "3", SubscriptionID: "b", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: due, CreatedAt: due},
+		{ID: "4", SubscriptionID: "b", Payload: []byte(`{}`), Status: walletscreener.DeliveryStatusPending, NextAttemptAt: due.Add(time.Nanosecond), CreatedAt: due},
+	}
+	for _, v := range deliveries {
+		if err := store.PutDelivery(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// retried delivery is due by its next attempt only
+	retried := *deliveries[1]
+	retried.Attempts, retried.NextAttemptAt = 1, due.Add(time.Hour)
+	if err := store.PutDelivery(ctx, &retried); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// delivered delivery is not due anymore
+	delivered := *deliveries[2]
+	delivered.Status, delivered.Attempts, delivered.DeliveredAt = walletscreener.DeliveryStatusDelivered, 1, due
+	if err := store.PutDelivery(ctx, &delivered); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	testcases := []struct {
+		dueBy time.Time
+		limit int
+		want  []*walletscreener.Delivery
+	}{
+		{due, 0, []*walletscreener.Delivery{deliveries[0]}},
+		{due.Add(time.Nanosecond), 0, []*walletscreener.Delivery{deliveries[0], deliveries[3]}},
+		{due.Add(time.Hour), 0, []*walletscreener.Delivery{deliveries[0], deliveries[3], &retried}},
+		{due.Add(time.Hour), 2, []*walletscreener.Delivery{deliveries[0], deliveries[3]}},
+		{due.Add(-time.Hour), 0, []*walletscreener.Delivery{}},
+	}
+
+	for _, tc := range testcases {
+		got, err := store.ListDueDeliveries(ctx, tc.dueBy, tc.limit)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ListDueDeliveries(%v, %d) mismatch (-want +got):\n%s", tc.dueBy, tc.limit, diff)
+		}
+	}
+}
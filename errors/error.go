@@ -47,3 +47,20 @@ func Equals(err1, err2 error) bool {
 func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
+
+// WithReason wraps err with reason and returns resulting error.
+// Resulting error matches both err and reason when checked with Is.
+func WithReason(err, reason error) error {
+	return fmt.Errorf("%w: %w", err, reason)
+}
+
+// Join returns an error that wraps the given errors.
+// Any nil error values are discarded, Join returns nil if every value in errs is nil.
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
+
+// As finds the first error in err's chain that matches target, and if so, sets target to that error value and returns true.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
@@ -9,12 +9,9 @@ import (
 	"time"
 
 	"github.com/deividaspetraitis/wallet-screener"
-	db "github.com/deividaspetraitis/wallet-screener/database/immudb"
-
 	"github.com/deividaspetraitis/wallet-screener/http/middleware"
 	"github.com/deividaspetraitis/wallet-screener/log"
 
-	immudb "github.com/codenotary/immudb/pkg/client"
 	"github.com/gorilla/mux"
 )
 
@@ -47,7 +44,8 @@ func (a *App) SignalShutdown() {
 }
 
 // API constructs an http.Handler with all application routes defined.
-func API(shutdown chan os.Signal, cfg *Config, logger log.Logger, riskprovider walletscreener.WalletRiskScreeningProvider, immuclient immudb.ImmuClient) stdhttp.Handler {
+// Wallets are screened by screen shared with background screenings, store is used to read stored screenings.
+func API(shutdown chan os.Signal, cfg *Config, logger log.Logger, screen walletscreener.ScreenWalletFunc, store walletscreener.ScreeningStore, batch *walletscreener.BatchConfig, queue walletscreener.JobQueue, watchlist walletscreener.WatchlistStore, jitter walletscreener.JitterFunc, webhooks walletscreener.WebhookStore, providerMetrics func() map[string]ClientMetrics) stdhttp.Handler {
 	// =========================================================================
 	// Construct the web app api which holds all routes as well as common Middleware.
 
@@ -56,17 +54,111 @@ func API(shutdown chan os.Signal, cfg *Config, logger log.Logger, riskprovider w
 	// =========================================================================
 	// Construct and attach relevant handlers to web app api
 
-	api.API.HandleFunc("/wallet/{address}/categories", GetRiskCategories(func(ctx context.Context, address string) ([]string, error) {
-		return walletscreener.ScreenWalletRiskCategories(ctx, riskprovider, func(ctx context.Context, address string, categories []string) error {
-			return db.StoreWalletRiskCategories(ctx, immuclient, address, categories)
-		}, address)
-	})).Methods(http.MethodPost)
-
-	api.API.HandleFunc("/wallet/{address}/categories", GetRiskCategoriesHistory(func(ctx context.Context, address string) ([]*walletscreener.HistoricalRiskCategory, error) {
-		return walletscreener.GetWalletRiskCategoriesHistory(ctx, func(ctx context.Context, address string) ([]string, []uint64, error) {
-			return db.GetWalletRiskCategories(ctx, immuclient, address)
-		}, address)
-	})).Methods(http.MethodGet)
+	screenRiskCategories := GetRiskCategories(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		return screen(ctx, chain, address)
+	})
+
+	// wallets of a batch are screened and stored one by one same as screened alone
+	screenWallets := ScreenWallets(func(ctx context.Context, wallets []walletscreener.Wallet) ([]*walletscreener.WalletScreening, error) {
+		return walletscreener.ScreenWallets(ctx, screen, batch, wallets)
+	}, batch.WalletsLimit())
+
+	submitJob := SubmitJob(func(ctx context.Context, job *walletscreener.Job) error {
+		return walletscreener.SubmitJob(ctx, queue, batch, job)
+	}, batch.WalletsLimit())
+
+	getJob := GetJob(func(ctx context.Context, id string) (*walletscreener.Job, error) {
+		return walletscreener.GetJob(ctx, queue.GetJob, id)
+	})
+
+	watchWallet := WatchWallet(func(ctx context.Context, chain walletscreener.Chain, address string, interval time.Duration) (*walletscreener.Watch, error) {
+		return walletscreener.WatchWallet(ctx, watchlist.GetWatch, watchlist.PutWatch, jitter, chain, address, interval, time.Now().UTC())
+	})
+
+	getWatch := GetWatch(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+		return walletscreener.GetWatch(ctx, watchlist.GetWatch, chain, address)
+	})
+
+	unwatchWallet := UnwatchWallet(func(ctx context.Context, chain walletscreener.Chain, address string) error {
+		return walletscreener.UnwatchWallet(ctx, watchlist.DeleteWatch, chain, address)
+	})
+
+	listWatches := ListWatches(func(ctx context.Context) ([]*walletscreener.Watch, error) {
+		return walletscreener.ListWatches(ctx, watchlist.ListWatches)
+	})
+
+	createSubscription := CreateSubscription(func(ctx context.Context, url, secret string, events []walletscreener.WebhookEvent) (*walletscreener.Subscription, error) {
+		return walletscreener.CreateSubscription(ctx, webhooks.PutSubscription, url, secret, events)
+	})
+
+	getSubscription := GetSubscription(func(ctx context.Context, id string) (*walletscreener.Subscription, error) {
+		return walletscreener.GetSubscription(ctx, webhooks.GetSubscription, id)
+	})
+
+	deleteSubscription := DeleteSubscription(func(ctx context.Context, id string) error {
+		return walletscreener.DeleteSubscription(ctx, webhooks.DeleteSubscription, id)
+	})
+
+	listSubscriptions := ListSubscriptions(func(ctx context.Context) ([]*walletscreener.Subscription, error) {
+		return walletscreener.ListSubscriptions(ctx, webhooks.ListSubscriptions)
+	})
+
+	listDeliveries := ListDeliveries(func(ctx context.Context, query walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+		return walletscreener.ListDeliveries(ctx, webhooks.GetSubscription, webhooks.ListDeliveries, query)
+	})
+
+	redeliver := Redeliver(func(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+		return walletscreener.RedeliverDelivery(ctx, webhooks.GetDelivery, webhooks.PutDelivery, subscriptionID, id, time.Now().UTC())
+	})
+
+	riskCategoriesHistory := GetRiskCategoriesHistory(func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+		return walletscreener.GetWalletScreeningsHistory(ctx, store.GetWalletScreenings, query)
+	})
+
+	latestScreening := GetLatestScreening(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		return walletscreener.GetLatestScreening(ctx, store.GetLatestScreening, chain, address)
+	})
+
+	// proofs are exported by stores able to prove stored screenings only
+	getScreeningProof := func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+		return nil, walletscreener.ErrProofNotSupported
+	}
+	if prover, ok := store.(walletscreener.ScreeningProver); ok {
+		getScreeningProof = prover.GetScreeningProof
+	}
+
+	screeningProof := GetScreeningProof(func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+		return walletscreener.GetScreeningProof(ctx, getScreeningProof, chain, address, id)
+	})
+
+	api.API.HandleFunc("/metrics/providers", GetProviderMetrics(providerMetrics)).Methods(http.MethodGet)
+
+	api.API.HandleFunc("/wallet/{chain}/{address}/categories", screenRiskCategories).Methods(http.MethodPost)
+	api.API.HandleFunc("/wallet/{chain}/{address}/categories", riskCategoriesHistory).Methods(http.MethodGet)
+	api.API.HandleFunc("/wallet/{chain}/{address}/screenings/latest", latestScreening).Methods(http.MethodGet)
+	api.API.HandleFunc("/wallet/{chain}/{address}/screenings/{id}/proof", screeningProof).Methods(http.MethodGet)
+
+	api.API.HandleFunc("/wallets/screenings", screenWallets).Methods(http.MethodPost)
+	api.API.HandleFunc("/jobs", submitJob).Methods(http.MethodPost)
+	api.API.HandleFunc("/jobs/{id}", getJob).Methods(http.MethodGet)
+
+	api.API.HandleFunc("/watchlist", listWatches).Methods(http.MethodGet)
+	api.API.HandleFunc("/watchlist/{chain}/{address}", watchWallet).Methods(http.MethodPut)
+	api.API.HandleFunc("/watchlist/{chain}/{address}", getWatch).Methods(http.MethodGet)
+	api.API.HandleFunc("/watchlist/{chain}/{address}", unwatchWallet).Methods(http.MethodDelete)
+
+	api.API.HandleFunc("/webhooks", createSubscription).Methods(http.MethodPost)
+	api.API.HandleFunc("/webhooks", listSubscriptions).Methods(http.MethodGet)
+	api.API.HandleFunc("/webhooks/{id}", getSubscription).Methods(http.MethodGet)
+	api.API.HandleFunc("/webhooks/{id}", deleteSubscription).Methods(http.MethodDelete)
+	api.API.HandleFunc("/webhooks/{id}/deliveries", listDeliveries).Methods(http.MethodGet)
+	api.API.HandleFunc("/webhooks/{id}/deliveries/{delivery}/redeliver", redeliver).Methods(http.MethodPost)
+
+	// Routes without chain segment are kept for backward compatibility, these default to Ethereum.
+	api.API.HandleFunc("/wallet/{address}/categories", screenRiskCategories).Methods(http.MethodPost)
+	api.API.HandleFunc("/wallet/{address}/categories", riskCategoriesHistory).Methods(http.MethodGet)
+	api.API.HandleFunc("/wallet/{address}/screenings/latest", latestScreening).Methods(http.MethodGet)
+	api.API.HandleFunc("/wallet/{address}/screenings/{id}/proof", screeningProof).Methods(http.MethodGet)
 
 	// guard with request rate limiter
 	api.API.Use(func(handler http.Handler) http.Handler {
@@ -66,6 +66,8 @@ type Client struct {
 	debug          bool
 	http           *http.Client
 	requestOptions []RequestOption
+	retry          RetryPolicy
+	metrics        clientMetrics
 }
 
 // NewClient constructs and returns new HTTP client instance.
@@ -99,6 +101,16 @@ func (c *Client) RequestOptions() []RequestOption {
 	return c.requestOptions
 }
 
+// SetRetryPolicy sets policy of retrying failed idempotent requests issued by the client.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// Metrics returns snapshot of metrics of requests issued by the client.
+func (c *Client) Metrics() ClientMetrics {
+	return c.metrics.snapshot()
+}
+
 // URI returns the absolute URL of the API with any path segments
 // appended to the end.
 func (c *Client) URI(path ...string) string {
@@ -136,17 +148,45 @@ func (c *Client) request(ctx context.Context, method, uri string, payload []byte
 
 // Request combines request and do, while also handling decoding of response
 // payload.
+// Failed idempotent requests are retried according to client RetryPolicy.
+// Unsuccessful HTTP responses result in *ResponseError.
 func (c *Client) Request(ctx context.Context, method, uri string, v []byte, options ...RequestOption) (*http.Response, error) {
 	uri = c.URI(uri)
 
-	req, err := c.request(ctx, method, uri, v, options...)
-	if err != nil {
-		return nil, errors.Wrapf(err, "building request")
+	c.metrics.requests.Add(1)
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.request(ctx, method, uri, v, options...)
+		if err != nil {
+			c.metrics.failures.Add(1)
+			return nil, errors.Wrapf(err, "building request")
+		}
+
+		res, err := c.attempt(req)
+
+		delay, ok := c.retryDelay(ctx, method, attempt, err)
+		if !ok || !wait(ctx, delay) {
+			if err != nil {
+				c.metrics.failures.Add(1)
+			}
+			return res, err
+		}
+
+		if c.debug {
+			log.Printf("retrying request to %s in %s after attempt %d failed: %v", uri, delay, attempt, err)
+		}
+
+		c.metrics.retries.Add(1)
 	}
+}
+
+// attempt makes a single attempt of the request.
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	c.metrics.attempts.Add(1)
 
 	res, err := c.do(req)
 	if err != nil {
-		return nil, errors.Wrapf(err, "sending request to %s", uri)
+		return nil, errors.Wrapf(err, "sending request to %s", req.URL.String())
 	}
 
 	if c.debug {
@@ -154,8 +194,7 @@ func (c *Client) Request(ctx context.Context, method, uri string, v []byte, opti
 	}
 
 	if res.StatusCode != http.StatusOK {
-		res.Body.Close()
-		return res, errors.Newf("request resulted in %d response code", res.StatusCode)
+		return nil, NewResponseError(res)
 	}
 
 	return res, nil
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer returns server responding with given status codes in order, the last one is repeated.
+func newTestServer(t *testing.T, header http.Header, statusCodes ...int) (*httptest.Server, *int) {
+	t.Helper()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statusCode := statusCodes[len(statusCodes)-1]
+		if requests < len(statusCodes) {
+			statusCode = statusCodes[requests]
+		}
+		requests++
+
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClientRequestRetry(t *testing.T) {
+	var testcases = []struct {
+		method      string
+		header      http.Header
+		statusCodes []int
+		policy      RetryPolicy
+		timeout     time.Duration
+
+		requests int
+		metrics  ClientMetrics
+		err      bool
+	}{
+		// retries disabled
+		{
+			method:      http.MethodGet,
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusOK},
+			requests:    1,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 1, Failures: 1},
+			err:         true,
+		},
+		// transient failures are retried
+		{
+			method:      http.MethodGet,
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			requests:    3,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 3, Retries: 2},
+		},
+		// attempts are exhausted
+		{
+			method:      http.MethodGet,
+			statusCodes: []int{http.StatusServiceUnavailable},
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			requests:    3,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 3, Retries: 2, Failures: 1},
+			err:         true,
+		},
+		// non idempotent requests are not retried
+		{
+			method:      http.MethodPost,
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			requests:    1,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 1, Failures: 1},
+			err:         true,
+		},
+		// permanent failures are not retried
+		{
+			method:      http.MethodGet,
+			statusCodes: []int{http.StatusNotFound, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			requests:    1,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 1, Failures: 1},
+			err:         true,
+		},
+		// Retry-After is honored
+		{
+			method:      http.MethodGet,
+			header:      http.Header{"Retry-After": []string{"0"}},
+			statusCodes: []int{http.StatusTooManyRequests, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour},
+			requests:    2,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 2, Retries: 1},
+		},
+		// retry is not attempted if Retry-After exceeds maximum delay of the policy
+		{
+			method:      http.MethodGet,
+			header:      http.Header{"Retry-After": []string{"3600"}},
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Minute},
+			requests:    1,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 1, Failures: 1},
+			err:         true,
+		},
+		// retry is not attempted if Retry-After exceeds context deadline
+		{
+			method:      http.MethodGet,
+			header:      http.Header{"Retry-After": []string{"60"}},
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusOK},
+			policy:      RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			timeout:     time.Second,
+			requests:    1,
+			metrics:     ClientMetrics{Requests: 1, Attempts: 1, Failures: 1},
+			err:         true,
+		},
+	}
+
+	for i, tt := range testcases {
+		server, requests := newTestServer(t, tt.header, tt.statusCodes...)
+
+		client, err := NewClient(server.URL)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+		client.SetRetryPolicy(tt.policy)
+
+		ctx := context.Background()
+		if tt.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+			defer cancel()
+		}
+
+		res, err := client.Request(ctx, tt.method, "test", nil)
+		if (err != nil) != tt.err {
+			t.Errorf("#%d got %v, want error %v", i, err, tt.err)
+		}
+
+		if err == nil {
+			res.Body.Close()
+		}
+
+		if *requests != tt.requests {
+			t.Errorf("#%d requests got %v, want %v", i, *requests, tt.requests)
+		}
+
+		if metrics := client.Metrics(); metrics != tt.metrics {
+			t.Errorf("#%d metrics got %+v, want %+v", i, metrics, tt.metrics)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt, max := range []time.Duration{10, 20, 40, 50, 50} {
+		max *= time.Millisecond
+		for i := 0; i < 100; i++ {
+			if delay := policy.Backoff(attempt + 1); delay < 0 || delay > max {
+				t.Fatalf("attempt %d delay got %v, want between 0 and %v", attempt+1, delay, max)
+			}
+		}
+	}
+}
+
+func TestClientRequestResponseError(t *testing.T) {
+	var testcases = []struct {
+		statusCode int
+		header     http.Header
+		body       string
+
+		errBody    string
+		retryAfter time.Duration
+		ok         bool
+	}{
+		// body is kept
+		{
+			statusCode: http.StatusNotFound,
+			body:       `{"error":"not found"}`,
+			errBody:    `{"error":"not found"}`,
+		},
+		// body is truncated
+		{
+			statusCode: http.StatusBadRequest,
+			body:       strings.Repeat("a", maxResponseErrorBodySize+1),
+			errBody:    strings.Repeat("a", maxResponseErrorBodySize),
+		},
+		// retry after in seconds
+		{
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{"3"}},
+			retryAfter: 3 * time.Second,
+			ok:         true,
+		},
+		// retry after is ignored for other status codes
+		{
+			statusCode: http.StatusUnauthorized,
+			header:     http.Header{"Retry-After": []string{"3"}},
+		},
+		// APIs respond with 200 only, other successful responses are unexpected
+		{
+			statusCode: http.StatusNoContent,
+		},
+	}
+
+	for i, tt := range testcases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range tt.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(tt.statusCode)
+			w.Write([]byte(tt.body))
+		}))
+
+		client, err := NewClient(server.URL)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		_, err = client.Request(context.Background(), http.MethodGet, "", nil)
+		server.Close()
+
+		var responseErr *ResponseError
+		if !errors.As(err, &responseErr) {
+			t.Fatalf("#%d got %v, want *ResponseError", i, err)
+		}
+
+		if responseErr.StatusCode != tt.statusCode {
+			t.Errorf("#%d status code got %v, want %v", i, responseErr.StatusCode, tt.statusCode)
+		}
+
+		if string(responseErr.Body) != tt.errBody {
+			t.Errorf("#%d body got %d bytes, want %d bytes", i, len(responseErr.Body), len(tt.errBody))
+		}
+
+		if retryAfter, ok := responseErr.RetryAfter(); retryAfter != tt.retryAfter || ok != tt.ok {
+			t.Errorf("#%d retry after got %v %v, want %v %v", i, retryAfter, ok, tt.retryAfter, tt.ok)
+		}
+	}
+}
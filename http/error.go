@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxResponseErrorBodySize is the maximum number of response body bytes kept in ResponseError.
+const maxResponseErrorBodySize = 4 << 10
+
+// ResponseError is returned when request results in unsuccessful HTTP response.
+type ResponseError struct {
+	StatusCode int         // HTTP response status code
+	Header     http.Header // HTTP response headers
+	Body       []byte      // Response body, truncated to maxResponseErrorBodySize bytes
+}
+
+// NewResponseError constructs a new ResponseError from res, response body is consumed and closed.
+func NewResponseError(res *http.Response) *ResponseError {
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseErrorBodySize))
+
+	// drain the rest of the body allowing connection to be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseErrorBodySize))
+
+	return &ResponseError{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+}
+
+// Error implements error.
+func (e *ResponseError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("request resulted in %d response code", e.StatusCode)
+	}
+	return fmt.Sprintf("request resulted in %d response code: %s", e.StatusCode, e.Body)
+}
+
+// RetryAfter parses Retry-After header of 429 and 503 responses.
+// Header value is either delay in seconds or HTTP date.
+func (e *ResponseError) RetryAfter() (time.Duration, bool) {
+	if e.StatusCode != http.StatusTooManyRequests && e.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := e.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
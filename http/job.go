@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+// submitJobFunc decouples actual job queue implementation and allows easily test HTTP handler.
+type submitJobFunc func(ctx context.Context, job *walletscreener.Job) error
+
+// SubmitJob responds with a job screening given wallets in background.
+// Request body is limited to the size of maxWallets wallets.
+func SubmitJob(submitJob submitJobFunc, maxWallets int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		request := api.SubmitJobRequest{ScreenWalletsRequest: api.ScreenWalletsRequest{MaxWallets: maxWallets}}
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "SubmitJob",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		job := request.Job()
+		if err := submitJob(r.Context(), job); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "SubmitJob",
+			}).Println("encountered an error submitting job")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewJobResponse(job, statusCode)
+
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		w.WriteHeader(http.StatusAccepted)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "SubmitJob",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// getJobFunc decouples actual job queue implementation and allows easily test HTTP handler.
+type getJobFunc func(ctx context.Context, id string) (*walletscreener.Job, error)
+
+// GetJob responds with progress of the job and its results once it is finished.
+func GetJob(getJob getJobFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.GetJobRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "GetJob",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		job, err := getJob(r.Context(), request.ID)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "GetJob",
+			}).Println("encountered an error retrieving job")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewJobResponse(job, statusCode)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "job",
+				"method":  "GetJob",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSubmitJob(t *testing.T) {
+	var testcases = []struct {
+		body      string
+		submitJob submitJobFunc
+
+		statusCode int
+		location   bool
+	}{
+		// job of valid and invalid wallets
+		{
+			body: `[{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"},{"chain":"btc","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"}]`,
+			submitJob: func(ctx context.Context, job *walletscreener.Job) error {
+				if len(job.Wallets) != 2 || job.Completed != 1 || job.Results[1] == nil || job.Status != walletscreener.JobStatusQueued {
+					return errors.Newf("unexpected job %+v", job)
+				}
+				return nil
+			},
+			statusCode: http.StatusAccepted,
+			location:   true,
+		},
+		// queue not accepting jobs
+		{
+			body: `[{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"}]`,
+			submitJob: func(ctx context.Context, job *walletscreener.Job) error {
+				return walletscreener.ErrQueueFull
+			},
+			statusCode: http.StatusServiceUnavailable,
+		},
+		// malformed body
+		{
+			body:       `{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/jobs", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/jobs", SubmitJob(tt.submitJob, 2))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if location := w.Result().Header.Get("Location"); strings.HasPrefix(location, "/jobs/") != tt.location {
+			t.Errorf("#%d Location got %v, want job location %v", i, location, tt.location)
+		}
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	created := time.Date(2023, 10, 4, 15, 18, 20, 0, time.UTC)
+	started := time.Date(2023, 10, 4, 15, 18, 21, 0, time.UTC)
+	finished := time.Date(2023, 10, 4, 15, 18, 24, 0, time.UTC)
+
+	wallets := []walletscreener.Wallet{
+		{Chain: walletscreener.ChainEthereum, Address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"},
+		{Chain: walletscreener.ChainTron, Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+	}
+
+	var testcases = []struct {
+		getJob getJobFunc
+
+		response   string
+		statusCode int
+	}{
+		// job in progress reports progress only
+		{
+			getJob: func(ctx context.Context, id string) (*walletscreener.Job, error) {
+				return &walletscreener.Job{
+					ID:        id,
+					Status:    walletscreener.JobStatusRunning,
+					Wallets:   wallets,
+					Results:   []*walletscreener.WalletScreening{{Wallet: wallets[0], Screening: newScreening(t, "category1", "category2")}, nil},
+					Completed: 1,
+					CreatedAt: created,
+					StartedAt: started,
+				}, nil
+			},
+			response:   `{"id":"1","status":"running","total":2,"completed":1,"created_at":"2023-10-04T15:18:20Z","started_at":"2023-10-04T15:18:21Z"}`,
+			statusCode: http.StatusOK,
+		},
+		// finished job reports results
+		{
+			getJob: func(ctx context.Context, id string) (*walletscreener.Job, error) {
+				return &walletscreener.Job{
+					ID:      id,
+					Status:  walletscreener.JobStatusCompleted,
+					Wallets: wallets,
+					Results: []*walletscreener.WalletScreening{
+						{Wallet: wallets[0], Screening: newScreening(t, "category1", "category2")},
+						{Wallet: wallets[1], Err: walletscreener.ErrProviderUnavailable},
+					},
+					Completed:  2,
+					CreatedAt:  created,
+					StartedAt:  started,
+					FinishedAt: finished,
+				}, nil
+			},
+			response:   `{"id":"1","status":"completed","total":2,"completed":2,"created_at":"2023-10-04T15:18:20Z","started_at":"2023-10-04T15:18:21Z","finished_at":"2023-10-04T15:18:24Z","results":[{"chain":"eth","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","status":200,"screening":{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","screened_at":"2023-10-04T15:18:23Z","verified":true,"categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","cached":false,"verdict":"block","matched_rules":["block: category category1"]}},{"chain":"trx","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t","status":503,"error":"Service Unavailable"}]}`,
+			statusCode: http.StatusOK,
+		},
+		// unknown job
+		{
+			getJob: func(ctx context.Context, id string) (*walletscreener.Job, error) {
+				return nil, walletscreener.ErrJobNotFound
+			},
+			response:   `{"error":"job not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/jobs/1", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/jobs/{id}", GetJob(tt.getJob))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
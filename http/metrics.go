@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+// providerMetricsFunc decouples actual risk provider clients and allows easily test HTTP handler.
+type providerMetricsFunc func() map[string]ClientMetrics
+
+// GetProviderMetrics responds with metrics of API requests made to risk providers, including retries.
+func GetProviderMetrics(providerMetrics providerMetricsFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		response := api.ProviderMetricsResponse{
+			Providers: make(map[string]*api.ClientMetrics),
+		}
+		for name, v := range providerMetrics() {
+			response.Providers[name] = &api.ClientMetrics{
+				Requests: v.Requests,
+				Attempts: v.Attempts,
+				Retries:  v.Retries,
+				Failures: v.Failures,
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, &response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "metrics",
+				"method":  "GetProviderMetrics",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetProviderMetrics(t *testing.T) {
+	var testcases = []struct {
+		providerMetrics providerMetricsFunc
+
+		response string
+	}{
+		{
+			providerMetrics: func() map[string]ClientMetrics {
+				return map[string]ClientMetrics{"blockmate": {Requests: 10, Attempts: 12, Retries: 2}}
+			},
+			response: `{"providers":{"blockmate":{"requests":10,"attempts":12,"retries":2,"failures":0}}}`,
+		},
+		// providers are not called through API clients
+		{
+			providerMetrics: func() map[string]ClientMetrics {
+				return nil
+			},
+			response: `{"providers":{}}`,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/metrics/providers", nil)
+		w := httptest.NewRecorder()
+
+		GetProviderMetrics(tt.providerMetrics).ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != http.StatusOK {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, http.StatusOK)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// RetryPolicy represents configuration of retrying failed idempotent requests.
+type RetryPolicy struct {
+	MaxAttempts int           `mapstructure:"maxattempts"` // Maximum number of attempts including the first one, values below 2 disable retries
+	BaseDelay   time.Duration `mapstructure:"basedelay"`   // Base delay of exponential backoff
+	MaxDelay    time.Duration `mapstructure:"maxdelay"`    // Maximum delay between attempts
+}
+
+// Default retry policy values.
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// maxDelay returns maximum delay between attempts falling back to the default one.
+func (p *RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay <= 0 {
+		return defaultRetryMaxDelay
+	}
+	return p.MaxDelay
+}
+
+// Backoff returns delay before given attempt using exponential backoff with full jitter.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	base, max := p.BaseDelay, p.maxDelay()
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	delay := max
+	if shift := attempt - 1; shift < 32 && base<<shift > 0 && base<<shift < max {
+		delay = base << shift
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// idempotentMethods contains HTTP methods safe to retry.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatusCodes contains HTTP status codes indicating transient failures.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// ClientMetrics represents counters of requests issued by Client.
+type ClientMetrics struct {
+	Requests uint64 // Requests issued by callers
+	Attempts uint64 // Attempts made including retries
+	Retries  uint64 // Attempts made after a failed attempt
+	Failures uint64 // Requests failed after all attempts
+}
+
+// clientMetrics is a concurrency safe storage of ClientMetrics.
+type clientMetrics struct {
+	requests atomic.Uint64
+	attempts atomic.Uint64
+	retries  atomic.Uint64
+	failures atomic.Uint64
+}
+
+// snapshot returns current values of metrics.
+func (m *clientMetrics) snapshot() ClientMetrics {
+	return ClientMetrics{
+		Requests: m.requests.Load(),
+		Attempts: m.attempts.Load(),
+		Retries:  m.retries.Load(),
+		Failures: m.failures.Load(),
+	}
+}
+
+// retryDelay returns delay before next attempt and whether request should be retried
+// given the outcome of the attempt.
+// Requests asked by the server to be retried later than MaxDelay of the policy are not retried,
+// so that callers are not blocked for long and may tell when to retry by ResponseError.RetryAfter instead.
+func (c *Client) retryDelay(ctx context.Context, method string, attempt int, err error) (time.Duration, bool) {
+	if err == nil || attempt >= c.retry.MaxAttempts || !idempotentMethods[method] || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) {
+		return c.retry.Backoff(attempt), true // transport error
+	}
+
+	if !retryableStatusCodes[responseErr.StatusCode] {
+		return 0, false
+	}
+
+	if delay, ok := responseErr.RetryAfter(); ok {
+		return delay, delay <= c.retry.maxDelay()
+	}
+
+	return c.retry.Backoff(attempt), true
+}
+
+// wait waits for delay to elapse and returns whether the next attempt should be made.
+// Wait is skipped if it would exceed context deadline or context is done while waiting.
+func wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -2,17 +2,21 @@ package http
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
 	"github.com/deividaspetraitis/wallet-screener/log"
 	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
 )
 
 // getRiskCategoriesFunc decouples actual check implementation and allows easily test HTTP handler.
-type getRiskCategoriesFunc func(ctx context.Context, address string) ([]string, error)
+type getRiskCategoriesFunc func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error)
 
-// GetRiskCategories responds with risk categories list for given address.
+// GetRiskCategories responds with risk score and categories for given address.
 func GetRiskCategories(getRiskCategories getRiskCategoriesFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// It's always json.
@@ -26,29 +30,112 @@ func GetRiskCategories(getRiskCategories getRiskCategoriesFunc) http.HandlerFunc
 			}).Println("unable to unmarshal request data")
 
 			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
 			return
 		}
 
-		categories, err := getRiskCategories(r.Context(), request.Address)
+		ctx := r.Context()
+		if request.NoCache {
+			ctx = walletscreener.WithCacheBypass(ctx)
+		}
+
+		screening, err := getRiskCategories(ctx, request.Chain, request.Address)
 		if err != nil {
 			log.WithError(err).WithFields(log.Fields{
 				"handler": "wallet",
 				"method":  "GetRiskCategories",
 			}).Println("encountered an error retrieving risk categories")
 
+			setRetryAfter(w, err)
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewScreenWalletRiskCategoriesResponse(screening)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetRiskCategories",
+			}).Println("unable to marshal response data")
+
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+	}
+}
+
+// screenWalletsFunc decouples actual batch screening implementation and allows easily test HTTP handler.
+type screenWalletsFunc func(ctx context.Context, wallets []walletscreener.Wallet) ([]*walletscreener.WalletScreening, error)
+
+// ScreenWallets responds with risk score and categories, or error, for each wallet of the batch.
+// Request body is limited to the size of maxWallets wallets.
+func ScreenWallets(screenWallets screenWalletsFunc, maxWallets int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		request := api.ScreenWalletsRequest{MaxWallets: maxWallets}
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "ScreenWallets",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		ctx := r.Context()
+		if request.NoCache {
+			ctx = walletscreener.WithCacheBypass(ctx)
+		}
 
-		response := api.ScreenWalletRiskCategoriesResponse{
-			Categories: categories,
+		// invalid wallets are reported without being screened
+		var (
+			results = make([]*walletscreener.WalletScreening, len(request.Wallets))
+			wallets []walletscreener.Wallet
+			index   []int
+		)
+		for i, v := range request.Wallets {
+			if request.Errors[i] != nil {
+				results[i] = &walletscreener.WalletScreening{Wallet: v, Err: request.Errors[i]}
+				continue
+			}
+			wallets = append(wallets, v)
+			index = append(index, i)
 		}
 
+		screenings, err := screenWallets(ctx, wallets)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "ScreenWallets",
+			}).Println("encountered an error screening wallets")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		for i, v := range screenings {
+			results[index[i]] = v
+		}
+
+		response := api.NewScreenWalletsResponse(results, statusCode)
+
 		w.WriteHeader(http.StatusOK)
-		if err := Marshal(w, &response); err != nil {
+		if err := Marshal(w, response); err != nil {
 			log.WithError(err).WithFields(log.Fields{
 				"handler": "wallet",
-				"method":  "GetRiskCategories",
+				"method":  "ScreenWallets",
 			}).Println("unable to marshal response data")
 
 			w.WriteHeader(http.StatusInternalServerError)
@@ -58,9 +145,9 @@ func GetRiskCategories(getRiskCategories getRiskCategoriesFunc) http.HandlerFunc
 }
 
 // historyFunc decouples actual check implementation and allows easily test HTTP handler.
-type getRiskCategoriesHistoryFunc func(ctx context.Context, address string) ([]*walletscreener.HistoricalRiskCategory, error)
+type getRiskCategoriesHistoryFunc func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error)
 
-// History responds with risk categories history list for given address.
+// History responds with history of screenings for given address.
 func GetRiskCategoriesHistory(getRiskCategoriesHistory getRiskCategoriesHistoryFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// It's always json.
@@ -74,21 +161,33 @@ func GetRiskCategoriesHistory(getRiskCategoriesHistory getRiskCategoriesHistoryF
 			}).Println("unable to unmarshal request data")
 
 			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
 			return
 		}
 
-		categories, err := getRiskCategoriesHistory(r.Context(), request.Address)
+		page, err := getRiskCategoriesHistory(r.Context(), walletscreener.ScreeningsQuery{
+			Chain:      request.Chain,
+			Address:    request.Address,
+			Cursor:     request.Cursor,
+			Since:      request.Since,
+			Until:      request.Until,
+			Descending: request.Descending,
+			Limit:      request.Limit,
+		})
 		if err != nil {
 			log.WithError(err).WithFields(log.Fields{
 				"handler": "wallet",
 				"method":  "GetRiskCategoriesHistory",
 			}).Println("encountered an error retrieving risk categories history")
 
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
 			return
 		}
 
-		response := api.NewGetWalletRiskCategoriesHistoryRespone(categories)
+		response := api.NewGetWalletRiskCategoriesHistoryRespone(page)
 
 		w.WriteHeader(http.StatusOK)
 		if err := Marshal(w, response); err != nil {
@@ -102,3 +201,157 @@ func GetRiskCategoriesHistory(getRiskCategoriesHistory getRiskCategoriesHistoryF
 		}
 	}
 }
+
+// getLatestScreeningFunc decouples actual lookup implementation and allows easily test HTTP handler.
+type getLatestScreeningFunc func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error)
+
+// GetLatestScreening responds with the most recently stored screening for given address and its age.
+func GetLatestScreening(getLatestScreening getLatestScreeningFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.GetLatestScreeningRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetLatestScreening",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		screening, err := getLatestScreening(r.Context(), request.Chain, request.Address)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetLatestScreening",
+			}).Println("encountered an error retrieving latest screening")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewGetLatestScreeningResponse(screening, time.Now())
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetLatestScreening",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// getScreeningProofFunc decouples actual proof implementation and allows easily test HTTP handler.
+type getScreeningProofFunc func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error)
+
+// GetScreeningProof responds with proof of the stored screening for given address.
+func GetScreeningProof(getScreeningProof getScreeningProofFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.GetScreeningProofRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetScreeningProof",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		proof, err := getScreeningProof(r.Context(), request.Chain, request.Address, request.ID)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetScreeningProof",
+			}).Println("encountered an error retrieving screening proof")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewGetScreeningProofResponse(proof)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "wallet",
+				"method":  "GetScreeningProof",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// statusCode maps service layer error to HTTP status code.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, walletscreener.ErrChainNotSupported):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrAddressNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, api.ErrAddressNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrBatchTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrScreeningNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, walletscreener.ErrProofNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, walletscreener.ErrScreeningTampered):
+		return http.StatusConflict // stored screenings conflict with trusted state, client must not rely on them
+	case errors.Is(err, walletscreener.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, walletscreener.ErrWatchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, walletscreener.ErrWatchIntervalInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrSubscriptionNotFound), errors.Is(err, walletscreener.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, walletscreener.ErrWebhookURLInvalid), errors.Is(err, walletscreener.ErrWebhookEventInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, walletscreener.ErrQueueClosed), errors.Is(err, walletscreener.ErrQueueFull):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, walletscreener.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// setRetryAfter sets Retry-After header if err indicates when request can be retried.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var unavailable *walletscreener.ProviderUnavailableError
+	if !errors.As(err, &unavailable) {
+		return
+	}
+
+	// Retry-After is expressed in whole seconds, round up to not invite retries too early.
+	seconds := int64(math.Ceil(unavailable.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
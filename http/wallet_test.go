@@ -2,19 +2,51 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
 
 	"github.com/gorilla/mux"
 )
 
+func newScreening(t *testing.T, own, sourceOfFunds string) *walletscreener.Screening {
+	t.Helper()
+	return &walletscreener.Screening{
+		ID:         "0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11",
+		Revision:   1,
+		ScreenedAt: time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC),
+		Verified:   true,
+		ScreeningResult: walletscreener.ScreeningResult{
+			Chain:   walletscreener.ChainEthereum,
+			Address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			Entity:  "unknown",
+			Risk:    100,
+			OwnCategories: []*walletscreener.RiskCategory{
+				{Name: own, Entity: "unknown", Risk: 100},
+			},
+			SourceOfFundsCategories: []*walletscreener.RiskCategory{
+				{Name: sourceOfFunds, Risk: 50},
+			},
+			CaseID:       "e8f0db90-5a31-44b0-930d-e83a4d573947",
+			RequestedAt:  time.Date(2023, 10, 4, 15, 18, 21, 0, time.UTC),
+			RespondedAt:  time.Date(2023, 10, 4, 15, 18, 22, 0, time.UTC),
+			Verdict:      walletscreener.VerdictBlock,
+			MatchedRules: []string{"block: category " + own},
+		},
+	}
+}
+
 func TestGetRiskCategories(t *testing.T) {
 	var testcases = []struct {
+		chain             string
 		address           string
 		getRiskCategories getRiskCategoriesFunc
 
@@ -24,47 +56,135 @@ func TestGetRiskCategories(t *testing.T) {
 		// not a valid address
 		{
 			address: "abc",
-			getRiskCategories: func(ctx context.Context, address string) ([]string, error) {
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
 				return nil, nil
 			},
+			response:   `{"error":"given address is not valid wallet address: expected 42 characters, got 3: address has invalid length"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// address checksum mismatch
+		{
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A68",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, nil
+			},
+			response:   `{"error":"given address is not valid wallet address: EIP-55 checksum mismatch: address checksum is not valid"}`,
 			statusCode: http.StatusBadRequest,
 		},
 		// empty categories list
 		{
 			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
-			getRiskCategories: func(ctx context.Context, address string) ([]string, error) {
-				return nil, nil
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return &walletscreener.Screening{}, nil
 			},
-			response:   `{"categories":[]}`,
+			response:   `{"verified":false,"categories":[],"risk":0,"own_categories":[],"source_of_funds_categories":[],"requested_at":"0001-01-01T00:00:00Z","responded_at":"0001-01-01T00:00:00Z","cached":false,"matched_rules":[]}`,
 			statusCode: http.StatusOK,
 		},
 		// non-empty categories list
 		{
 			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
-			getRiskCategories: func(ctx context.Context, address string) ([]string, error) {
-				return []string{"category1", "category2"}, nil
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return newScreening(t, "category1", "category2"), nil
+			},
+			response:   `{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","screened_at":"2023-10-04T15:18:23Z","verified":true,"categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","cached":false,"verdict":"block","matched_rules":["block: category category1"]}`,
+			statusCode: http.StatusOK,
+		},
+		// change since the previous screening
+		{
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				screening := newScreening(t, "category1", "category2")
+				screening.Change = &walletscreener.RiskChange{
+					PreviousID:      "8d3c6f0e-2b1a-4c5d-9e8f-7a6b5c4d3e2f",
+					PreviousVerdict: walletscreener.VerdictAllow,
+					AddedCategories: []string{"category1"},
+					RiskDelta:       60,
+				}
+				return screening, nil
 			},
-			response:   `{"categories":["category1","category2"]}`,
+			response:   `{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","screened_at":"2023-10-04T15:18:23Z","verified":true,"categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","cached":false,"verdict":"block","matched_rules":["block: category category1"],"change":{"changed":true,"previous_id":"8d3c6f0e-2b1a-4c5d-9e8f-7a6b5c4d3e2f","previous_verdict":"allow","added_categories":["category1"],"removed_categories":[],"risk_delta":60}}`,
 			statusCode: http.StatusOK,
 		},
 		// service error
 		{
 			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
-			getRiskCategories: func(ctx context.Context, address string) ([]string, error) {
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
 				return nil, errors.New("test getRiskCategories errors")
 			},
 			response:   "",
 			statusCode: http.StatusInternalServerError,
 		},
+		// chain given in the route
+		{
+			chain:   "matic",
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				if chain != walletscreener.ChainPolygon {
+					return nil, errors.Newf("unexpected chain %s", chain)
+				}
+				return &walletscreener.Screening{
+					ScreeningResult: walletscreener.ScreeningResult{
+						OwnCategories: []*walletscreener.RiskCategory{{Name: "category1", Risk: 10}},
+					},
+				}, nil
+			},
+			response:   `{"verified":false,"categories":["category1"],"risk":0,"own_categories":[{"name":"category1","risk":10}],"source_of_funds_categories":[],"requested_at":"0001-01-01T00:00:00Z","responded_at":"0001-01-01T00:00:00Z","cached":false,"matched_rules":[]}`,
+			statusCode: http.StatusOK,
+		},
+		// unknown chain
+		{
+			chain:   "doge",
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, nil
+			},
+			response:   `{"error":"chain \"doge\": chain is not supported"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// address of other chain
+		{
+			chain:   "btc",
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, nil
+			},
+			response:   `{"error":"given address is not valid wallet address: invalid base58 character '0' at position 0: address has invalid encoding"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// chain not supported by the provider
+		{
+			chain:   "btc",
+			address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, errors.Wrap(walletscreener.ErrChainNotSupported, "test provider")
+			},
+			response:   `{"error":"test provider: chain is not supported"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// address rejected by the provider
+		{
+			address: "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			getRiskCategories: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, walletscreener.ErrAddressNotValid
+			},
+			response:   `{"error":"risk provider rejected address as not valid"}`,
+			statusCode: http.StatusBadRequest,
+		},
 	}
 
 	for i, tt := range testcases {
-		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost/wallet/%s/categories", tt.address), nil)
+		target := fmt.Sprintf("http://localhost/wallet/%s/categories", tt.address)
+		if tt.chain != "" {
+			target = fmt.Sprintf("http://localhost/wallet/%s/%s/categories", tt.chain, tt.address)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, target, nil)
 		w := httptest.NewRecorder()
 
 		// To add the vars to the context we need to create a router through which we can pass the request.
 		// TODO: tests should be not aware of routing mechanism.
 		router := mux.NewRouter()
+		router.HandleFunc("/wallet/{chain}/{address}/categories", GetRiskCategories(tt.getRiskCategories))
 		router.HandleFunc("/wallet/{address}/categories", GetRiskCategories(tt.getRiskCategories))
 
 		router.ServeHTTP(w, req)
@@ -79,3 +199,313 @@ func TestGetRiskCategories(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRiskCategoriesProviderUnavailable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories", nil)
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/wallet/{address}/categories", GetRiskCategories(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		return nil, errors.Wrap(&walletscreener.ProviderUnavailableError{Provider: "test", RetryAfter: 1500 * time.Millisecond}, "test provider")
+	}))
+
+	router.ServeHTTP(w, req)
+
+	if statusCode := w.Result().StatusCode; statusCode != http.StatusServiceUnavailable {
+		t.Errorf("HTTP status got %v, want %v", statusCode, http.StatusServiceUnavailable)
+	}
+
+	if retryAfter := w.Result().Header.Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("Retry-After got %v, want %v", retryAfter, "2")
+	}
+}
+
+func TestGetScreeningProof(t *testing.T) {
+	var testcases = []struct {
+		id                string
+		getScreeningProof getScreeningProofFunc
+
+		response   string
+		statusCode int
+	}{
+		// proof of existing screening
+		{
+			id: "0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11",
+			getScreeningProof: func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+				screening := newScreening(t, "category1", "category2")
+				if id != screening.ID {
+					return nil, errors.Newf("unexpected id %s", id)
+				}
+				return &walletscreener.ScreeningProof{
+					Screening: screening,
+					Format:    "test",
+					Proof:     []byte(`{"entries":[]}`),
+				}, nil
+			},
+			response:   `{"format":"test","screening":{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","revision":1,"screened_at":"2023-10-04T15:18:23Z","verified":true,"chain":"eth","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","verdict":"block","matched_rules":["block: category category1"]},"proof":{"entries":[]}}`,
+			statusCode: http.StatusOK,
+		},
+		// unknown screening
+		{
+			id: "unknown",
+			getScreeningProof: func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+				return nil, walletscreener.ErrScreeningNotFound
+			},
+			response:   `{"error":"screening not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+		// store not able to prove screenings
+		{
+			id: "0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11",
+			getScreeningProof: func(ctx context.Context, chain walletscreener.Chain, address string, id string) (*walletscreener.ScreeningProof, error) {
+				return nil, walletscreener.ErrProofNotSupported
+			},
+			response:   "",
+			statusCode: http.StatusNotImplemented,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/screenings/%s/proof", tt.id), nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/wallet/{address}/screenings/{id}/proof", GetScreeningProof(tt.getScreeningProof))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP status got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestGetRiskCategoriesHistory(t *testing.T) {
+	var testcases = []struct {
+		target                   string
+		getRiskCategoriesHistory getRiskCategoriesHistoryFunc
+
+		response   string
+		statusCode int
+	}{
+		// page of history along cursor of the next page
+		{
+			target: "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories?cursor=3&since=2023-10-04T00:00:00Z&until=2023-10-05T00:00:00Z&order=desc&limit=1",
+			getRiskCategoriesHistory: func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+				expected := walletscreener.ScreeningsQuery{
+					Chain:      walletscreener.ChainEthereum,
+					Address:    "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+					Cursor:     "3",
+					Since:      time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC),
+					Until:      time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC),
+					Descending: true,
+					Limit:      1,
+				}
+				if query != expected {
+					return nil, errors.Newf("unexpected query %+v", query)
+				}
+				return &walletscreener.ScreeningsPage{
+					Screenings: []*walletscreener.Screening{newScreening(t, "category1", "category2")},
+					Next:       "1",
+				}, nil
+			},
+			response:   `{"screenings":[{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","revision":1,"screened_at":"2023-10-04T15:18:23Z","verified":true,"chain":"eth","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","verdict":"block","matched_rules":["block: category category1"]}],"next":"1"}`,
+			statusCode: http.StatusOK,
+		},
+		// the last page
+		{
+			target: "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories",
+			getRiskCategoriesHistory: func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+				return &walletscreener.ScreeningsPage{}, nil
+			},
+			response:   `{"screenings":[]}`,
+			statusCode: http.StatusOK,
+		},
+		// cursor not returned by the store
+		{
+			target: "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories?cursor=x",
+			getRiskCategoriesHistory: func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+				return nil, walletscreener.ErrInvalidCursor
+			},
+			response:   `{"error":"screenings cursor is not valid"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// malformed query parameter
+		{
+			target: "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories?order=random",
+			getRiskCategoriesHistory: func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+				return nil, errors.New("unexpected call")
+			},
+			response:   `{"error":"given query parameter is not valid: order \"random\" is neither asc nor desc"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// history fails verification
+		{
+			target: "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories",
+			getRiskCategoriesHistory: func(ctx context.Context, query walletscreener.ScreeningsQuery) (*walletscreener.ScreeningsPage, error) {
+				return nil, errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("entry is not included in its transaction"))
+			},
+			response:   `{"error":"screening failed verification against trusted state"}`,
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+tt.target, nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/wallet/{address}/categories", GetRiskCategoriesHistory(tt.getRiskCategoriesHistory))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestGetLatestScreening(t *testing.T) {
+	var testcases = []struct {
+		getLatestScreening getLatestScreeningFunc
+
+		age        int64
+		response   string
+		statusCode int
+	}{
+		// screening recorded a minute and a half ago
+		{
+			getLatestScreening: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				screening := newScreening(t, "category1", "category2")
+				screening.ScreenedAt = time.Now().Add(-90 * time.Second)
+				return screening, nil
+			},
+			age:        90,
+			statusCode: http.StatusOK,
+		},
+		// wallet was never screened
+		{
+			getLatestScreening: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, walletscreener.ErrScreeningNotFound
+			},
+			response:   `{"error":"screening not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+		// latest screening fails verification
+		{
+			getLatestScreening: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+				return nil, errors.Wrap(errors.WithReason(walletscreener.ErrScreeningTampered, errors.New("entry is not included in its transaction")), "failed to verify screening")
+			},
+			response:   `{"error":"screening failed verification against trusted state"}`,
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/screenings/latest", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/wallet/{address}/screenings/latest", GetLatestScreening(tt.getLatestScreening))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			// we do apply TrimSpace to clean up response coming from HTTP protocol
+			if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+				t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+			}
+			continue
+		}
+
+		var response api.GetLatestScreeningResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		if response.Screening == nil || response.Screening.ID != "0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11" {
+			t.Errorf("#%d screening got %+v, want %v", i, response.Screening, "0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11")
+		}
+
+		if response.AgeSeconds == nil || *response.AgeSeconds != tt.age {
+			t.Errorf("#%d age got %v, want %v", i, response.AgeSeconds, tt.age)
+		}
+	}
+}
+
+func TestScreenWallets(t *testing.T) {
+	var testcases = []struct {
+		body          string
+		screenWallets screenWalletsFunc
+
+		response   string
+		statusCode int
+	}{
+		// valid wallets are screened, invalid ones reported without screening
+		{
+			body: `[{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"},{"chain":"btc","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"},{"chain":"trx","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"}]`,
+			screenWallets: func(ctx context.Context, wallets []walletscreener.Wallet) ([]*walletscreener.WalletScreening, error) {
+				if len(wallets) != 2 {
+					return nil, errors.Newf("unexpected wallets %v", wallets)
+				}
+				return []*walletscreener.WalletScreening{
+					{Wallet: wallets[0], Screening: newScreening(t, "category1", "category2")},
+					{Wallet: wallets[1], Err: walletscreener.ErrProviderUnavailable},
+				}, nil
+			},
+			response:   `{"results":[{"chain":"eth","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","status":200,"screening":{"id":"0b5c1a0e-6c3f-4f4e-9d0c-4b8d4c1f8a11","screened_at":"2023-10-04T15:18:23Z","verified":true,"categories":["category1","category2"],"risk":100,"entity":"unknown","own_categories":[{"name":"category1","entity":"unknown","risk":100}],"source_of_funds_categories":[{"name":"category2","risk":50}],"case_id":"e8f0db90-5a31-44b0-930d-e83a4d573947","requested_at":"2023-10-04T15:18:21Z","responded_at":"2023-10-04T15:18:22Z","cached":false,"verdict":"block","matched_rules":["block: category category1"]}},{"chain":"btc","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","status":400,"error":"given address is not valid wallet address: invalid base58 character '0' at position 0: address has invalid encoding"},{"chain":"trx","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t","status":503,"error":"Service Unavailable"}]}`,
+			statusCode: http.StatusOK,
+		},
+		// batch exceeding configured size
+		{
+			body: `[{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"}]`,
+			screenWallets: func(ctx context.Context, wallets []walletscreener.Wallet) ([]*walletscreener.WalletScreening, error) {
+				return nil, walletscreener.ErrBatchTooLarge
+			},
+			response:   `{"error":"batch has too many wallets"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// empty batch
+		{
+			body:       `[]`,
+			response:   `{"error":"given request body is not valid: batch has no wallets"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// body exceeding size of configured number of wallets is not read whole
+		{
+			body:       "[" + strings.Repeat(`{"address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"},`, 20) + `{}]`,
+			response:   `{"error":"given request body is not valid: body exceeds 768 bytes: batch has too many wallets"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/wallets/screenings", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/wallets/screenings", ScreenWallets(tt.screenWallets, 2))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
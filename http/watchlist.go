@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+// watchWalletFunc decouples actual watchlist implementation and allows easily test HTTP handler.
+type watchWalletFunc func(ctx context.Context, chain walletscreener.Chain, address string, interval time.Duration) (*walletscreener.Watch, error)
+
+// WatchWallet responds with watch of given address registered to be re-screened periodically.
+func WatchWallet(watchWallet watchWalletFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.WatchWalletRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "WatchWallet",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		watch, err := watchWallet(r.Context(), request.Chain, request.Address, request.Interval)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "WatchWallet",
+			}).Println("encountered an error watching wallet")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewWatchResponse(watch)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "WatchWallet",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// getWatchFunc decouples actual watchlist implementation and allows easily test HTTP handler.
+type getWatchFunc func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error)
+
+// GetWatch responds with watch of given address along outcome of its last re-screening.
+func GetWatch(getWatch getWatchFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.GetWatchRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "GetWatch",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		watch, err := getWatch(r.Context(), request.Chain, request.Address)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "GetWatch",
+			}).Println("encountered an error retrieving watch")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewWatchResponse(watch)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "GetWatch",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// unwatchWalletFunc decouples actual watchlist implementation and allows easily test HTTP handler.
+type unwatchWalletFunc func(ctx context.Context, chain walletscreener.Chain, address string) error
+
+// UnwatchWallet responds with no content once given address is no longer re-screened.
+func UnwatchWallet(unwatchWallet unwatchWalletFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.UnwatchWalletRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "UnwatchWallet",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		if err := unwatchWallet(r.Context(), request.Chain, request.Address); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "UnwatchWallet",
+			}).Println("encountered an error unwatching wallet")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// listWatchesFunc decouples actual watchlist implementation and allows easily test HTTP handler.
+type listWatchesFunc func(ctx context.Context) ([]*walletscreener.Watch, error)
+
+// ListWatches responds with every watched wallet.
+func ListWatches(listWatches listWatchesFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		watches, err := listWatches(r.Context())
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "ListWatches",
+			}).Println("encountered an error listing watches")
+
+			w.WriteHeader(statusCode(err))
+			return
+		}
+
+		response := api.NewListWatchesResponse(watches)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "watchlist",
+				"method":  "ListWatches",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
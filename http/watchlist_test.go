@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+
+	"github.com/gorilla/mux"
+)
+
+// newWatch returns watch of the wallet re-screened daily.
+func newWatch(chain walletscreener.Chain, address string) *walletscreener.Watch {
+	return &walletscreener.Watch{
+		Chain:     chain,
+		Address:   address,
+		Interval:  24 * time.Hour,
+		CreatedAt: time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC),
+		NextAt:    time.Date(2023, 10, 5, 15, 18, 23, 0, time.UTC),
+	}
+}
+
+func TestWatchWallet(t *testing.T) {
+	var testcases = []struct {
+		url         string
+		body        string
+		watchWallet watchWalletFunc
+
+		response   string
+		statusCode int
+	}{
+		// wallet is watched
+		{
+			url:  "http://localhost/watchlist/trx/TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+			body: `{"interval":"24h"}`,
+			watchWallet: func(ctx context.Context, chain walletscreener.Chain, address string, interval time.Duration) (*walletscreener.Watch, error) {
+				return newWatch(chain, address), nil
+			},
+			response:   `{"chain":"trx","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t","interval":"24h0m0s","created_at":"2023-10-04T15:18:23Z","next_at":"2023-10-05T15:18:23Z"}`,
+			statusCode: http.StatusOK,
+		},
+		// interval shorter than allowed
+		{
+			url:  "http://localhost/watchlist/eth/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			body: `{"interval":"1m"}`,
+			watchWallet: func(ctx context.Context, chain walletscreener.Chain, address string, interval time.Duration) (*walletscreener.Watch, error) {
+				return nil, walletscreener.ErrWatchIntervalInvalid
+			},
+			response:   `{"error":"watch interval must be at least 1h0m0s"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// malformed interval
+		{
+			url:        "http://localhost/watchlist/eth/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67",
+			body:       `{"interval":"daily"}`,
+			response:   `{"error":"given request body is not valid: time: invalid duration \"daily\""}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPut, tt.url, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/watchlist/{chain}/{address}", WatchWallet(tt.watchWallet))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestGetWatch(t *testing.T) {
+	screened := time.Date(2023, 10, 5, 15, 18, 23, 0, time.UTC)
+
+	var testcases = []struct {
+		getWatch getWatchFunc
+
+		response   string
+		statusCode int
+	}{
+		// failed re-screening is reported
+		{
+			getWatch: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+				watch := newWatch(chain, address)
+				watch.LastScreenedAt, watch.LastError = screened, "failed to store screening"
+				return watch, nil
+			},
+			response:   `{"chain":"eth","address":"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67","interval":"24h0m0s","created_at":"2023-10-04T15:18:23Z","next_at":"2023-10-05T15:18:23Z","last_screened_at":"2023-10-05T15:18:23Z","last_error":"failed to store screening"}`,
+			statusCode: http.StatusOK,
+		},
+		// wallet not watched
+		{
+			getWatch: func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Watch, error) {
+				return nil, walletscreener.ErrWatchNotFound
+			},
+			response:   `{"error":"wallet is not watched"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/watchlist/eth/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/watchlist/{chain}/{address}", GetWatch(tt.getWatch))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestUnwatchWallet(t *testing.T) {
+	var testcases = []struct {
+		unwatchWallet unwatchWalletFunc
+
+		statusCode int
+	}{
+		{
+			unwatchWallet: func(ctx context.Context, chain walletscreener.Chain, address string) error {
+				return nil
+			},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			unwatchWallet: func(ctx context.Context, chain walletscreener.Chain, address string) error {
+				return walletscreener.ErrWatchNotFound
+			},
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodDelete, "http://localhost/watchlist/eth/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/watchlist/{chain}/{address}", UnwatchWallet(tt.unwatchWallet))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+	}
+}
+
+func TestListWatches(t *testing.T) {
+	var testcases = []struct {
+		listWatches listWatchesFunc
+
+		response   string
+		statusCode int
+	}{
+		{
+			listWatches: func(ctx context.Context) ([]*walletscreener.Watch, error) {
+				return []*walletscreener.Watch{newWatch(walletscreener.ChainTron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")}, nil
+			},
+			response:   `{"watches":[{"chain":"trx","address":"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t","interval":"24h0m0s","created_at":"2023-10-04T15:18:23Z","next_at":"2023-10-05T15:18:23Z"}]}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			listWatches: func(ctx context.Context) ([]*walletscreener.Watch, error) {
+				return nil, nil
+			},
+			response:   `{"watches":[]}`,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/watchlist", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/watchlist", ListWatches(tt.listWatches))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+// createSubscriptionFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type createSubscriptionFunc func(ctx context.Context, url, secret string, events []walletscreener.WebhookEvent) (*walletscreener.Subscription, error)
+
+// CreateSubscription responds with subscription of given endpoint to screening events along its secret.
+func CreateSubscription(createSubscription createSubscriptionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.CreateSubscriptionRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "CreateSubscription",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		subscription, err := createSubscription(r.Context(), request.URL, request.Secret, request.Events)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "CreateSubscription",
+			}).Println("encountered an error creating subscription")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewCreateSubscriptionResponse(subscription)
+
+		w.Header().Set("Location", "/webhooks/"+subscription.ID)
+		w.WriteHeader(http.StatusCreated)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "CreateSubscription",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// getSubscriptionFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type getSubscriptionFunc func(ctx context.Context, id string) (*walletscreener.Subscription, error)
+
+// GetSubscription responds with subscription identified by id.
+func GetSubscription(getSubscription getSubscriptionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.GetSubscriptionRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "GetSubscription",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		subscription, err := getSubscription(r.Context(), request.ID)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "GetSubscription",
+			}).Println("encountered an error retrieving subscription")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewSubscriptionResponse(subscription)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "GetSubscription",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// deleteSubscriptionFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type deleteSubscriptionFunc func(ctx context.Context, id string) error
+
+// DeleteSubscription responds with no content once subscription identified by id is no longer notified.
+func DeleteSubscription(deleteSubscription deleteSubscriptionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.DeleteSubscriptionRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "DeleteSubscription",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		if err := deleteSubscription(r.Context(), request.ID); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "DeleteSubscription",
+			}).Println("encountered an error deleting subscription")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// listSubscriptionsFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type listSubscriptionsFunc func(ctx context.Context) ([]*walletscreener.Subscription, error)
+
+// ListSubscriptions responds with every webhook subscription.
+func ListSubscriptions(listSubscriptions listSubscriptionsFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		subscriptions, err := listSubscriptions(r.Context())
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "ListSubscriptions",
+			}).Println("encountered an error listing subscriptions")
+
+			w.WriteHeader(statusCode(err))
+			return
+		}
+
+		response := api.NewListSubscriptionsResponse(subscriptions)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "ListSubscriptions",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// listDeliveriesFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type listDeliveriesFunc func(ctx context.Context, query walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error)
+
+// ListDeliveries responds with deliveries of the subscription along their logs, the most recent first.
+func ListDeliveries(listDeliveries listDeliveriesFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.ListDeliveriesRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "ListDeliveries",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		deliveries, err := listDeliveries(r.Context(), walletscreener.DeliveriesQuery{
+			SubscriptionID: request.SubscriptionID,
+			Status:         request.Status,
+			Limit:          request.Limit,
+		})
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "ListDeliveries",
+			}).Println("encountered an error listing deliveries")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewListDeliveriesResponse(deliveries)
+
+		w.WriteHeader(http.StatusOK)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "ListDeliveries",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// redeliverFunc decouples actual webhooks implementation and allows easily test HTTP handler.
+type redeliverFunc func(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error)
+
+// Redeliver responds with delivery of the subscription scheduled to be attempted again, e.g. once it is dead.
+func Redeliver(redeliver redeliverFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// It's always json.
+		w.Header().Set("Content-Type", "application/json")
+
+		var request api.RedeliverRequest
+		if err := UnmarshalRequest(r, &request); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "Redeliver",
+			}).Println("unable to unmarshal request data")
+
+			w.WriteHeader(http.StatusBadRequest)
+			Marshal(w, api.NewErrorResponse(err))
+			return
+		}
+
+		delivery, err := redeliver(r.Context(), request.SubscriptionID, request.ID)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "Redeliver",
+			}).Println("encountered an error redelivering")
+
+			w.WriteHeader(statusCode(err))
+			if statusCode(err) < http.StatusInternalServerError {
+				Marshal(w, api.NewErrorResponse(err))
+			}
+			return
+		}
+
+		response := api.NewDeliveryResponse(delivery)
+
+		w.WriteHeader(http.StatusAccepted)
+		if err := Marshal(w, response); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"handler": "webhook",
+				"method":  "Redeliver",
+			}).Println("unable to marshal response data")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	var testcases = []struct {
+		body               string
+		createSubscription createSubscriptionFunc
+
+		response   string
+		statusCode int
+		location   string
+	}{
+		// subscription is created along its secret
+		{
+			body: `{"url":"https://example.com/hook","events":["screening.blocked"]}`,
+			createSubscription: func(ctx context.Context, url, secret string, events []walletscreener.WebhookEvent) (*walletscreener.Subscription, error) {
+				return &walletscreener.Subscription{
+					ID:        "2a7c1e9e-5b8f-4f0e-9a3b-6c1d2e3f4a5b",
+					URL:       url,
+					Secret:    "generated",
+					Events:    events,
+					CreatedAt: time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC),
+				}, nil
+			},
+			response:   `{"id":"2a7c1e9e-5b8f-4f0e-9a3b-6c1d2e3f4a5b","url":"https://example.com/hook","secret":"generated","events":["screening.blocked"],"created_at":"2023-10-04T15:18:23Z"}`,
+			statusCode: http.StatusCreated,
+			location:   "/webhooks/2a7c1e9e-5b8f-4f0e-9a3b-6c1d2e3f4a5b",
+		},
+		// unknown event
+		{
+			body: `{"url":"https://example.com/hook","events":["screening.allowed"]}`,
+			createSubscription: func(ctx context.Context, url, secret string, events []walletscreener.WebhookEvent) (*walletscreener.Subscription, error) {
+				return nil, walletscreener.ErrWebhookEventInvalid
+			},
+			response:   `{"error":"webhook event is not supported"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// url is missing
+		{
+			body:       `{"events":["screening.blocked"]}`,
+			response:   `{"error":"given request body is not valid: url is required"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/webhooks", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/webhooks", CreateSubscription(tt.createSubscription))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if location := w.Result().Header.Get("Location"); location != tt.location {
+			t.Errorf("#%d HTTP location got %v, want %v", i, location, tt.location)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	created := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	var testcases = []struct {
+		url            string
+		listDeliveries listDeliveriesFunc
+
+		response   string
+		statusCode int
+	}{
+		// dead letters are listed along their log
+		{
+			url: "http://localhost/webhooks/hook/deliveries?status=dead&limit=10",
+			listDeliveries: func(ctx context.Context, query walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+				if query.SubscriptionID != "hook" || query.Status != walletscreener.DeliveryStatusDead || query.Limit != 10 {
+					t.Errorf("got %+v, want dead deliveries of hook limited to 10", query)
+				}
+
+				return []*walletscreener.Delivery{{
+					ID:             "delivery",
+					SubscriptionID: "hook",
+					Event:          walletscreener.WebhookEventBlocked,
+					ScreeningID:    "screening",
+					Payload:        []byte(`{"id":"delivery"}`),
+					Status:         walletscreener.DeliveryStatusDead,
+					Attempts:       1,
+					Log:            []walletscreener.DeliveryAttempt{{At: created, StatusCode: http.StatusInternalServerError, Error: "request resulted in 500 response code", Duration: 150 * time.Millisecond}},
+					NextAttemptAt:  created,
+					CreatedAt:      created,
+				}}, nil
+			},
+			response:   `{"deliveries":[{"id":"delivery","subscription_id":"hook","event":"screening.blocked","screening_id":"screening","status":"dead","attempts":1,"log":[{"at":"2023-10-04T15:18:23Z","status_code":500,"error":"request resulted in 500 response code","duration":"150ms"}],"payload":{"id":"delivery"},"created_at":"2023-10-04T15:18:23Z"}]}`,
+			statusCode: http.StatusOK,
+		},
+		// unknown status
+		{
+			url:        "http://localhost/webhooks/hook/deliveries?status=failed",
+			response:   `{"error":"given query parameter is not valid: status \"failed\" is not one of pending, delivered or dead"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		// subscription not found
+		{
+			url: "http://localhost/webhooks/hook/deliveries",
+			listDeliveries: func(ctx context.Context, query walletscreener.DeliveriesQuery) ([]*walletscreener.Delivery, error) {
+				return nil, walletscreener.ErrSubscriptionNotFound
+			},
+			response:   `{"error":"webhook subscription not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/webhooks/{id}/deliveries", ListDeliveries(tt.listDeliveries))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	created := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	var testcases = []struct {
+		redeliver redeliverFunc
+
+		response   string
+		statusCode int
+	}{
+		// dead delivery is pending again
+		{
+			redeliver: func(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+				return &walletscreener.Delivery{
+					ID:             id,
+					SubscriptionID: subscriptionID,
+					Event:          walletscreener.WebhookEventCategoriesChanged,
+					ScreeningID:    "screening",
+					Status:         walletscreener.DeliveryStatusPending,
+					NextAttemptAt:  created.Add(time.Hour),
+					CreatedAt:      created,
+				}, nil
+			},
+			response:   `{"id":"delivery","subscription_id":"hook","event":"screening.categories_changed","screening_id":"screening","status":"pending","attempts":0,"log":[],"next_attempt_at":"2023-10-04T16:18:23Z","created_at":"2023-10-04T15:18:23Z"}`,
+			statusCode: http.StatusAccepted,
+		},
+		// delivery not found
+		{
+			redeliver: func(ctx context.Context, subscriptionID, id string) (*walletscreener.Delivery, error) {
+				return nil, walletscreener.ErrDeliveryNotFound
+			},
+			response:   `{"error":"webhook delivery not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/webhooks/hook/deliveries/delivery/redeliver", nil)
+		w := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/webhooks/{id}/deliveries/{delivery}/redeliver", Redeliver(tt.redeliver))
+
+		router.ServeHTTP(w, req)
+
+		if statusCode := w.Result().StatusCode; statusCode != tt.statusCode {
+			t.Errorf("#%d HTTP status got %v, want %v", i, statusCode, tt.statusCode)
+		}
+
+		if response := strings.TrimSpace(w.Body.String()); response != tt.response {
+			t.Errorf("#%d HTTP response got %v, want %s", i, response, tt.response)
+		}
+	}
+}
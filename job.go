@@ -0,0 +1,129 @@
+package walletscreener
+
+import (
+	"context"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/uuid"
+)
+
+// Job queue errors.
+var (
+	ErrJobNotFound = errors.New("job not found")                      // queue does not hold such job
+	ErrQueueClosed = errors.New("job queue is closed")                // queue no longer accepts jobs
+	ErrQueueFull   = errors.New("job queue is full, try again later") // queue holds as many pending jobs as it can
+)
+
+// JobStatus represents status of a job.
+type JobStatus string
+
+// Statuses of a job.
+const (
+	JobStatusQueued    JobStatus = "queued"    // waiting for a worker
+	JobStatusRunning   JobStatus = "running"   // wallets are being screened
+	JobStatusCompleted JobStatus = "completed" // every wallet has its outcome
+	JobStatusCanceled  JobStatus = "canceled"  // interrupted before every wallet was screened, e.g. by shutdown
+)
+
+// Job represents a batch of wallets screened in background.
+type Job struct {
+	ID        string
+	Status    JobStatus
+	Wallets   []Wallet
+	NoCache   bool               // Whether wallets must be screened instead of serving cached results
+	Results   []*WalletScreening // Outcome of wallets by their index, nil until wallet is screened
+	Completed int                // Number of wallets having outcome
+
+	CreatedAt  time.Time
+	StartedAt  time.Time // Zero until job is picked by a worker
+	FinishedAt time.Time // Zero until job is completed or canceled
+}
+
+// NewJob constructs a new queued Job screening wallets identified by a random ID.
+func NewJob(wallets []Wallet) *Job {
+	return &Job{
+		ID:        uuid.NewString(),
+		Status:    JobStatusQueued,
+		Wallets:   wallets,
+		Results:   make([]*WalletScreening, len(wallets)),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// SetResult sets outcome of wallet at index i of the job.
+func (j *Job) SetResult(i int, result *WalletScreening) {
+	if j.Results[i] == nil {
+		j.Completed++
+	}
+	j.Results[i] = result
+}
+
+// Finished returns whether job will not make any more progress.
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusCanceled
+}
+
+// Copy returns a copy of the job not sharing its results.
+func (j *Job) Copy() *Job {
+	job := *j
+	job.Wallets = append([]Wallet(nil), j.Wallets...)
+	job.Results = append([]*WalletScreening(nil), j.Results...)
+	return &job
+}
+
+// JobQueue represents a queue of jobs keeping their state for status polling.
+type JobQueue interface {
+	// Enqueue adds job to the queue.
+	// ErrQueueClosed is returned once queue is closed, ErrQueueFull if it cannot hold more pending jobs.
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Dequeue takes the next pending job waiting for one until context is done.
+	// ErrQueueClosed is returned once queue is closed and every pending job was taken.
+	Dequeue(ctx context.Context) (*Job, error)
+
+	// UpdateJob records progress of the job taken from the queue.
+	UpdateJob(ctx context.Context, job *Job) error
+
+	// GetJob returns the job identified by id, ErrJobNotFound is returned if queue does not hold such job.
+	// GetJob implements GetJobFunc.
+	GetJob(ctx context.Context, id string) (*Job, error)
+
+	// Close stops queue from accepting new jobs, pending jobs can still be taken.
+	Close() error
+}
+
+// SubmitJob enqueues job screening its wallets in background.
+// ErrBatchTooLarge is returned if job holds more wallets than configured for a batch.
+func SubmitJob(ctx context.Context, queue JobQueue, cfg *BatchConfig, job *Job) error {
+	if _, maxWallets := cfg.limits(); len(job.Wallets) > maxWallets {
+		return errors.Wrapf(ErrBatchTooLarge, "got %d wallets, at most %d are accepted", len(job.Wallets), maxWallets)
+	}
+
+	err := queue.Enqueue(ctx, job)
+	if errors.Is(err, ErrQueueClosed) || errors.Is(err, ErrQueueFull) {
+		return err
+	}
+	if err != nil {
+		return errors.New("failed to submit job")
+	}
+
+	return nil
+}
+
+// GetJobFunc retrieves job identified by id.
+type GetJobFunc func(ctx context.Context, id string) (*Job, error)
+
+// GetJob retrieves job identified by id along its progress and results.
+func GetJob(ctx context.Context, getJob GetJobFunc, id string) (*Job, error) {
+	job, err := getJob(ctx, id)
+	if errors.Is(err, ErrJobNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch job")
+	}
+
+	return job, nil
+}
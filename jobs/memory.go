@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// MemoryQueue is an in-process implementation of walletscreener.JobQueue.
+// Jobs are kept in memory and are lost on shutdown unless drained, finished jobs are kept for status polling until retention passes.
+type MemoryQueue struct {
+	mu        sync.Mutex
+	jobs      map[string]*walletscreener.Job
+	pending   chan string // IDs of queued jobs
+	closed    bool
+	retention time.Duration
+}
+
+// NewMemoryQueue constructs and returns new MemoryQueue holding at most size pending jobs.
+// Finished jobs are forgotten once retention passes, never if retention is zero.
+func NewMemoryQueue(size int, retention time.Duration) *MemoryQueue {
+	return &MemoryQueue{
+		jobs:      make(map[string]*walletscreener.Job),
+		pending:   make(chan string, size),
+		retention: retention,
+	}
+}
+
+// Enqueue implements walletscreener.JobQueue.
+func (q *MemoryQueue) Enqueue(ctx context.Context, job *walletscreener.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return walletscreener.ErrQueueClosed
+	}
+
+	q.expire(time.Now())
+
+	select {
+	case q.pending <- job.ID:
+	default:
+		return walletscreener.ErrQueueFull
+	}
+
+	q.jobs[job.ID] = job.Copy()
+
+	return nil
+}
+
+// Dequeue implements walletscreener.JobQueue.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*walletscreener.Job, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case id, ok := <-q.pending:
+		if !ok {
+			return nil, walletscreener.ErrQueueClosed
+		}
+		return q.GetJob(ctx, id)
+	}
+}
+
+// UpdateJob implements walletscreener.JobQueue.
+func (q *MemoryQueue) UpdateJob(ctx context.Context, job *walletscreener.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.jobs[job.ID]; !ok {
+		return errors.Wrapf(walletscreener.ErrJobNotFound, "job %s", job.ID)
+	}
+
+	q.jobs[job.ID] = job.Copy()
+
+	return nil
+}
+
+// GetJob implements walletscreener.JobQueue.
+func (q *MemoryQueue) GetJob(ctx context.Context, id string) (*walletscreener.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, errors.Wrapf(walletscreener.ErrJobNotFound, "job %s", id)
+	}
+
+	return job.Copy(), nil
+}
+
+// Close implements walletscreener.JobQueue.
+func (q *MemoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.pending)
+	}
+
+	return nil
+}
+
+// expire forgets jobs finished earlier than retention before now, q.mu must be held.
+func (q *MemoryQueue) expire(now time.Time) {
+	if q.retention <= 0 {
+		return
+	}
+
+	for id, job := range q.jobs {
+		if job.Finished() && now.Sub(job.FinishedAt) > q.retention {
+			delete(q.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := NewMemoryQueue(1, time.Hour)
+
+	job := walletscreener.NewJob([]walletscreener.Wallet{{Chain: walletscreener.ChainEthereum, Address: "a"}})
+	if err := queue.Enqueue(ctx, job); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if err := queue.Enqueue(ctx, walletscreener.NewJob(nil)); !errors.Is(err, walletscreener.ErrQueueFull) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrQueueFull)
+	}
+
+	if _, err := queue.GetJob(ctx, "unknown"); !errors.Is(err, walletscreener.ErrJobNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrJobNotFound)
+	}
+
+	taken, err := queue.Dequeue(ctx)
+	if err != nil || taken.ID != job.ID {
+		t.Fatalf("got %v %v, want job %s", taken, err, job.ID)
+	}
+
+	// taken job is a copy, state is changed only by update
+	taken.Status = walletscreener.JobStatusRunning
+	if got, _ := queue.GetJob(ctx, job.ID); got.Status != walletscreener.JobStatusQueued {
+		t.Errorf("got %v, want %v", got.Status, walletscreener.JobStatusQueued)
+	}
+
+	if err := queue.UpdateJob(ctx, taken); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if got, _ := queue.GetJob(ctx, job.ID); got.Status != walletscreener.JobStatusRunning {
+		t.Errorf("got %v, want %v", got.Status, walletscreener.JobStatusRunning)
+	}
+
+	if err := queue.Close(); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if err := queue.Enqueue(ctx, walletscreener.NewJob(nil)); !errors.Is(err, walletscreener.ErrQueueClosed) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrQueueClosed)
+	}
+
+	if _, err := queue.Dequeue(ctx); !errors.Is(err, walletscreener.ErrQueueClosed) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrQueueClosed)
+	}
+
+	// finished jobs are kept for status polling after queue is closed
+	if _, err := queue.GetJob(ctx, job.ID); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+}
+
+func TestMemoryQueueRetention(t *testing.T) {
+	ctx := context.Background()
+	queue := NewMemoryQueue(10, time.Minute)
+
+	finished := walletscreener.NewJob(nil)
+	if err := queue.Enqueue(ctx, finished); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	finished.Status, finished.FinishedAt = walletscreener.JobStatusCompleted, time.Now().Add(-2*time.Minute)
+	if err := queue.UpdateJob(ctx, finished); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	pending := walletscreener.NewJob(nil)
+	if err := queue.Enqueue(ctx, pending); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if _, err := queue.GetJob(ctx, finished.ID); !errors.Is(err, walletscreener.ErrJobNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrJobNotFound)
+	}
+
+	if _, err := queue.GetJob(ctx, pending.ID); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+}
@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+)
+
+// Defaults of jobs configuration.
+const (
+	DefaultWorkers      = 2
+	DefaultQueueSize    = 100
+	DefaultRetention    = 24 * time.Hour
+	DefaultDrainTimeout = 30 * time.Second
+)
+
+// Config represents jobs configuration, defaults are used for values not set.
+type Config struct {
+	Workers      int           `mapstructure:"workers"`      // Jobs processed at once
+	QueueSize    int           `mapstructure:"queuesize"`    // Maximum number of pending jobs
+	Retention    time.Duration `mapstructure:"retention"`    // How long finished jobs are kept for status polling
+	DrainTimeout time.Duration `mapstructure:"draintimeout"` // How long pending jobs are taken on shutdown before those not started are canceled
+}
+
+// withDefaults returns copy of the configuration with defaults set for values not set, cfg may be nil.
+func (cfg *Config) withDefaults() Config {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Workers < 1 {
+		c.Workers = DefaultWorkers
+	}
+	if c.QueueSize < 1 {
+		c.QueueSize = DefaultQueueSize
+	}
+	if c.Retention <= 0 {
+		c.Retention = DefaultRetention
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = DefaultDrainTimeout
+	}
+	return c
+}
+
+// NewQueue constructs in-process job queue configured by cfg, cfg may be nil.
+func NewQueue(cfg *Config) *MemoryQueue {
+	c := cfg.withDefaults()
+	return NewMemoryQueue(c.QueueSize, c.Retention)
+}
+
+// Pool processes jobs taken from the queue by a fixed number of workers.
+// Wallets of a job are screened same as a batch, progress is recorded into the queue as each wallet is screened.
+// Job state is kept by the queue only, jobs canceled on shutdown are not resumed once pool is started again.
+type Pool struct {
+	queue  walletscreener.JobQueue
+	screen walletscreener.ScreenWalletFunc
+	batch  *walletscreener.BatchConfig
+	cfg    Config
+	logger log.Logger
+
+	stop     context.Context // canceled once pending jobs are not drained in time, workers stop taking jobs
+	stopTake context.CancelFunc
+	ctx      context.Context // canceled once running jobs are not finished until shutdown context is done
+	cancel   context.CancelFunc
+	canceled int32 // pending jobs canceled, accessed atomically
+	wg       sync.WaitGroup
+}
+
+// NewPool constructs and returns new Pool screening wallets of jobs by screen, cfg and batch may be nil.
+func NewPool(queue walletscreener.JobQueue, screen walletscreener.ScreenWalletFunc, batch *walletscreener.BatchConfig, cfg *Config, logger log.Logger) *Pool {
+	stop, stopTake := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		queue:    queue,
+		screen:   screen,
+		batch:    batch,
+		cfg:      cfg.withDefaults(),
+		logger:   logger,
+		stop:     stop,
+		stopTake: stopTake,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start starts workers processing jobs until Shutdown.
+func (p *Pool) Start() {
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work()
+		}()
+	}
+}
+
+// Shutdown stops queue from accepting new jobs and waits for workers to drain pending jobs.
+// Jobs not started within drain timeout are canceled, running jobs are waited for to finish until ctx is done and canceled then.
+// State of canceled jobs is recorded as such, wallets not screened by then are left without outcome and are not screened later.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	if err := p.queue.Close(); err != nil {
+		return errors.Wrap(err, "failed to close job queue")
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(p.cfg.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// jobs not started yet are canceled
+	p.stopTake()
+
+	select {
+	case <-drained:
+		if canceled := atomic.LoadInt32(&p.canceled); canceled > 0 {
+			return errors.Wrapf(context.DeadlineExceeded, "%d pending jobs were not drained in time", canceled)
+		}
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-drained
+		return errors.Wrap(ctx.Err(), "running jobs were not finished in time")
+	}
+}
+
+// work processes jobs until queue is closed and drained or pool stops taking jobs.
+func (p *Pool) work() {
+	for {
+		if p.stop.Err() != nil {
+			// jobs left in the queue are canceled
+			p.cancelPending()
+			return
+		}
+
+		job, err := p.queue.Dequeue(p.stop)
+		switch {
+		case err == nil:
+			p.run(job)
+		case errors.Is(err, walletscreener.ErrQueueClosed):
+			return
+		case p.stop.Err() != nil:
+			continue
+		default:
+			p.logger.WithError(err).Error("unable to take job from the queue")
+
+			// back off not to spin on a failing queue
+			select {
+			case <-p.stop.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// cancelPending records jobs left in the queue as canceled, queue must be closed.
+func (p *Pool) cancelPending() {
+	for {
+		job, err := p.queue.Dequeue(context.Background())
+		if err != nil {
+			return
+		}
+
+		atomic.AddInt32(&p.canceled, 1)
+
+		job.Status, job.FinishedAt = walletscreener.JobStatusCanceled, time.Now().UTC()
+		if err := p.queue.UpdateJob(context.Background(), job); err != nil {
+			p.logger.WithError(err).Errorf("unable to record job %s as canceled", job.ID)
+		}
+	}
+}
+
+// run screens wallets of the job not having outcome yet recording progress into the queue.
+func (p *Pool) run(job *walletscreener.Job) {
+	// update records snapshot of the job, state is recorded even if pool is canceled
+	update := func() {
+		if err := p.queue.UpdateJob(context.Background(), job); err != nil {
+			p.logger.WithError(err).Errorf("unable to record progress of job %s", job.ID)
+		}
+	}
+
+	job.Status, job.StartedAt = walletscreener.JobStatusRunning, time.Now().UTC()
+	update()
+
+	var (
+		wallets []walletscreener.Wallet
+		index   []int // job wallet index of wallets
+	)
+	for i, v := range job.Wallets {
+		if job.Results[i] == nil {
+			wallets = append(wallets, v)
+			index = append(index, i)
+		}
+	}
+
+	ctx := p.ctx
+	if job.NoCache {
+		ctx = walletscreener.WithCacheBypass(ctx)
+	}
+
+	err := walletscreener.ScreenWalletsEach(ctx, p.screen, p.batch, wallets, func(i int, result *walletscreener.WalletScreening) {
+		// wallets interrupted by cancellation are left without outcome
+		if p.ctx.Err() != nil && errors.Is(result.Err, context.Canceled) {
+			return
+		}
+
+		job.SetResult(index[i], result)
+		update()
+	})
+	if err != nil {
+		p.logger.WithError(err).Errorf("unable to screen wallets of job %s", job.ID)
+	}
+
+	job.Status = walletscreener.JobStatusCompleted
+	if err != nil || job.Completed < len(job.Wallets) {
+		job.Status = walletscreener.JobStatusCanceled
+	}
+
+	job.FinishedAt = time.Now().UTC()
+	update()
+}
@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+)
+
+// screenFunc returns screening of any wallet after delay.
+func screenFunc(delay time.Duration) walletscreener.ScreenWalletFunc {
+	return func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		return &walletscreener.Screening{ID: address, ScreeningResult: walletscreener.ScreeningResult{Chain: chain, Address: address}}, nil
+	}
+}
+
+// newJob returns job screening wallets identified by addresses.
+func newJob(addresses ...string) *walletscreener.Job {
+	var wallets []walletscreener.Wallet
+	for _, v := range addresses {
+		wallets = append(wallets, walletscreener.Wallet{Chain: walletscreener.ChainEthereum, Address: v})
+	}
+	return walletscreener.NewJob(wallets)
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	queue := NewQueue(nil)
+
+	pool := NewPool(queue, screenFunc(0), nil, nil, log.Default())
+	pool.Start()
+
+	job := newJob("a", "b", "c")
+	job.SetResult(1, &walletscreener.WalletScreening{Wallet: job.Wallets[1], Err: walletscreener.ErrAddressNotValid})
+
+	if err := queue.Enqueue(ctx, job); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// jobs enqueued before shutdown are drained
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	got, err := queue.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if got.Status != walletscreener.JobStatusCompleted || got.Completed != 3 || got.StartedAt.IsZero() || got.FinishedAt.IsZero() {
+		t.Errorf("got %+v, want completed job", got)
+	}
+
+	for i, v := range got.Results {
+		switch i {
+		case 1:
+			if !errors.Is(v.Err, walletscreener.ErrAddressNotValid) {
+				t.Errorf("#%d got %v, want %v", i, v.Err, walletscreener.ErrAddressNotValid)
+			}
+		default:
+			if v.Err != nil || v.Screening.ID != job.Wallets[i].Address {
+				t.Errorf("#%d got %+v, want screening %s", i, v, job.Wallets[i].Address)
+			}
+		}
+	}
+}
+
+func TestPoolShutdownTimeout(t *testing.T) {
+	ctx := context.Background()
+	queue := NewQueue(nil)
+
+	pool := NewPool(queue, screenFunc(time.Hour), nil, &Config{Workers: 1, DrainTimeout: 10 * time.Millisecond}, log.Default())
+	pool.Start()
+
+	running, pending := newJob("a", "b"), newJob("c")
+	for _, v := range []*walletscreener.Job{running, pending} {
+		if err := queue.Enqueue(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// jobs not finished until shutdown context is done are canceled leaving wallets not screened without outcome
+	for _, v := range []*walletscreener.Job{running, pending} {
+		got, err := queue.GetJob(ctx, v.ID)
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if got.Status != walletscreener.JobStatusCanceled || got.Completed != 0 || got.FinishedAt.IsZero() {
+			t.Errorf("got %+v, want canceled job", got)
+		}
+	}
+}
+
+func TestPoolShutdownWaitsRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	queue := NewQueue(nil)
+
+	pool := NewPool(queue, screenFunc(50*time.Millisecond), nil, &Config{Workers: 1, DrainTimeout: 10 * time.Millisecond}, log.Default())
+	pool.Start()
+
+	running, pending := newJob("a"), newJob("b")
+	for _, v := range []*walletscreener.Job{running, pending} {
+		if err := queue.Enqueue(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// pending job is not drained in time
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// job running once drain timeout passes is finished
+	var testcases = []struct {
+		job    *walletscreener.Job
+		status walletscreener.JobStatus
+	}{
+		{job: running, status: walletscreener.JobStatusCompleted},
+		{job: pending, status: walletscreener.JobStatusCanceled},
+	}
+
+	for i, tt := range testcases {
+		got, err := queue.GetJob(ctx, tt.job.ID)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		if got.Status != tt.status {
+			t.Errorf("#%d status got %v, want %v", i, got.Status, tt.status)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+
+	"github.com/gorilla/mux"
+)
+
+// SubmitJobRequest represents HTTP request for screening a batch of wallets in background.
+// Request body is laid out same as of ScreenWalletsRequest.
+type SubmitJobRequest struct {
+	ScreenWalletsRequest
+}
+
+// Job constructs a new job screening valid wallets of the request, invalid wallets are given their outcome up front.
+func (r *SubmitJobRequest) Job() *walletscreener.Job {
+	job := walletscreener.NewJob(r.Wallets)
+	job.NoCache = r.NoCache
+
+	for i, err := range r.Errors {
+		if err != nil {
+			job.SetResult(i, &walletscreener.WalletScreening{Wallet: r.Wallets[i], Err: err})
+		}
+	}
+
+	return job
+}
+
+// GetJobRequest represents HTTP request for retrieving a job.
+type GetJobRequest struct {
+	ID string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *GetJobRequest) Validate() error {
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *GetJobRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	*r = GetJobRequest{
+		ID: mux.Vars(req)["id"],
+	}
+	return r.Validate()
+}
+
+// NewJobResponse constructs a new response describing job for SubmitJobRequest and GetJobRequest.
+// Outcome of each wallet is reported along HTTP status code given by status once job is finished.
+func NewJobResponse(job *walletscreener.Job, status func(err error) int) *JobResponse {
+	response := JobResponse{
+		ID:        job.ID,
+		Status:    job.Status,
+		Total:     len(job.Wallets),
+		Completed: job.Completed,
+		CreatedAt: job.CreatedAt,
+	}
+
+	if !job.StartedAt.IsZero() {
+		response.StartedAt = &job.StartedAt
+	}
+
+	if !job.Finished() {
+		return &response
+	}
+
+	response.FinishedAt = &job.FinishedAt
+
+	// wallets left without outcome by canceled jobs are omitted
+	response.Results = []*WalletScreeningResult{}
+	for _, v := range job.Results {
+		if v != nil {
+			response.Results = append(response.Results, newWalletScreeningResult(v, status))
+		}
+	}
+
+	return &response
+}
+
+// JobResponse represents a response describing a job.
+type JobResponse struct {
+	ID         string                   `json:"id"`
+	Status     walletscreener.JobStatus `json:"status"`
+	Total      int                      `json:"total"`     // number of wallets of the job
+	Completed  int                      `json:"completed"` // number of wallets having outcome
+	CreatedAt  time.Time                `json:"created_at"`
+	StartedAt  *time.Time               `json:"started_at,omitempty"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+	Results    []*WalletScreeningResult `json:"results,omitempty"` // in the order of submitted wallets, once job is finished
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *JobResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ClientMetrics represents counters of API requests made to a risk provider.
+type ClientMetrics struct {
+	Requests uint64 `json:"requests"` // requests issued by the service
+	Attempts uint64 `json:"attempts"` // attempts made including retries
+	Retries  uint64 `json:"retries"`  // attempts made after a failed attempt
+	Failures uint64 `json:"failures"` // requests failed after all attempts
+}
+
+// ProviderMetricsResponse represents a response describing API requests made to risk providers.
+type ProviderMetricsResponse struct {
+	Providers map[string]*ClientMetrics `json:"providers"` // by provider name
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ProviderMetricsResponse) MarshalHTTP(w http.ResponseWriter) error {
+	if r.Providers == nil {
+		r.Providers = map[string]*ClientMetrics{}
+	}
+
+	return json.NewEncoder(w).Encode(r)
+}
@@ -3,51 +3,254 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/errors"
 	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/validator"
 
 	"github.com/gorilla/mux"
 )
 
 // API errors
 var (
-	ErrAddressNotValid = errors.New("given address is not valid wallet address")
+	ErrAddressNotValid   = errors.New("given address is not valid wallet address")
+	ErrParameterNotValid = errors.New("given query parameter is not valid")
+	ErrBodyNotValid      = errors.New("given request body is not valid")
 )
 
-// Ethereum hexadecimal address is derived from the last 20 bytes
-// of the public key controlling the account with 0x appended in front.
-// e.g., 0x71C7656EC7ab88b098defB751B7401B5f6d8976F
-const ethWalletAddressLength = 42
+// addressValidators maps supported chains to their address validators.
+var addressValidators = map[walletscreener.Chain]func(address string) error{
+	walletscreener.ChainEthereum: validator.EVMAddress,
+	walletscreener.ChainPolygon:  validator.EVMAddress,
+	walletscreener.ChainBitcoin:  validator.BitcoinAddress,
+	walletscreener.ChainTron:     validator.TronAddress,
+	walletscreener.ChainSolana:   validator.SolanaAddress,
+}
+
+// addressNormalizers maps chains whose addresses may be given in several forms to functions returning their canonical form.
+// Addresses of other chains are case sensitive and used as given.
+var addressNormalizers = map[walletscreener.Chain]func(address string) string{
+	walletscreener.ChainEthereum: validator.NormalizeEVMAddress,
+	walletscreener.ChainPolygon:  validator.NormalizeEVMAddress,
+	walletscreener.ChainBitcoin:  validator.NormalizeBitcoinAddress,
+}
+
+// validateAddress validates address format for the given chain and returns address in its canonical form,
+// so that the same wallet is stored, cached and deduplicated under the same address regardless of form it was given in.
+// Returned error wraps ErrAddressNotValid along the reason of rejection, address is returned as given then.
+func validateAddress(chain walletscreener.Chain, address string) (string, error) {
+	validate, ok := addressValidators[chain]
+	if !ok {
+		return address, errors.Wrapf(walletscreener.ErrChainNotSupported, "chain %s", chain)
+	}
+
+	if err := validate(address); err != nil {
+		return address, errors.WithReason(ErrAddressNotValid, err)
+	}
+
+	if normalize, ok := addressNormalizers[chain]; ok {
+		return normalize(address), nil
+	}
+
+	return address, nil
+}
+
+// ErrorResponse represents a response describing why request has failed.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewErrorResponse constructs a new ErrorResponse from err.
+// Screenings failing verification are reported as such without details of the failed proof.
+func NewErrorResponse(err error) *ErrorResponse {
+	if errors.Is(err, walletscreener.ErrScreeningTampered) {
+		return &ErrorResponse{
+			Error: walletscreener.ErrScreeningTampered.Error(),
+		}
+	}
+
+	return &ErrorResponse{
+		Error: err.Error(),
+	}
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ErrorResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// defaultChain is a chain used when request does not specify one.
+// Routes without {chain} segment are kept for backward compatibility and screen Ethereum wallets.
+const defaultChain = walletscreener.ChainEthereum
+
+// parseChain parses chain identifier from request route variables falling back to defaultChain.
+func parseChain(vars map[string]string) (walletscreener.Chain, error) {
+	chain, ok := vars["chain"]
+	if !ok {
+		return defaultChain, nil
+	}
+	return walletscreener.ParseChain(chain)
+}
+
+// noCache returns whether request asks to bypass cached results either by nocache query parameter or Cache-Control header.
+func noCache(req *http.Request) bool {
+	if v, err := strconv.ParseBool(req.URL.Query().Get("nocache")); err == nil && v {
+		return true
+	}
+
+	for _, directive := range strings.Split(req.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+
+	return false
+}
 
 // ScreenWalletRiskCategoriesRequest represents HTTP request for screening a wallet for risk categories.
 type ScreenWalletRiskCategoriesRequest struct {
+	Chain   walletscreener.Chain
 	Address string
+	NoCache bool // Whether wallet must be screened instead of serving cached result
 }
 
 // Validate parses request fields and returns whether they contain valid data.
 // Validate implements validator.Validator.
-// TODO: implement more sophisticated rule
 func (r *ScreenWalletRiskCategoriesRequest) Validate() error {
-	if len(r.Address) < ethWalletAddressLength {
-		return ErrAddressNotValid
-	}
-	return nil
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
 }
 
 // UnmarshalHTTP implements http.RequestUnmarshaler.
 func (r *ScreenWalletRiskCategoriesRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
 	*r = ScreenWalletRiskCategoriesRequest{
-		Address: mux.Vars(req)["address"],
+		Chain:   chain,
+		Address: vars["address"],
+		NoCache: noCache(req),
 	}
 	log.Println("address", req.URL)
 	return r.Validate()
 }
 
+// RiskCategory represents a risk category a wallet or its source of funds is associated with.
+type RiskCategory struct {
+	Name      string   `json:"name"`
+	Entity    string   `json:"entity,omitempty"`
+	Risk      int      `json:"risk"`
+	Providers []string `json:"providers,omitempty"`
+}
+
+// RiskChange represents change of wallet risk since its previous screening.
+type RiskChange struct {
+	Changed           bool                   `json:"changed"` // whether categories or risk score have changed
+	PreviousID        string                 `json:"previous_id"`
+	PreviousVerdict   walletscreener.Verdict `json:"previous_verdict,omitempty"`
+	AddedCategories   []string               `json:"added_categories"`
+	RemovedCategories []string               `json:"removed_categories"`
+	RiskDelta         int                    `json:"risk_delta"`
+}
+
+// newRiskChange converts walletscreener.RiskChange into RiskChange, nil change is kept nil.
+func newRiskChange(change *walletscreener.RiskChange) *RiskChange {
+	if change == nil {
+		return nil
+	}
+
+	result := RiskChange{
+		Changed:           change.Changed(),
+		PreviousID:        change.PreviousID,
+		PreviousVerdict:   change.PreviousVerdict,
+		AddedCategories:   change.AddedCategories,
+		RemovedCategories: change.RemovedCategories,
+		RiskDelta:         change.RiskDelta,
+	}
+
+	if result.AddedCategories == nil {
+		result.AddedCategories = []string{}
+	}
+
+	if result.RemovedCategories == nil {
+		result.RemovedCategories = []string{}
+	}
+
+	return &result
+}
+
+// newRiskCategories converts walletscreener.RiskCategory into RiskCategory.
+func newRiskCategories(categories []*walletscreener.RiskCategory) []*RiskCategory {
+	result := []*RiskCategory{}
+	for _, v := range categories {
+		result = append(result, &RiskCategory{
+			Name:      v.Name,
+			Entity:    v.Entity,
+			Risk:      v.Risk,
+			Providers: v.Providers,
+		})
+	}
+	return result
+}
+
+// NewScreenWalletRiskCategoriesResponse constructs a new response for ScreenWalletRiskCategoriesRequest.
+func NewScreenWalletRiskCategoriesResponse(screening *walletscreener.Screening) *ScreenWalletRiskCategoriesResponse {
+	response := ScreenWalletRiskCategoriesResponse{
+		ID:                      screening.ID,
+		Verified:                screening.Verified,
+		Provider:                screening.Provider,
+		Categories:              screening.Categories(),
+		Risk:                    screening.Risk,
+		Entity:                  screening.Entity,
+		OwnCategories:           newRiskCategories(screening.OwnCategories),
+		SourceOfFundsCategories: newRiskCategories(screening.SourceOfFundsCategories),
+		CaseID:                  screening.CaseID,
+		RequestedAt:             screening.RequestedAt,
+		RespondedAt:             screening.RespondedAt,
+		Cached:                  screening.Cached,
+		Verdict:                 screening.Verdict,
+		MatchedRules:            screening.MatchedRules,
+		Change:                  newRiskChange(screening.Change),
+	}
+
+	// screenings not stored have no time of recording
+	if screening.ID != "" {
+		response.ScreenedAt = &screening.ScreenedAt
+	}
+
+	return &response
+}
+
 // ScreenWalletRiskCategoriesResponse represents a response for ScreenWalletRiskCategoriesRequest.
 type ScreenWalletRiskCategoriesResponse struct {
-	Categories []string `json:"categories"`
+	ID                      string          `json:"id,omitempty"`
+	ScreenedAt              *time.Time      `json:"screened_at,omitempty"`
+	Verified                bool            `json:"verified"`
+	Provider                string          `json:"provider,omitempty"`
+	Categories              []string        `json:"categories"`
+	Risk                    int             `json:"risk"`
+	Entity                  string          `json:"entity,omitempty"`
+	OwnCategories           []*RiskCategory `json:"own_categories"`
+	SourceOfFundsCategories []*RiskCategory `json:"source_of_funds_categories"`
+	CaseID                  string          `json:"case_id,omitempty"`
+	RequestedAt             time.Time       `json:"requested_at"`
+	RespondedAt             time.Time       `json:"responded_at"`
+	Cached                  bool            `json:"cached"`
+
+	Verdict      walletscreener.Verdict `json:"verdict,omitempty"`
+	MatchedRules []string               `json:"matched_rules"`
+
+	Change *RiskChange `json:"change,omitempty"` // change since the previous screening, absent for the first one
 }
 
 // MarshalHTTP implements http.Marshaler.
@@ -56,64 +259,430 @@ func (r *ScreenWalletRiskCategoriesResponse) MarshalHTTP(w http.ResponseWriter)
 		r.Categories = []string{}
 	}
 
+	if r.MatchedRules == nil {
+		r.MatchedRules = []string{}
+	}
+
+	return json.NewEncoder(w).Encode(r)
+}
+
+// ScreenWalletsRequest represents HTTP request for screening a batch of wallets for risk categories.
+type ScreenWalletsRequest struct {
+	Wallets []walletscreener.Wallet
+	Errors  []error // Validation errors of wallets by their index, nil for valid wallets
+	NoCache bool    // Whether wallets must be screened instead of serving cached results
+
+	// MaxWallets is set before unmarshaling to limit request body to the size of that many wallets,
+	// walletscreener.DefaultBatchMaxWallets if not set.
+	MaxWallets int
+}
+
+// maxWalletBytes is an upper bound of JSON encoded batch wallet, addresses of supported chains are far shorter.
+const maxWalletBytes = 256
+
+// bodyLimit returns maximum size of request body in bytes.
+func (r *ScreenWalletsRequest) bodyLimit() int64 {
+	maxWallets := r.MaxWallets
+	if maxWallets <= 0 {
+		maxWallets = walletscreener.DefaultBatchMaxWallets
+	}
+	return int64(maxWallets+1) * maxWalletBytes
+}
+
+// batchWallet represents a single wallet of ScreenWalletsRequest body.
+type batchWallet struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Wallets are validated one by one, invalid wallets do not fail the request and are reported in Errors instead.
+// Validate implements validator.Validator.
+func (r *ScreenWalletsRequest) Validate() error {
+	if len(r.Wallets) < 1 {
+		return errors.WithReason(ErrBodyNotValid, errors.New("batch has no wallets"))
+	}
+
+	r.Errors = make([]error, len(r.Wallets))
+	for i, v := range r.Wallets {
+		r.Wallets[i].Address, r.Errors[i] = validateAddress(v.Chain, v.Address)
+	}
+
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+// Request body is a JSON array of wallets, e.g. [{"chain":"btc","address":"bc1q..."}], chain defaults to defaultChain.
+// Body larger than MaxWallets wallets may take is rejected before it is read whole.
+func (r *ScreenWalletsRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	limit := r.bodyLimit()
+
+	var wallets []batchWallet
+	if err := json.NewDecoder(http.MaxBytesReader(nil, req.Body, limit)).Decode(&wallets); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errors.WithReason(ErrBodyNotValid, errors.Wrapf(walletscreener.ErrBatchTooLarge, "body exceeds %d bytes", limit))
+		}
+		return errors.WithReason(ErrBodyNotValid, err)
+	}
+
+	*r = ScreenWalletsRequest{
+		NoCache:    noCache(req),
+		MaxWallets: r.MaxWallets,
+	}
+
+	for _, v := range wallets {
+		chain := defaultChain
+		if v.Chain != "" {
+			chain = walletscreener.Chain(v.Chain)
+		}
+
+		r.Wallets = append(r.Wallets, walletscreener.Wallet{
+			Chain:   chain,
+			Address: v.Address,
+		})
+	}
+
+	return r.Validate()
+}
+
+// NewScreenWalletsResponse constructs a new response for ScreenWalletsRequest.
+// Outcome of each wallet is reported along HTTP status code given by status, as if wallet was screened alone.
+func NewScreenWalletsResponse(results []*walletscreener.WalletScreening, status func(err error) int) *ScreenWalletsResponse {
+	var response ScreenWalletsResponse
+	for _, v := range results {
+		response.Results = append(response.Results, newWalletScreeningResult(v, status))
+	}
+	return &response
+}
+
+// newWalletScreeningResult converts walletscreener.WalletScreening into WalletScreeningResult.
+func newWalletScreeningResult(v *walletscreener.WalletScreening, status func(err error) int) *WalletScreeningResult {
+	result := WalletScreeningResult{
+		Chain:   v.Chain,
+		Address: v.Address,
+		Status:  http.StatusOK,
+	}
+
+	switch {
+	case v.Err != nil:
+		result.Status = status(v.Err)
+
+		// details of internal errors are not exposed same as for a single wallet
+		result.Error = http.StatusText(result.Status)
+		if result.Status < http.StatusInternalServerError {
+			result.Error = v.Err.Error()
+		}
+	default:
+		result.Screening = NewScreenWalletRiskCategoriesResponse(v.Screening)
+		if result.Screening.Categories == nil {
+			result.Screening.Categories = []string{}
+		}
+		if result.Screening.MatchedRules == nil {
+			result.Screening.MatchedRules = []string{}
+		}
+	}
+
+	return &result
+}
+
+// ScreenWalletsResponse represents a response for ScreenWalletsRequest.
+type ScreenWalletsResponse struct {
+	Results []*WalletScreeningResult `json:"results"` // in the order of requested wallets
+}
+
+// WalletScreeningResult represents outcome of screening a single wallet of ScreenWalletsRequest.
+type WalletScreeningResult struct {
+	Chain     walletscreener.Chain                `json:"chain"`
+	Address   string                              `json:"address"`
+	Status    int                                 `json:"status"`
+	Error     string                              `json:"error,omitempty"`
+	Screening *ScreenWalletRiskCategoriesResponse `json:"screening,omitempty"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ScreenWalletsResponse) MarshalHTTP(w http.ResponseWriter) error {
+	if r.Results == nil {
+		r.Results = []*WalletScreeningResult{}
+	}
+
 	return json.NewEncoder(w).Encode(r)
 }
 
 // GetWalletRiskCategoriesHistory represents HTTP request for retrieving historical risk categories for a wallet.
 type GetWalletRiskCategoriesHistoryRequest struct {
-	Address string
+	Chain      walletscreener.Chain
+	Address    string
+	Cursor     string    // Cursor of the page as returned by the previous page
+	Since      time.Time // Screenings recorded at or after, RFC 3339
+	Until      time.Time // Screenings recorded before, RFC 3339
+	Descending bool      // Whether the most recent screenings come first, ordered by order=asc|desc
+	Limit      int       // Maximum number of screenings
 }
 
 // Validate parses request fields and returns whether they contain valid data.
 // Validate implements validator.Validator.
-// TODO: implement more sophisticated rule
 func (r *GetWalletRiskCategoriesHistoryRequest) Validate() error {
-	if len(r.Address) < ethWalletAddressLength {
-		return ErrAddressNotValid
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Since.Before(r.Until) {
+		return errors.WithReason(ErrParameterNotValid, errors.New("since must be before until"))
 	}
-	return nil
+
+	if r.Limit < 0 {
+		return errors.WithReason(ErrParameterNotValid, errors.New("limit must not be negative"))
+	}
+
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
 }
 
 // UnmarshalHTTP implements http.RequestUnmarshaler.
 func (r *GetWalletRiskCategoriesHistoryRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+	query := req.URL.Query()
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
 	*r = GetWalletRiskCategoriesHistoryRequest{
-		Address: mux.Vars(req)["address"],
+		Chain:   chain,
+		Address: vars["address"],
+		Cursor:  query.Get("cursor"),
+	}
+
+	if r.Since, err = parseTime(query, "since"); err != nil {
+		return err
+	}
+
+	if r.Until, err = parseTime(query, "until"); err != nil {
+		return err
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		r.Descending = true
+	default:
+		return errors.WithReason(ErrParameterNotValid, errors.Newf("order %q is neither asc nor desc", order))
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if r.Limit, err = strconv.Atoi(limit); err != nil {
+			return errors.WithReason(ErrParameterNotValid, errors.Newf("limit %q is not a number", limit))
+		}
 	}
+
 	return r.Validate()
 }
 
-// HistoricalRiskCategory represents wallet historical risk category entity.
-type HistoricalRiskCategory struct {
-	Category string `json:"category"`
-	Revision uint64 `json:"revision"`
+// parseTime parses RFC 3339 time from the named query parameter, zero time is returned if parameter is not set.
+func parseTime(query url.Values, name string) (time.Time, error) {
+	v := query.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.WithReason(ErrParameterNotValid, errors.Newf("%s %q is not RFC 3339 time", name, v))
+	}
+
+	return t, nil
+}
+
+// Screening represents a single recorded screening of a wallet.
+type Screening struct {
+	ID                      string                 `json:"id"`
+	Revision                uint64                 `json:"revision"`
+	ScreenedAt              time.Time              `json:"screened_at"`
+	Verified                bool                   `json:"verified"`
+	Chain                   walletscreener.Chain   `json:"chain"`
+	Address                 string                 `json:"address"`
+	Provider                string                 `json:"provider,omitempty"`
+	Categories              []string               `json:"categories"`
+	Risk                    int                    `json:"risk"`
+	Entity                  string                 `json:"entity,omitempty"`
+	OwnCategories           []*RiskCategory        `json:"own_categories"`
+	SourceOfFundsCategories []*RiskCategory        `json:"source_of_funds_categories"`
+	CaseID                  string                 `json:"case_id,omitempty"`
+	RequestedAt             time.Time              `json:"requested_at"`
+	RespondedAt             time.Time              `json:"responded_at"`
+	Verdict                 walletscreener.Verdict `json:"verdict,omitempty"`
+	MatchedRules            []string               `json:"matched_rules"`
+	Change                  *RiskChange            `json:"change,omitempty"` // change since the previous screening, absent for the first one
+}
+
+// newScreening converts walletscreener.Screening into Screening.
+func newScreening(screening *walletscreener.Screening) *Screening {
+	result := Screening{
+		ID:                      screening.ID,
+		Revision:                screening.Revision,
+		ScreenedAt:              screening.ScreenedAt,
+		Verified:                screening.Verified,
+		Chain:                   screening.Chain,
+		Address:                 screening.Address,
+		Provider:                screening.Provider,
+		Categories:              screening.Categories(),
+		Risk:                    screening.Risk,
+		Entity:                  screening.Entity,
+		OwnCategories:           newRiskCategories(screening.OwnCategories),
+		SourceOfFundsCategories: newRiskCategories(screening.SourceOfFundsCategories),
+		CaseID:                  screening.CaseID,
+		RequestedAt:             screening.RequestedAt,
+		RespondedAt:             screening.RespondedAt,
+		Verdict:                 screening.Verdict,
+		MatchedRules:            screening.MatchedRules,
+		Change:                  newRiskChange(screening.Change),
+	}
+
+	if result.Categories == nil {
+		result.Categories = []string{}
+	}
+
+	if result.MatchedRules == nil {
+		result.MatchedRules = []string{}
+	}
+
+	return &result
 }
 
 // NewGetWalletRiskCategoriesHistoryRespone constructs a new response for GetWalletRiskCategoriesHistoryRequest.
-func NewGetWalletRiskCategoriesHistoryRespone(categories []*walletscreener.HistoricalRiskCategory) *GetWalletRiskCategoriesHistoryRespone {
+func NewGetWalletRiskCategoriesHistoryRespone(page *walletscreener.ScreeningsPage) *GetWalletRiskCategoriesHistoryRespone {
 	return &GetWalletRiskCategoriesHistoryRespone{
-		input: categories,
+		input: page.Screenings,
+		Next:  page.Next,
 	}
 }
 
 // GetWalletRiskCategoriesHistoryRespone represents a response for GetWalletRiskCategoriesHistoryRequest.
 type GetWalletRiskCategoriesHistoryRespone struct {
-	input []*walletscreener.HistoricalRiskCategory // state
+	input []*walletscreener.Screening // state
 
-	Categories []*HistoricalRiskCategory `json:"categories"`
+	Screenings []*Screening `json:"screenings"`
+	Next       string       `json:"next,omitempty"` // cursor of the next page, omitted on the last page
 }
 
 // MarshalHTTP implements http.Marshaler.
 func (r *GetWalletRiskCategoriesHistoryRespone) MarshalHTTP(w http.ResponseWriter) error {
 	for _, v := range r.input {
-		r.Categories = append(r.Categories, &HistoricalRiskCategory{
-			Category: v.Category,
-			Revision: v.Revision,
-		})
+		r.Screenings = append(r.Screenings, newScreening(v))
 	}
 
-	if r.Categories == nil {
-		r.Categories = []*HistoricalRiskCategory{}
+	if r.Screenings == nil {
+		r.Screenings = []*Screening{}
+	}
+
+	return json.NewEncoder(w).Encode(r)
+}
+
+// GetLatestScreeningRequest represents HTTP request for retrieving the most recently stored screening of a wallet.
+type GetLatestScreeningRequest struct {
+	Chain   walletscreener.Chain
+	Address string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *GetLatestScreeningRequest) Validate() error {
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *GetLatestScreeningRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
+	*r = GetLatestScreeningRequest{
+		Chain:   chain,
+		Address: vars["address"],
+	}
+	return r.Validate()
+}
+
+// NewGetLatestScreeningResponse constructs a new response for GetLatestScreeningRequest with screening age at the given time.
+func NewGetLatestScreeningResponse(screening *walletscreener.Screening, now time.Time) *GetLatestScreeningResponse {
+	response := GetLatestScreeningResponse{
+		Screening: newScreening(screening),
+	}
+
+	// screenings stored by the earliest versions have no time recorded
+	if !screening.ScreenedAt.IsZero() {
+		age := int64(screening.Age(now).Seconds())
+		response.AgeSeconds = &age
+	}
+
+	return &response
+}
+
+// GetLatestScreeningResponse represents a response for GetLatestScreeningRequest.
+type GetLatestScreeningResponse struct {
+	Screening  *Screening `json:"screening"`
+	AgeSeconds *int64     `json:"age_seconds,omitempty"` // whole seconds elapsed since screening was recorded
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *GetLatestScreeningResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// GetScreeningProofRequest represents HTTP request for retrieving proof of a stored screening of a wallet.
+type GetScreeningProofRequest struct {
+	Chain   walletscreener.Chain
+	Address string
+	ID      string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *GetScreeningProofRequest) Validate() error {
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *GetScreeningProofRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
+	*r = GetScreeningProofRequest{
+		Chain:   chain,
+		Address: vars["address"],
+		ID:      vars["id"],
+	}
+	return r.Validate()
+}
+
+// NewGetScreeningProofResponse constructs a new response for GetScreeningProofRequest.
+func NewGetScreeningProofResponse(proof *walletscreener.ScreeningProof) *GetScreeningProofResponse {
+	return &GetScreeningProofResponse{
+		Format:    proof.Format,
+		Screening: newScreening(proof.Screening),
+		Proof:     proof.Proof,
 	}
+}
 
+// GetScreeningProofResponse represents a response for GetScreeningProofRequest.
+// Proof material is laid out as defined by its format, screening is provided for convenience and is not part of the proof.
+type GetScreeningProofResponse struct {
+	Format    string          `json:"format"`
+	Screening *Screening      `json:"screening"`
+	Proof     json.RawMessage `json:"proof"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *GetScreeningProofResponse) MarshalHTTP(w http.ResponseWriter) error {
 	return json.NewEncoder(w).Encode(r)
 }
@@ -1,29 +1,108 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
 	"github.com/deividaspetraitis/wallet-screener/validator"
+
+	"github.com/gorilla/mux"
 )
 
 func TestScreenWalletRiskCategoriesRequest(t *testing.T) {
 	var testcases = []struct {
-		Address string
-		Error   error
+		Chain      walletscreener.Chain
+		Address    string
+		Normalized string
+		Error      error
 	}{
-		{"", ErrAddressNotValid},
-		{"0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil},
+		{walletscreener.ChainEthereum, "", "", ErrAddressNotValid},
+		{walletscreener.ChainEthereum, "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil},
+		{walletscreener.ChainEthereum, "0x4e9ce36e442e55ecd9025b9a6e0d88485d628a67", "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil},
+		{walletscreener.ChainEthereum, "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A6Z", "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A6Z", ErrAddressNotValid},
+		{walletscreener.ChainPolygon, "0x4E9CE36E442E55ECD9025B9A6E0D88485D628A67", "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", nil},
+		{walletscreener.ChainBitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", nil},
+		{walletscreener.ChainBitcoin, "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", nil},
+		{walletscreener.ChainBitcoin, "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67", ErrAddressNotValid},
+		{walletscreener.ChainTron, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", nil},
+		{walletscreener.ChainSolana, "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV", "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV", nil},
+		{walletscreener.Chain("doge"), "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L", "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L", walletscreener.ErrChainNotSupported},
 	}
 
 	for _, v := range testcases {
 		req := ScreenWalletRiskCategoriesRequest{
+			Chain:   v.Chain,
 			Address: v.Address,
 		}
 
 		err := validator.Validate(&req)
-		if err != v.Error {
+		if !errors.Is(err, v.Error) {
 			t.Errorf("got %v, want %v", err, v.Error)
 		}
+
+		if req.Address != v.Normalized {
+			t.Errorf("got %v, want %v", req.Address, v.Normalized)
+		}
+	}
+
+}
+
+func TestScreenWalletRiskCategoriesRequestNoCache(t *testing.T) {
+	var testcases = []struct {
+		target  string
+		header  http.Header
+		noCache bool
+	}{
+		{"/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories", nil, false},
+		{"/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories?nocache=true", nil, true},
+		{"/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories?nocache=false", nil, false},
+		{"/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories", http.Header{"Cache-Control": []string{"max-age=0, no-cache"}}, true},
+	}
+
+	for _, v := range testcases {
+		req := httptest.NewRequest(http.MethodPost, v.target, nil)
+		req.Header = v.header
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+		req = mux.SetURLVars(req, map[string]string{"address": "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"})
+
+		var request ScreenWalletRiskCategoriesRequest
+		if err := request.UnmarshalHTTPRequest(req); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if request.NoCache != v.noCache {
+			t.Errorf("%s got %v, want %v", v.target, request.NoCache, v.noCache)
+		}
 	}
+}
 
+func TestGetWalletRiskCategoriesHistoryRequest(t *testing.T) {
+	var testcases = []struct {
+		query string
+		err   error
+	}{
+		{"", nil},
+		{"?cursor=5&order=asc&limit=10", nil},
+		{"?since=2023-10-04T00:00:00Z&until=2023-10-05T00:00:00%2B02:00&order=desc", nil},
+		{"?since=2023-10-04", ErrParameterNotValid},
+		{"?since=2023-10-05T00:00:00Z&until=2023-10-04T00:00:00Z", ErrParameterNotValid},
+		{"?order=latest", ErrParameterNotValid},
+		{"?limit=ten", ErrParameterNotValid},
+		{"?limit=-1", ErrParameterNotValid},
+	}
+
+	for _, v := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "/wallet/0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67/categories"+v.query, nil)
+		req = mux.SetURLVars(req, map[string]string{"address": "0x4E9ce36E442e55EcD9025B9a6E0D88485d628A67"})
+
+		var request GetWalletRiskCategoriesHistoryRequest
+		if err := request.UnmarshalHTTPRequest(req); !errors.Is(err, v.err) {
+			t.Errorf("%s got %v, want %v", v.query, err, v.err)
+		}
+	}
 }
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// WatchWalletRequest represents HTTP request for re-screening a wallet periodically.
+type WatchWalletRequest struct {
+	Chain    walletscreener.Chain
+	Address  string
+	Interval time.Duration // How often wallet is re-screened, given as duration in request body, e.g. {"interval":"24h"}
+}
+
+// watchWalletBody represents body of WatchWalletRequest.
+type watchWalletBody struct {
+	Interval string `json:"interval"`
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *WatchWalletRequest) Validate() error {
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *WatchWalletRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
+	var body watchWalletBody
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return errors.WithReason(ErrBodyNotValid, err)
+	}
+
+	interval, err := time.ParseDuration(body.Interval)
+	if err != nil {
+		return errors.WithReason(ErrBodyNotValid, err)
+	}
+
+	*r = WatchWalletRequest{
+		Chain:    chain,
+		Address:  vars["address"],
+		Interval: interval,
+	}
+	return r.Validate()
+}
+
+// GetWatchRequest represents HTTP request for retrieving watch of a wallet.
+type GetWatchRequest struct {
+	Chain   walletscreener.Chain
+	Address string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *GetWatchRequest) Validate() error {
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *GetWatchRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
+	*r = GetWatchRequest{
+		Chain:   chain,
+		Address: vars["address"],
+	}
+	return r.Validate()
+}
+
+// UnwatchWalletRequest represents HTTP request for no longer re-screening a wallet.
+type UnwatchWalletRequest struct {
+	Chain   walletscreener.Chain
+	Address string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *UnwatchWalletRequest) Validate() error {
+	var err error
+	r.Address, err = validateAddress(r.Chain, r.Address)
+	return err
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *UnwatchWalletRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	chain, err := parseChain(vars)
+	if err != nil {
+		return err
+	}
+
+	*r = UnwatchWalletRequest{
+		Chain:   chain,
+		Address: vars["address"],
+	}
+	return r.Validate()
+}
+
+// NewWatchResponse constructs a new response describing watch for WatchWalletRequest and GetWatchRequest.
+func NewWatchResponse(watch *walletscreener.Watch) *WatchResponse {
+	response := WatchResponse{
+		Chain:     watch.Chain,
+		Address:   watch.Address,
+		Interval:  watch.Interval.String(),
+		CreatedAt: watch.CreatedAt,
+		NextAt:    watch.NextAt,
+		LastError: watch.LastError,
+	}
+
+	if !watch.LastScreenedAt.IsZero() {
+		response.LastScreenedAt = &watch.LastScreenedAt
+	}
+
+	return &response
+}
+
+// WatchResponse represents a response describing a watched wallet.
+type WatchResponse struct {
+	Chain          walletscreener.Chain `json:"chain"`
+	Address        string               `json:"address"`
+	Interval       string               `json:"interval"`
+	CreatedAt      time.Time            `json:"created_at"`
+	NextAt         time.Time            `json:"next_at"`                    // when wallet is due to be re-screened
+	LastScreenedAt *time.Time           `json:"last_screened_at,omitempty"` // until wallet is re-screened for the first time
+	LastError      string               `json:"last_error,omitempty"`       // why the last re-screening failed
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *WatchResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// NewListWatchesResponse constructs a new response listing watched wallets.
+func NewListWatchesResponse(watches []*walletscreener.Watch) *ListWatchesResponse {
+	response := ListWatchesResponse{
+		Watches: []*WatchResponse{},
+	}
+	for _, v := range watches {
+		response.Watches = append(response.Watches, NewWatchResponse(v))
+	}
+	return &response
+}
+
+// ListWatchesResponse represents a response listing watched wallets.
+type ListWatchesResponse struct {
+	Watches []*WatchResponse `json:"watches"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ListWatchesResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateSubscriptionRequest represents HTTP request for subscribing an endpoint to screening events.
+// Request body is laid out as {"url":"https://example.com/hook","secret":"...","events":["screening.blocked"]},
+// secret is generated and events default to every event if they are not given.
+type CreateSubscriptionRequest struct {
+	URL    string
+	Secret string
+	Events []walletscreener.WebhookEvent
+}
+
+// createSubscriptionBody represents body of CreateSubscriptionRequest.
+type createSubscriptionBody struct {
+	URL    string                        `json:"url"`
+	Secret string                        `json:"secret"`
+	Events []walletscreener.WebhookEvent `json:"events"`
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *CreateSubscriptionRequest) Validate() error {
+	if r.URL == "" {
+		return errors.WithReason(ErrBodyNotValid, errors.New("url is required"))
+	}
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *CreateSubscriptionRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	var body createSubscriptionBody
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return errors.WithReason(ErrBodyNotValid, err)
+	}
+
+	*r = CreateSubscriptionRequest(body)
+	return r.Validate()
+}
+
+// GetSubscriptionRequest represents HTTP request for retrieving a webhook subscription.
+type GetSubscriptionRequest struct {
+	ID string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *GetSubscriptionRequest) Validate() error {
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *GetSubscriptionRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	*r = GetSubscriptionRequest{
+		ID: mux.Vars(req)["id"],
+	}
+	return r.Validate()
+}
+
+// DeleteSubscriptionRequest represents HTTP request for deleting a webhook subscription.
+type DeleteSubscriptionRequest struct {
+	ID string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *DeleteSubscriptionRequest) Validate() error {
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *DeleteSubscriptionRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	*r = DeleteSubscriptionRequest{
+		ID: mux.Vars(req)["id"],
+	}
+	return r.Validate()
+}
+
+// NewSubscriptionResponse constructs a new response describing subscription for GetSubscriptionRequest.
+// Secret of the subscription is not disclosed.
+func NewSubscriptionResponse(subscription *walletscreener.Subscription) *SubscriptionResponse {
+	response := SubscriptionResponse{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Events:    subscription.Events,
+		CreatedAt: subscription.CreatedAt,
+	}
+
+	if response.Events == nil {
+		response.Events = []walletscreener.WebhookEvent{}
+	}
+
+	return &response
+}
+
+// NewCreateSubscriptionResponse constructs a new response for CreateSubscriptionRequest.
+// Secret of the subscription is disclosed once, when subscription is created.
+func NewCreateSubscriptionResponse(subscription *walletscreener.Subscription) *SubscriptionResponse {
+	response := NewSubscriptionResponse(subscription)
+	response.Secret = subscription.Secret
+	return response
+}
+
+// SubscriptionResponse represents a response describing a webhook subscription.
+type SubscriptionResponse struct {
+	ID        string                        `json:"id"`
+	URL       string                        `json:"url"`
+	Secret    string                        `json:"secret,omitempty"` // disclosed only when subscription is created
+	Events    []walletscreener.WebhookEvent `json:"events"`           // empty when subscribed to every event
+	CreatedAt time.Time                     `json:"created_at"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *SubscriptionResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// NewListSubscriptionsResponse constructs a new response listing webhook subscriptions.
+func NewListSubscriptionsResponse(subscriptions []*walletscreener.Subscription) *ListSubscriptionsResponse {
+	response := ListSubscriptionsResponse{
+		Webhooks: []*SubscriptionResponse{},
+	}
+	for _, v := range subscriptions {
+		response.Webhooks = append(response.Webhooks, NewSubscriptionResponse(v))
+	}
+	return &response
+}
+
+// ListSubscriptionsResponse represents a response listing webhook subscriptions.
+type ListSubscriptionsResponse struct {
+	Webhooks []*SubscriptionResponse `json:"webhooks"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ListSubscriptionsResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// deliveryStatuses contains delivery statuses deliveries can be listed by.
+var deliveryStatuses = map[walletscreener.DeliveryStatus]bool{
+	walletscreener.DeliveryStatusPending:   true,
+	walletscreener.DeliveryStatusDelivered: true,
+	walletscreener.DeliveryStatusDead:      true,
+}
+
+// ListDeliveriesRequest represents HTTP request for retrieving deliveries of a webhook subscription, the most recent first.
+type ListDeliveriesRequest struct {
+	SubscriptionID string
+	Status         walletscreener.DeliveryStatus // Deliveries of the status, e.g. status=dead lists dead letters
+	Limit          int                           // Maximum number of deliveries
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *ListDeliveriesRequest) Validate() error {
+	if r.Status != "" && !deliveryStatuses[r.Status] {
+		return errors.WithReason(ErrParameterNotValid, errors.Newf("status %q is not one of pending, delivered or dead", r.Status))
+	}
+
+	if r.Limit < 0 {
+		return errors.WithReason(ErrParameterNotValid, errors.New("limit must not be negative"))
+	}
+
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *ListDeliveriesRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	query := req.URL.Query()
+
+	*r = ListDeliveriesRequest{
+		SubscriptionID: mux.Vars(req)["id"],
+		Status:         walletscreener.DeliveryStatus(query.Get("status")),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if r.Limit, err = strconv.Atoi(limit); err != nil {
+			return errors.WithReason(ErrParameterNotValid, errors.Newf("limit %q is not a number", limit))
+		}
+	}
+
+	return r.Validate()
+}
+
+// RedeliverRequest represents HTTP request for attempting a webhook delivery again.
+type RedeliverRequest struct {
+	SubscriptionID string
+	ID             string
+}
+
+// Validate parses request fields and returns whether they contain valid data.
+// Validate implements validator.Validator.
+func (r *RedeliverRequest) Validate() error {
+	return nil
+}
+
+// UnmarshalHTTP implements http.RequestUnmarshaler.
+func (r *RedeliverRequest) UnmarshalHTTPRequest(req *http.Request) error {
+	vars := mux.Vars(req)
+
+	*r = RedeliverRequest{
+		SubscriptionID: vars["id"],
+		ID:             vars["delivery"],
+	}
+	return r.Validate()
+}
+
+// DeliveryAttempt represents a single attempt of a webhook delivery.
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"` // omitted if endpoint did not respond
+	Error      string    `json:"error,omitempty"`
+	Duration   string    `json:"duration"`
+}
+
+// NewDeliveryResponse constructs a new response describing delivery for RedeliverRequest.
+func NewDeliveryResponse(delivery *walletscreener.Delivery) *DeliveryResponse {
+	response := DeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		Event:          delivery.Event,
+		ScreeningID:    delivery.ScreeningID,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		Log:            []*DeliveryAttempt{},
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if json.Valid(delivery.Payload) {
+		response.Payload = delivery.Payload
+	}
+
+	for _, v := range delivery.Log {
+		response.Log = append(response.Log, &DeliveryAttempt{
+			At:         v.At,
+			StatusCode: v.StatusCode,
+			Error:      v.Error,
+			Duration:   v.Duration.String(),
+		})
+	}
+
+	if delivery.Status == walletscreener.DeliveryStatusPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	if !delivery.DeliveredAt.IsZero() {
+		response.DeliveredAt = &delivery.DeliveredAt
+	}
+
+	return &response
+}
+
+// DeliveryResponse represents a response describing a webhook delivery along its log.
+type DeliveryResponse struct {
+	ID             string                        `json:"id"`
+	SubscriptionID string                        `json:"subscription_id"`
+	Event          walletscreener.WebhookEvent   `json:"event"`
+	ScreeningID    string                        `json:"screening_id"`
+	Status         walletscreener.DeliveryStatus `json:"status"`
+	Attempts       int                           `json:"attempts"` // attempts made since delivery was created or redelivered
+	Log            []*DeliveryAttempt            `json:"log"`      // every attempt made, oldest first
+	Payload        json.RawMessage               `json:"payload,omitempty"`
+	NextAttemptAt  *time.Time                    `json:"next_attempt_at,omitempty"` // only while delivery is pending
+	CreatedAt      time.Time                     `json:"created_at"`
+	DeliveredAt    *time.Time                    `json:"delivered_at,omitempty"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *DeliveryResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// NewListDeliveriesResponse constructs a new response for ListDeliveriesRequest.
+func NewListDeliveriesResponse(deliveries []*walletscreener.Delivery) *ListDeliveriesResponse {
+	response := ListDeliveriesResponse{
+		Deliveries: []*DeliveryResponse{},
+	}
+	for _, v := range deliveries {
+		response.Deliveries = append(response.Deliveries, NewDeliveryResponse(v))
+	}
+	return &response
+}
+
+// ListDeliveriesResponse represents a response for ListDeliveriesRequest.
+type ListDeliveriesResponse struct {
+	Deliveries []*DeliveryResponse `json:"deliveries"`
+}
+
+// MarshalHTTP implements http.Marshaler.
+func (r *ListDeliveriesResponse) MarshalHTTP(w http.ResponseWriter) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// WebhookPayload represents body posted to webhook endpoints notifying about event of a screening.
+type WebhookPayload struct {
+	ID        string                      `json:"id"` // ID of the delivery, same for every attempt
+	Event     walletscreener.WebhookEvent `json:"event"`
+	CreatedAt time.Time                   `json:"created_at"`
+	Screening *Screening                  `json:"screening"`
+}
+
+// NewWebhookPayload returns body of delivery notifying about screening.
+func NewWebhookPayload(delivery *walletscreener.Delivery, screening *walletscreener.Screening) ([]byte, error) {
+	return json.Marshal(&WebhookPayload{
+		ID:        delivery.ID,
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt,
+		Screening: newScreening(screening),
+	})
+}
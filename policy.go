@@ -0,0 +1,158 @@
+package walletscreener
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdict represents outcome of risk policy evaluation.
+type Verdict string
+
+// Verdicts ordered by severity.
+const (
+	VerdictAllow  Verdict = "allow"  // wallet is safe to interact with
+	VerdictReview Verdict = "review" // wallet requires manual review
+	VerdictBlock  Verdict = "block"  // wallet must not be interacted with
+)
+
+// severity returns severity of the verdict, the higher value the more severe verdict is.
+func (v Verdict) severity() int {
+	switch v {
+	case VerdictBlock:
+		return 2
+	case VerdictReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// PolicyRuleConfig represents configuration of rules resulting in a single verdict.
+type PolicyRuleConfig struct {
+	Categories []string `mapstructure:"categories"` // Rule matches if wallet is associated with any of categories
+	Score      int      `mapstructure:"score"`      // Rule matches if wallet risk score is greater than score, zero disables rule
+}
+
+// PolicyConfig represents risk policy configuration.
+//
+// Example configuration blocking wallets associated with sanctions or darknet, reviewing wallets with risk score
+// greater than 50 and lowering review threshold for Tron wallets:
+//
+//	POLICY_BLOCK_CATEGORIES=sanctions,darknet
+//	POLICY_REVIEW_SCORE=50
+//	POLICY_CHAINS_TRX_REVIEW_SCORE=30
+type PolicyConfig struct {
+	Block  PolicyRuleConfig `mapstructure:"block"`  // Rules resulting in VerdictBlock
+	Review PolicyRuleConfig `mapstructure:"review"` // Rules resulting in VerdictReview
+
+	// Chains contains per chain overrides. Non-empty categories and non-zero score of
+	// an override replace corresponding default values for the given chain.
+	Chains map[string]*PolicyRuleOverrideConfig `mapstructure:"chains"`
+}
+
+// PolicyRuleOverrideConfig represents per chain risk policy configuration override.
+type PolicyRuleOverrideConfig struct {
+	Block  PolicyRuleConfig `mapstructure:"block"`
+	Review PolicyRuleConfig `mapstructure:"review"`
+}
+
+// policyRules represents rules of a policy applied to a single chain.
+type policyRules struct {
+	block  PolicyRuleConfig
+	review PolicyRuleConfig
+}
+
+// override returns copy of rules overridden by non-empty values of cfg.
+func (r policyRules) override(cfg *PolicyRuleOverrideConfig) policyRules {
+	if len(cfg.Block.Categories) > 0 {
+		r.block.Categories = cfg.Block.Categories
+	}
+	if cfg.Block.Score > 0 {
+		r.block.Score = cfg.Block.Score
+	}
+	if len(cfg.Review.Categories) > 0 {
+		r.review.Categories = cfg.Review.Categories
+	}
+	if cfg.Review.Score > 0 {
+		r.review.Score = cfg.Review.Score
+	}
+	return r
+}
+
+// Policy evaluates screening results against configured rules and decides whether wallet is allowed, requires review or is blocked.
+type Policy struct {
+	defaults policyRules
+	chains   map[Chain]policyRules
+}
+
+// NewPolicy constructs a new Policy from given configuration.
+// Nil configuration results in a policy allowing all wallets.
+func NewPolicy(cfg *PolicyConfig) (*Policy, error) {
+	policy := Policy{
+		chains: make(map[Chain]policyRules),
+	}
+
+	if cfg == nil {
+		return &policy, nil
+	}
+
+	policy.defaults = policyRules{
+		block:  cfg.Block,
+		review: cfg.Review,
+	}
+
+	for k, v := range cfg.Chains {
+		chain, err := ParseChain(k)
+		if err != nil {
+			return nil, err
+		}
+		policy.chains[chain] = policy.defaults.override(v)
+	}
+
+	return &policy, nil
+}
+
+// PolicyDecision represents outcome of policy evaluation.
+type PolicyDecision struct {
+	Verdict      Verdict  // Most severe verdict of matched rules, VerdictAllow when none matched
+	MatchedRules []string // Human readable descriptions of matched rules
+}
+
+// Evaluate evaluates screening result against policy rules applicable to the result chain.
+func (p *Policy) Evaluate(result *ScreeningResult) *PolicyDecision {
+	rules, ok := p.chains[result.Chain]
+	if !ok {
+		rules = p.defaults
+	}
+
+	decision := PolicyDecision{
+		Verdict: VerdictAllow,
+	}
+
+	match := func(verdict Verdict, rule PolicyRuleConfig) {
+		for _, category := range result.Categories() {
+			for _, v := range rule.Categories {
+				if strings.EqualFold(category, v) {
+					decision.match(verdict, fmt.Sprintf("%s: category %s", verdict, category))
+				}
+			}
+		}
+
+		if rule.Score > 0 && result.Risk > rule.Score {
+			decision.match(verdict, fmt.Sprintf("%s: risk score %d > %d", verdict, result.Risk, rule.Score))
+		}
+	}
+
+	match(VerdictBlock, rules.block)
+	match(VerdictReview, rules.review)
+
+	return &decision
+}
+
+// match records matched rule and escalates decision verdict if needed.
+func (d *PolicyDecision) match(verdict Verdict, rule string) {
+	if verdict.severity() > d.Verdict.severity() {
+		d.Verdict = verdict
+	}
+	d.MatchedRules = append(d.MatchedRules, rule)
+}
@@ -0,0 +1,126 @@
+package walletscreener
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy, err := NewPolicy(&PolicyConfig{
+		Block: PolicyRuleConfig{
+			Categories: []string{"sanctions", "darknet"},
+		},
+		Review: PolicyRuleConfig{
+			Score: 50,
+		},
+		Chains: map[string]*PolicyRuleOverrideConfig{
+			"trx": {
+				Review: PolicyRuleConfig{Score: 30},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	var testcases = []struct {
+		result *ScreeningResult
+
+		decision *PolicyDecision
+	}{
+		// clean wallet
+		{
+			result: &ScreeningResult{
+				Chain: ChainEthereum,
+			},
+			decision: &PolicyDecision{
+				Verdict: VerdictAllow,
+			},
+		},
+		// category not covered by rules
+		{
+			result: &ScreeningResult{
+				Chain:         ChainEthereum,
+				Risk:          20,
+				OwnCategories: []*RiskCategory{{Name: "Exchange", Risk: 20}},
+			},
+			decision: &PolicyDecision{
+				Verdict: VerdictAllow,
+			},
+		},
+		// risk score above review threshold
+		{
+			result: &ScreeningResult{
+				Chain: ChainEthereum,
+				Risk:  51,
+			},
+			decision: &PolicyDecision{
+				Verdict:      VerdictReview,
+				MatchedRules: []string{"review: risk score 51 > 50"},
+			},
+		},
+		// blocked category takes precedence over review, categories are matched case insensitively
+		{
+			result: &ScreeningResult{
+				Chain:                   ChainEthereum,
+				Risk:                    100,
+				SourceOfFundsCategories: []*RiskCategory{{Name: "Sanctions", Risk: 100}},
+			},
+			decision: &PolicyDecision{
+				Verdict:      VerdictBlock,
+				MatchedRules: []string{"block: category Sanctions", "review: risk score 100 > 50"},
+			},
+		},
+		// chain override lowers review threshold
+		{
+			result: &ScreeningResult{
+				Chain: ChainTron,
+				Risk:  40,
+			},
+			decision: &PolicyDecision{
+				Verdict:      VerdictReview,
+				MatchedRules: []string{"review: risk score 40 > 30"},
+			},
+		},
+		// chain override keeps default block rules
+		{
+			result: &ScreeningResult{
+				Chain:         ChainTron,
+				OwnCategories: []*RiskCategory{{Name: "darknet"}},
+			},
+			decision: &PolicyDecision{
+				Verdict:      VerdictBlock,
+				MatchedRules: []string{"block: category darknet"},
+			},
+		},
+	}
+
+	for i, tt := range testcases {
+		if decision := policy.Evaluate(tt.result); !cmp.Equal(decision, tt.decision) {
+			t.Errorf("#%d got %v, want %v", i, decision, tt.decision)
+		}
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	_, err := NewPolicy(&PolicyConfig{
+		Chains: map[string]*PolicyRuleOverrideConfig{
+			"doge": {},
+		},
+	})
+	if !errors.Is(err, ErrChainNotSupported) {
+		t.Errorf("got %v, want %v", err, ErrChainNotSupported)
+	}
+
+	policy, err := NewPolicy(nil)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if decision := policy.Evaluate(&ScreeningResult{Risk: 100}); decision.Verdict != VerdictAllow {
+		t.Errorf("got %v, want %v", decision.Verdict, VerdictAllow)
+	}
+}
@@ -1,9 +1,55 @@
 package walletscreener
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// Risk provider errors.
+var (
+	ErrProviderUnavailable = errors.New("risk provider is temporarily unavailable")
+	ErrAddressNotValid     = errors.New("risk provider rejected address as not valid")
+)
 
 // WalletRiskScreeningProvider represents wallet risk screening provider.
 type WalletRiskScreeningProvider interface {
-	// GetRiskCategories returns a list of risk categories for the given address.
-	GetRiskCategories(ctx context.Context, address string) ([]string, error)
+	// GetRiskCategories screens the given address on the given chain and returns screening result
+	// containing risk score along with risk categories.
+	// ErrChainNotSupported is returned if provider is not able to screen wallets on the chain.
+	GetRiskCategories(ctx context.Context, chain Chain, address string) (*ScreeningResult, error)
+}
+
+// cacheBypassKey is a context key of cache bypass flag.
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a copy of ctx instructing providers to screen wallet instead of serving cached result.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// IsCacheBypassed returns whether ctx instructs providers to bypass cached results.
+func IsCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// ProviderUnavailableError is returned when risk provider is temporarily unavailable
+// and should not be called again until RetryAfter elapses.
+// ProviderUnavailableError matches ErrProviderUnavailable.
+type ProviderUnavailableError struct {
+	Provider   string        // Name of the unavailable provider
+	RetryAfter time.Duration // Duration after which provider is expected to be available again
+}
+
+// Error implements error.
+func (e *ProviderUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s, retry after %s", e.Provider, ErrProviderUnavailable, e.RetryAfter)
+}
+
+// Is reports whether target is ErrProviderUnavailable.
+func (e *ProviderUnavailableError) Is(target error) bool {
+	return target == ErrProviderUnavailable
 }
@@ -6,15 +6,31 @@ import (
 	stdhttp "net/http"
 	"time"
 
+	"github.com/deividaspetraitis/wallet-screener"
 	"github.com/deividaspetraitis/wallet-screener/errors"
 	"github.com/deividaspetraitis/wallet-screener/http"
-	"github.com/deividaspetraitis/wallet-screener/slices"
 	"github.com/deividaspetraitis/wallet-screener/token/jwt"
 )
 
+// BlockmateName is a name of Blockmate risk provider recorded in screening results.
+const BlockmateName = "blockmate"
+
 // Config represents Blockmate risk provider configuration.
 type Config struct {
-	APIKey string `mapstructure:"apikey"` // API key
+	APIKey  string           `mapstructure:"apikey"`  // API key
+	Timeout time.Duration    `mapstructure:"timeout"` // Maximum duration of a single screening, zero means no timeout
+	Weight  float64          `mapstructure:"weight"`  // Weight of risk score when merging results of multiple providers
+	Breaker BreakerConfig    `mapstructure:"breaker"` // Circuit breaker guarding provider, disabled unless thresholds are set
+	Retry   http.RetryPolicy `mapstructure:"retry"`   // Policy of retrying failed API requests
+}
+
+// blockmateChains maps supported chains to chain identifiers used by Blockmate API.
+var blockmateChains = map[walletscreener.Chain]string{
+	walletscreener.ChainEthereum: "eth",
+	walletscreener.ChainBitcoin:  "btc",
+	walletscreener.ChainTron:     "trx",
+	walletscreener.ChainPolygon:  "matic",
+	walletscreener.ChainSolana:   "sol",
 }
 
 // Blockmate is an implementation of walletscreener.WalletRiskScreeningProvider
@@ -22,8 +38,8 @@ type Blockmate struct {
 	// apiKey is Blockmate API-Key used to authenticate and exchanged for JWT tokens.
 	apiKey string
 
-	// jwtToken is auth token used to authorise client when making API calls to blockmate.
-	jwtToken string
+	// tokens manages JWT tokens used to authorise client when making API calls to blockmate.
+	tokens *jwt.Manager
 
 	// client is an http.Client a library containing HTTP layer methods and helpers.
 	*http.Client
@@ -35,31 +51,46 @@ func NewBlockMate(apiKey string, client *http.Client) (*Blockmate, error) {
 		return nil, errors.Newf("riskprovider: %s is not a valid API key token", apiKey) // API key is mandatory
 	}
 
-	return &Blockmate{
+	c := &Blockmate{
 		apiKey: apiKey,
 		Client: client,
-	}, nil
+	}
+
+	c.tokens = jwt.NewManager(func(ctx context.Context) (string, error) {
+		return c.AuthProject(ctx, c.apiKey)
+	}, jwt.DefaultRenewalLeeway)
+
+	return c, nil
 }
 
 // Request wraps http.Client Request method and makes sure that all requests are authorised by renewing JWT token.
+// Request is retried once with a new token if API rejects current token.
 // For more see http.Client docs.
 func (c *Blockmate) Request(ctx context.Context, method, uri string, v []byte, options ...http.RequestOption) (*stdhttp.Response, error) {
-	_, err := jwt.Parse(c.jwtToken)
-	if err == nil {
-		return c.authorizedRequest(ctx, method, uri, v, options...) // JWT is present and valid
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err // authorisation error
 	}
 
-	c.jwtToken, err = c.AuthProject(ctx, c.apiKey)
+	res, err := c.authorizedRequest(ctx, token, method, uri, v, options...)
+	if !isResponseStatus(err, stdhttp.StatusUnauthorized) {
+		return res, err
+	}
+
+	// token was rejected before its expiry, e.g. revoked
+	c.tokens.Invalidate(token)
+
+	token, err = c.tokens.Token(ctx)
 	if err != nil {
-		return nil, err // authorisation error
+		return nil, err
 	}
 
-	return c.authorizedRequest(ctx, method, uri, v, options...)
+	return c.authorizedRequest(ctx, token, method, uri, v, options...)
 }
 
 // authorizedRequest makes authorized requests to the API by adding authorization header with token.
-func (c *Blockmate) authorizedRequest(ctx context.Context, method, uri string, v []byte, options ...http.RequestOption) (*stdhttp.Response, error) {
-	return c.Client.Request(ctx, method, uri, v, append(options, http.WithBearerToken(c.jwtToken))...)
+func (c *Blockmate) authorizedRequest(ctx context.Context, token, method, uri string, v []byte, options ...http.RequestOption) (*stdhttp.Response, error) {
+	return c.Client.Request(ctx, method, uri, v, append(options, http.WithBearerToken(token))...)
 }
 
 // jwtTokenResponse represents JWT token response from auth blockmate endpoint.
@@ -133,36 +164,93 @@ func (t *getAddressRiskScoreDetails) UnmarshalHTTPResponse(r *stdhttp.Response)
 	return json.NewDecoder(r.Body).Decode(t)
 }
 
-// GetRiskCategories returns risk categories for given address on ethereum network.
-func (c *Blockmate) GetRiskCategories(ctx context.Context, address string) (categories []string, err error) {
+// GetRiskCategories returns risk score and categories for given address on given chain.
+func (c *Blockmate) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
 	var (
 		response getAddressRiskScoreDetails
 		opts     []http.RequestOption
 	)
 
+	blockmateChain, ok := blockmateChains[chain]
+	if !ok {
+		return nil, errors.Wrapf(walletscreener.ErrChainNotSupported, "blockmate: chain %s", chain)
+	}
+
 	opts = append(opts, http.WithQueryParam("address", address))
-	opts = append(opts, http.WithQueryParam("chain", "eth"))
+	opts = append(opts, http.WithQueryParam("chain", blockmateChain))
 
 	res, err := c.Request(ctx, stdhttp.MethodGet, "risk/score/details", nil, opts...)
-	if err == nil {
-		defer res.Body.Close()
-		err = http.UnmarshalResponse(res, &response)
+	if err != nil {
+		return nil, blockmateError(err)
 	}
+	defer res.Body.Close()
 
-	categories = collectUniqueCategoryNames(response.Details.OwnCategories, response.Details.SourceOfFundsCategories)
+	if err := http.UnmarshalResponse(res, &response); err != nil {
+		return nil, err
+	}
 
-	return
+	return newScreeningResult(chain, address, &response), nil
+}
+
+// newScreeningResult converts risk score details response into walletscreener.ScreeningResult.
+func newScreeningResult(chain walletscreener.Chain, address string, response *getAddressRiskScoreDetails) *walletscreener.ScreeningResult {
+	return &walletscreener.ScreeningResult{
+		Chain:                   chain,
+		Address:                 address,
+		Provider:                BlockmateName,
+		Entity:                  response.Name,
+		Risk:                    response.Risk,
+		OwnCategories:           newRiskCategories(response.Details.OwnCategories),
+		SourceOfFundsCategories: newRiskCategories(response.Details.SourceOfFundsCategories),
+		CaseID:                  response.CaseID,
+		RequestedAt:             response.RequestDatetime,
+		RespondedAt:             response.ResponseDatetime,
+	}
+}
+
+// newRiskCategories converts detailsCategory into walletscreener.RiskCategory.
+func newRiskCategories(categories []detailsCategory) []*walletscreener.RiskCategory {
+	var result []*walletscreener.RiskCategory
+	for _, v := range categories {
+		result = append(result, &walletscreener.RiskCategory{
+			Name:   v.CategoryName,
+			Entity: v.Name,
+			Risk:   v.Risk,
+		})
+	}
+	return result
 }
 
-// collectUniqueCategoryNames parses detailsCategory and returns slice containing of unique CategoryName.
-func collectUniqueCategoryNames(category ...[]detailsCategory) []string {
-	var categories []string
+// defaultRateLimitBackoff is a duration to back off for when API rate limits requests without specifying Retry-After.
+const defaultRateLimitBackoff = 10 * time.Second
+
+// blockmateError maps unsuccessful API responses to walletscreener errors.
+func blockmateError(err error) error {
+	var responseErr *http.ResponseError
+	if !errors.As(err, &responseErr) {
+		return err
+	}
 
-	for _, detailsCategory := range category {
-		for _, v := range detailsCategory {
-			categories = append(categories, v.CategoryName)
+	switch responseErr.StatusCode {
+	case stdhttp.StatusTooManyRequests:
+		retryAfter, ok := responseErr.RetryAfter()
+		if !ok {
+			retryAfter = defaultRateLimitBackoff
+		}
+		return &walletscreener.ProviderUnavailableError{
+			Provider:   BlockmateName,
+			RetryAfter: retryAfter,
 		}
+	case stdhttp.StatusBadRequest, stdhttp.StatusUnprocessableEntity:
+		// chain is validated before making a request, address is the only user input left
+		return errors.WithReason(walletscreener.ErrAddressNotValid, responseErr)
+	default:
+		return err
 	}
+}
 
-	return slices.Unique(categories)
+// isResponseStatus returns whether err is *http.ResponseError with given status code.
+func isResponseStatus(err error, statusCode int) bool {
+	var responseErr *http.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == statusCode
 }
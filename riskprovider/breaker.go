@@ -0,0 +1,288 @@
+package riskprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// BreakerConfig represents circuit breaker configuration.
+type BreakerConfig struct {
+	ConsecutiveFailures int           `mapstructure:"consecutivefailures"` // Opens circuit after given number of consecutive failures, zero disables
+	FailureRatio        float64       `mapstructure:"failureratio"`        // Opens circuit when ratio of failures within window reaches given value, zero disables
+	MinRequests         int           `mapstructure:"minrequests"`         // Minimum number of requests within window for failure ratio to be considered
+	Window              time.Duration `mapstructure:"window"`              // Duration of window failure ratio is counted within
+	CoolDown            time.Duration `mapstructure:"cooldown"`            // Duration circuit stays open before trial requests are let through
+	HalfOpenRequests    int           `mapstructure:"halfopenrequests"`    // Number of successful trial requests closing the circuit
+}
+
+// Enabled returns whether any of circuit opening thresholds are configured.
+func (c *BreakerConfig) Enabled() bool {
+	return c.ConsecutiveFailures > 0 || c.FailureRatio > 0
+}
+
+// Default breaker configuration values.
+const (
+	defaultBreakerWindow           = time.Minute
+	defaultBreakerCoolDown         = 30 * time.Second
+	defaultBreakerHalfOpenRequests = 1
+)
+
+// BreakerState represents state of the circuit.
+type BreakerState int
+
+// Circuit states.
+const (
+	BreakerClosed   BreakerState = iota // requests are let through
+	BreakerOpen                         // requests are rejected
+	BreakerHalfOpen                     // limited number of trial requests are let through
+)
+
+// String implements fmt.Stringer.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker is an implementation of walletscreener.WalletRiskScreeningProvider guarding underlying provider.
+//
+// Circuit opens once failures reach configured thresholds, while open requests are rejected with
+// *walletscreener.ProviderUnavailableError without calling the provider. After cool-down period circuit becomes
+// half-open letting trial requests through, circuit closes once they succeed or opens again on the first failure.
+type CircuitBreaker struct {
+	name     string
+	provider walletscreener.WalletRiskScreeningProvider
+	cfg      BreakerConfig
+	now      func() time.Time
+
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	consecutive int       // consecutive failures
+	windowStart time.Time // start of the current failure ratio window
+	requests    int       // requests within window
+	failures    int       // failures within window
+	trials      int       // trial requests let through while half-open
+	successes   int       // successful trial requests
+}
+
+// NewCircuitBreaker constructs and returns new CircuitBreaker guarding provider identified by name.
+func NewCircuitBreaker(name string, provider walletscreener.WalletRiskScreeningProvider, cfg BreakerConfig) (*CircuitBreaker, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("riskprovider: circuit breaker requires consecutive failures or failure ratio threshold")
+	}
+
+	if cfg.FailureRatio > 1 {
+		return nil, errors.Newf("riskprovider: failure ratio must be between 0 and 1, got %f", cfg.FailureRatio)
+	}
+
+	if cfg.Window <= 0 {
+		cfg.Window = defaultBreakerWindow
+	}
+
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = defaultBreakerCoolDown
+	}
+
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = defaultBreakerHalfOpenRequests
+	}
+
+	return &CircuitBreaker{
+		name:     name,
+		provider: provider,
+		cfg:      cfg,
+		now:      time.Now,
+	}, nil
+}
+
+// State returns current state of the circuit.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(b.now())
+	return b.state
+}
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+func (b *CircuitBreaker) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+
+	result, err := b.provider.GetRiskCategories(ctx, chain, address)
+	b.record(ctx, err)
+
+	return result, err
+}
+
+// advance moves open circuit to half-open state once cool-down period elapses.
+func (b *CircuitBreaker) advance(now time.Time) {
+	if b.state == BreakerOpen && !now.Before(b.openedAt.Add(b.cfg.CoolDown)) {
+		b.state = BreakerHalfOpen
+		b.trials = 0
+		b.successes = 0
+	}
+}
+
+// allow returns error if request must not be let through.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.advance(now)
+
+	switch b.state {
+	case BreakerOpen:
+		return &walletscreener.ProviderUnavailableError{
+			Provider:   b.name,
+			RetryAfter: b.openedAt.Add(b.cfg.CoolDown).Sub(now),
+		}
+	case BreakerHalfOpen:
+		if b.trials >= b.cfg.HalfOpenRequests {
+			return &walletscreener.ProviderUnavailableError{
+				Provider:   b.name,
+				RetryAfter: b.cfg.CoolDown,
+			}
+		}
+		b.trials++
+	}
+
+	return nil
+}
+
+// record records outcome of the request.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	switch {
+	case err == nil:
+		b.success(now)
+	case isProviderFailure(ctx, err):
+		b.failure(now)
+	case b.state == BreakerHalfOpen && b.trials > 0:
+		b.trials-- // outcome caused by the caller tells nothing about provider health, release trial
+	}
+}
+
+// success records successful request.
+func (b *CircuitBreaker) success(now time.Time) {
+	b.consecutive = 0
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.close(now)
+		}
+	case BreakerClosed:
+		b.count(now, false)
+	}
+}
+
+// failure records failed request and opens the circuit if thresholds are reached.
+func (b *CircuitBreaker) failure(now time.Time) {
+	b.consecutive++
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.open(now)
+	case BreakerClosed:
+		b.count(now, true)
+
+		if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+			b.open(now)
+			return
+		}
+
+		if b.cfg.FailureRatio > 0 && b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRatio {
+			b.open(now)
+		}
+	}
+}
+
+// count counts request within failure ratio window starting a new window if current one has elapsed.
+func (b *CircuitBreaker) count(now time.Time, failure bool) {
+	if now.Sub(b.windowStart) >= b.cfg.Window {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+
+	b.requests++
+	if failure {
+		b.failures++
+	}
+}
+
+// open opens the circuit.
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+}
+
+// close closes the circuit and resets counters.
+func (b *CircuitBreaker) close(now time.Time) {
+	b.state = BreakerClosed
+	b.consecutive = 0
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+// isProviderFailure returns whether err indicates provider failure.
+// Errors caused by the caller, such as unsupported chain, address rejected as not valid or request given up by the caller
+// whether cancelled or timed out, are not provider failures. Provider not responding within its own timeout is.
+func isProviderFailure(ctx context.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case callerGaveUp(ctx):
+		return false
+	case errors.Is(err, walletscreener.ErrChainNotSupported):
+		return false
+	case errors.Is(err, walletscreener.ErrAddressNotValid):
+		return false
+	default:
+		return true
+	}
+}
+
+// callerContextKey is a context key of the caller context screening is limited by provider timeout within.
+type callerContextKey struct{}
+
+// withProviderTimeout returns ctx limited to timeout of a single provider screening.
+// Caller context is kept so that providers tell their own timeout from the caller giving up, see callerGaveUp.
+func withProviderTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithValue(ctx, callerContextKey{}, ctx), timeout)
+}
+
+// callerGaveUp returns whether ctx is done because the caller cancelled request or its deadline expired,
+// ctx done because of expired provider timeout only is not given up by the caller.
+func callerGaveUp(ctx context.Context) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+
+	if caller, ok := ctx.Value(callerContextKey{}).(context.Context); ok {
+		return caller.Err() != nil
+	}
+
+	return true
+}
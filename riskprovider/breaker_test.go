@@ -0,0 +1,220 @@
+package riskprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+)
+
+// switchProvider is a provider failing while fail is set.
+type switchProvider struct {
+	fail  bool
+	calls int
+}
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+func (p *switchProvider) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	p.calls++
+	if p.fail {
+		return nil, errors.New("outage")
+	}
+	return &walletscreener.ScreeningResult{Chain: chain, Address: address}, nil
+}
+
+// newTestBreaker constructs CircuitBreaker using clock controlled by the test.
+func newTestBreaker(t *testing.T, provider walletscreener.WalletRiskScreeningProvider, cfg BreakerConfig) (*CircuitBreaker, *time.Time) {
+	t.Helper()
+
+	breaker, err := NewCircuitBreaker("test", provider, cfg)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	now := time.Date(2023, 10, 4, 15, 18, 21, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	return breaker, &now
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	provider := &switchProvider{fail: true}
+	breaker, now := newTestBreaker(t, provider, BreakerConfig{
+		ConsecutiveFailures: 3,
+		CoolDown:            time.Minute,
+	})
+
+	screen := func() error {
+		_, err := breaker.GetRiskCategories(context.Background(), walletscreener.ChainEthereum, "0xe9e9afac38e64728f1afbb2b65dec7be7c704c05")
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := screen(); err == nil || errors.Is(err, walletscreener.ErrProviderUnavailable) {
+			t.Fatalf("#%d got %v, want provider error", i, err)
+		}
+	}
+
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("state got %v, want %v", state, BreakerOpen)
+	}
+
+	// open circuit rejects requests without calling provider
+	var unavailable *walletscreener.ProviderUnavailableError
+	if err := screen(); !errors.As(err, &unavailable) {
+		t.Fatalf("got %v, want %T", err, unavailable)
+	}
+
+	if unavailable.RetryAfter != time.Minute {
+		t.Errorf("retry after got %v, want %v", unavailable.RetryAfter, time.Minute)
+	}
+
+	if provider.calls != 3 {
+		t.Errorf("calls got %v, want %v", provider.calls, 3)
+	}
+
+	// failing trial request opens circuit again
+	*now = now.Add(time.Minute)
+	if state := breaker.State(); state != BreakerHalfOpen {
+		t.Fatalf("state got %v, want %v", state, BreakerHalfOpen)
+	}
+
+	if err := screen(); err == nil || errors.Is(err, walletscreener.ErrProviderUnavailable) {
+		t.Fatalf("got %v, want provider error", err)
+	}
+
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("state got %v, want %v", state, BreakerOpen)
+	}
+
+	// successful trial request closes circuit
+	*now = now.Add(time.Minute)
+	provider.fail = false
+
+	if err := screen(); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if state := breaker.State(); state != BreakerClosed {
+		t.Fatalf("state got %v, want %v", state, BreakerClosed)
+	}
+}
+
+func TestCircuitBreakerFailureRatio(t *testing.T) {
+	provider := &switchProvider{}
+	breaker, now := newTestBreaker(t, provider, BreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+	})
+
+	screen := func(fail bool) {
+		provider.fail = fail
+		breaker.GetRiskCategories(context.Background(), walletscreener.ChainEthereum, "0xe9e9afac38e64728f1afbb2b65dec7be7c704c05")
+	}
+
+	// ratio is not considered until minimum requests are made
+	screen(true)
+	screen(false)
+	screen(true)
+	if state := breaker.State(); state != BreakerClosed {
+		t.Fatalf("state got %v, want %v", state, BreakerClosed)
+	}
+
+	// new window starts counting from scratch
+	*now = now.Add(time.Minute)
+	screen(false)
+	screen(false)
+	screen(false)
+	screen(true)
+	if state := breaker.State(); state != BreakerClosed {
+		t.Fatalf("state got %v, want %v", state, BreakerClosed)
+	}
+
+	screen(true)
+	screen(true)
+	if state := breaker.State(); state != BreakerOpen {
+		t.Fatalf("state got %v, want %v", state, BreakerOpen)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerErrors(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	outage := errors.New("outage")
+
+	var testcases = []struct {
+		ctx      context.Context
+		provider walletscreener.WalletRiskScreeningProvider
+		err      error
+	}{
+		{context.Background(), newTestProvider(0, walletscreener.ErrChainNotSupported), walletscreener.ErrChainNotSupported},
+		{context.Background(), newTestProvider(0, walletscreener.ErrAddressNotValid), walletscreener.ErrAddressNotValid},
+		{expired, slowProvider, context.DeadlineExceeded}, // caller gave up waiting
+		{expired, newTestProvider(0, outage), outage},     // failed while caller gave up
+	}
+
+	for _, tc := range testcases {
+		breaker, _ := newTestBreaker(t, tc.provider, BreakerConfig{
+			ConsecutiveFailures: 1,
+		})
+
+		for i := 0; i < 3; i++ {
+			_, err := breaker.GetRiskCategories(tc.ctx, walletscreener.ChainSolana, "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("#%d got %v, want %v", i, err, tc.err)
+			}
+		}
+
+		if state := breaker.State(); state != BreakerClosed {
+			t.Errorf("%v state got %v, want %v", tc.err, state, BreakerClosed)
+		}
+	}
+}
+
+func TestCircuitBreakerProviderTimeout(t *testing.T) {
+	breaker, _ := newTestBreaker(t, slowProvider, BreakerConfig{
+		ConsecutiveFailures: 2,
+	})
+
+	// provider timing out every time within failover is failing
+	failover, err := NewFailover(log.Default(), &FailoverProvider{Name: "slow", Provider: breaker, Timeout: time.Millisecond})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := failover.GetRiskCategories(context.Background(), walletscreener.ChainSolana, "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("#%d got %v, want %v", i, err, context.DeadlineExceeded)
+		}
+	}
+
+	if state := breaker.State(); state != BreakerOpen {
+		t.Errorf("state got %v, want %v", state, BreakerOpen)
+	}
+
+	// caller giving up before provider timeout is not counted
+	breaker, _ = newTestBreaker(t, slowProvider, BreakerConfig{
+		ConsecutiveFailures: 1,
+	})
+
+	failover, err = NewFailover(log.Default(), &FailoverProvider{Name: "slow", Provider: breaker, Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if _, err := failover.GetRiskCategories(ctx, walletscreener.ChainSolana, "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if state := breaker.State(); state != BreakerClosed {
+		t.Errorf("state got %v, want %v", state, BreakerClosed)
+	}
+}
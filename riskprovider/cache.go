@@ -0,0 +1,119 @@
+package riskprovider
+
+import (
+	"context"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/cache"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// CacheConfig represents screening results cache configuration.
+//
+// Time to live of a result is resolved from the most specific setting: clean wallets, verdict, chain and default TTL.
+// Example configuration caching results for 10 minutes, blocked wallets for an hour and clean wallets for a day:
+//
+//	RISKPROVIDER_CACHE_SIZE=10000
+//	RISKPROVIDER_CACHE_TTL=10m
+//	RISKPROVIDER_CACHE_VERDICTS_BLOCK=1h
+//	RISKPROVIDER_CACHE_CLEANTTL=24h
+type CacheConfig struct {
+	Size     int                      `mapstructure:"size"`     // Maximum number of cached results, zero disables cache
+	TTL      time.Duration            `mapstructure:"ttl"`      // Default time to live of cached results
+	CleanTTL time.Duration            `mapstructure:"cleanttl"` // Time to live of results without risk categories, zero falls back to other settings
+	Verdicts map[string]time.Duration `mapstructure:"verdicts"` // Time to live per policy verdict
+	Chains   map[string]time.Duration `mapstructure:"chains"`   // Time to live per chain
+}
+
+// Enabled returns whether screening results cache is configured.
+func (c *CacheConfig) Enabled() bool {
+	return c.Size > 0
+}
+
+// Cache is an implementation of walletscreener.WalletRiskScreeningProvider caching results of underlying provider.
+// Results are cached per chain and address, cached results are marked as such.
+// Context flagged by walletscreener.WithCacheBypass screens wallet and refreshes the cache.
+type Cache struct {
+	provider walletscreener.WalletRiskScreeningProvider
+	policy   *walletscreener.Policy
+	cfg      CacheConfig
+	results  *cache.LRU[string, walletscreener.ScreeningResult]
+}
+
+// NewCache constructs and returns new Cache of provider results.
+// Policy is used to evaluate verdicts of results to resolve their time to live.
+func NewCache(provider walletscreener.WalletRiskScreeningProvider, policy *walletscreener.Policy, cfg CacheConfig) (*Cache, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("riskprovider: cache size must be positive")
+	}
+
+	for verdict := range cfg.Verdicts {
+		switch walletscreener.Verdict(verdict) {
+		case walletscreener.VerdictAllow, walletscreener.VerdictReview, walletscreener.VerdictBlock:
+		default:
+			return nil, errors.Newf("riskprovider: cache: unknown verdict %s", verdict)
+		}
+	}
+
+	for chain := range cfg.Chains {
+		if _, err := walletscreener.ParseChain(chain); err != nil {
+			return nil, errors.Wrap(err, "riskprovider: cache")
+		}
+	}
+
+	return &Cache{
+		provider: provider,
+		policy:   policy,
+		cfg:      cfg,
+		results:  cache.NewLRU[string, walletscreener.ScreeningResult](cfg.Size),
+	}, nil
+}
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+func (c *Cache) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	key := cacheKey(chain, address)
+
+	if !walletscreener.IsCacheBypassed(ctx) {
+		if result, ok := c.results.Get(key); ok {
+			// every caller receives its own copy of cached result
+			cached := result.Clone()
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+
+	result, err := c.provider.GetRiskCategories(ctx, chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// deep copy is cached so that callers are free to modify returned result
+	c.results.Set(key, *result.Clone(), c.ttl(result))
+
+	return result, nil
+}
+
+// ttl resolves time to live of the result.
+func (c *Cache) ttl(result *walletscreener.ScreeningResult) time.Duration {
+	ttl := c.cfg.TTL
+
+	if v, ok := c.cfg.Chains[result.Chain.String()]; ok {
+		ttl = v
+	}
+
+	if v, ok := c.cfg.Verdicts[string(c.policy.Evaluate(result).Verdict)]; ok {
+		ttl = v
+	}
+
+	if c.cfg.CleanTTL > 0 && len(result.Categories()) < 1 {
+		ttl = c.cfg.CleanTTL
+	}
+
+	return ttl
+}
+
+// cacheKey returns cache key of the wallet.
+func cacheKey(chain walletscreener.Chain, address string) string {
+	return chain.String() + ":" + address
+}
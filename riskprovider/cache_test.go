@@ -0,0 +1,164 @@
+package riskprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// countingProvider returns provider responding with given result and counting calls.
+func countingProvider(calls *int, result walletscreener.ScreeningResult, err error) providerFunc {
+	return func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+		*calls++
+		if err != nil {
+			return nil, err
+		}
+		result.Chain, result.Address = chain, address
+		return &result, nil
+	}
+}
+
+func TestCache(t *testing.T) {
+	policy, err := walletscreener.NewPolicy(&walletscreener.PolicyConfig{
+		Block: walletscreener.PolicyRuleConfig{Categories: []string{"sanctions"}},
+	})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	sanctioned := walletscreener.ScreeningResult{
+		Risk:          100,
+		OwnCategories: []*walletscreener.RiskCategory{{Name: "Sanctions", Risk: 100}},
+	}
+
+	var testcases = []struct {
+		cfg    CacheConfig
+		chain  walletscreener.Chain
+		result walletscreener.ScreeningResult
+		err    error
+		bypass bool
+
+		calls  int
+		cached bool
+	}{
+		// result is cached
+		{
+			cfg:    CacheConfig{Size: 1, TTL: time.Minute},
+			chain:  walletscreener.ChainEthereum,
+			result: sanctioned,
+			calls:  1,
+			cached: true,
+		},
+		// cache is bypassed
+		{
+			cfg:    CacheConfig{Size: 1, TTL: time.Minute},
+			chain:  walletscreener.ChainEthereum,
+			result: sanctioned,
+			bypass: true,
+			calls:  2,
+		},
+		// errors are not cached
+		{
+			cfg:   CacheConfig{Size: 1, TTL: time.Minute},
+			chain: walletscreener.ChainEthereum,
+			err:   errors.New("provider error"),
+			calls: 2,
+		},
+		// chain is not cached
+		{
+			cfg:    CacheConfig{Size: 1, TTL: time.Minute, Chains: map[string]time.Duration{"trx": 0}},
+			chain:  walletscreener.ChainTron,
+			result: sanctioned,
+			calls:  2,
+		},
+		// blocked wallets are not cached
+		{
+			cfg:    CacheConfig{Size: 1, TTL: time.Minute, Verdicts: map[string]time.Duration{"block": 0}},
+			chain:  walletscreener.ChainEthereum,
+			result: sanctioned,
+			calls:  2,
+		},
+		// clean wallets are cached even though default is not to cache
+		{
+			cfg:    CacheConfig{Size: 1, CleanTTL: time.Hour},
+			chain:  walletscreener.ChainEthereum,
+			calls:  1,
+			cached: true,
+		},
+	}
+
+	for i, tt := range testcases {
+		var calls int
+		provider, err := NewCache(countingProvider(&calls, tt.result, tt.err), policy, tt.cfg)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		ctx := context.Background()
+		if tt.bypass {
+			ctx = walletscreener.WithCacheBypass(ctx)
+		}
+
+		var result *walletscreener.ScreeningResult
+		for j := 0; j < 2; j++ {
+			result, err = provider.GetRiskCategories(ctx, tt.chain, "address")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+			}
+		}
+
+		if calls != tt.calls {
+			t.Errorf("#%d calls got %v, want %v", i, calls, tt.calls)
+		}
+
+		if result != nil && result.Cached != tt.cached {
+			t.Errorf("#%d cached got %v, want %v", i, result.Cached, tt.cached)
+		}
+	}
+}
+
+func TestNewCacheConfig(t *testing.T) {
+	var testcases = []CacheConfig{
+		{},
+		{Size: 1, Verdicts: map[string]time.Duration{"deny": time.Minute}},
+		{Size: 1, Chains: map[string]time.Duration{"doge": time.Minute}},
+	}
+
+	for i, cfg := range testcases {
+		if _, err := NewCache(newTestProvider(0, nil), nil, cfg); err == nil {
+			t.Errorf("#%d got %v, want error", i, err)
+		}
+	}
+}
+
+func TestCacheResultCopy(t *testing.T) {
+	provider, err := NewCache(providerFunc(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+		return &walletscreener.ScreeningResult{
+			Chain:         chain,
+			Address:       address,
+			OwnCategories: []*walletscreener.RiskCategory{{Name: "Sanctions", Risk: 100, Providers: []string{"blockmate"}}},
+		}, nil
+	}), &walletscreener.Policy{}, CacheConfig{Size: 1, TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// callers modifying results do not modify cached result
+	for i := 0; i < 3; i++ {
+		result, err := provider.GetRiskCategories(context.Background(), walletscreener.ChainEthereum, "address")
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		category := result.OwnCategories[0]
+		if category.Name != "Sanctions" || category.Providers[0] != "blockmate" {
+			t.Errorf("#%d got %s reported by %v, want %s reported by %v", i, category.Name, category.Providers, "Sanctions", []string{"blockmate"})
+		}
+
+		category.Name = "Gambling"
+		category.Providers[0] = "another"
+	}
+}
@@ -0,0 +1,218 @@
+package riskprovider
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+
+	"golang.org/x/exp/slices"
+)
+
+// ErrQuorumNotReached is returned when less providers than required by quorum have screened a wallet successfully.
+var ErrQuorumNotReached = errors.New("riskprovider: quorum not reached")
+
+// ConsensusProvider represents a risk provider taking part in Consensus.
+type ConsensusProvider struct {
+	Name     string                                     // Name of the provider attributed to reported categories
+	Provider walletscreener.WalletRiskScreeningProvider // Provider implementation
+	Weight   float64                                    // Weight of the provider risk score, zero is treated as 1
+	Timeout  time.Duration                              // Maximum duration of a single screening, zero means no timeout
+}
+
+// weight returns weight of provider risk score.
+func (p *ConsensusProvider) weight() float64 {
+	if p.Weight <= 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+// Consensus is an implementation of walletscreener.WalletRiskScreeningProvider
+// screening wallets using multiple providers in parallel and merging their results.
+//
+// Merged result contains union of categories reported by providers with each category attributed to providers
+// reporting it, risk score is weighted average of providers risk scores.
+type Consensus struct {
+	providers []*ConsensusProvider
+	quorum    int
+	logger    log.Logger
+}
+
+// NewConsensus constructs and returns new Consensus instance.
+// Quorum is a minimum number of providers required to screen a wallet successfully for the result to be returned.
+func NewConsensus(logger log.Logger, quorum int, providers ...*ConsensusProvider) (*Consensus, error) {
+	if len(providers) < 1 {
+		return nil, errors.New("riskprovider: consensus requires at least one provider")
+	}
+
+	if quorum < 1 || quorum > len(providers) {
+		return nil, errors.Newf("riskprovider: quorum must be between 1 and %d, got %d", len(providers), quorum)
+	}
+
+	return &Consensus{
+		providers: providers,
+		quorum:    quorum,
+		logger:    logger,
+	}, nil
+}
+
+// consensusResponse represents a response of a single provider.
+type consensusResponse struct {
+	provider *ConsensusProvider
+	result   *walletscreener.ScreeningResult
+	err      error
+}
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+// ErrQuorumNotReached is returned if less providers than required by quorum succeed,
+// walletscreener.ErrChainNotSupported is returned only if none of the providers support the chain.
+func (c *Consensus) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	responses := make([]*consensusResponse, len(c.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range c.providers {
+		wg.Add(1)
+		go func(i int, p *ConsensusProvider) {
+			defer wg.Done()
+			result, err := c.screen(ctx, p, chain, address)
+			responses[i] = &consensusResponse{provider: p, result: result, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var (
+		succeeded   []*consensusResponse
+		errs        []error
+		unsupported int
+	)
+	for _, v := range responses {
+		switch {
+		case v.err == nil:
+			succeeded = append(succeeded, v)
+		case errors.Is(v.err, walletscreener.ErrChainNotSupported):
+			unsupported++
+		default:
+			c.logger.WithError(v.err).WithField("provider", v.provider.Name).Warn("risk provider failed to screen wallet")
+			errs = append(errs, errors.Wrapf(v.err, "provider %s", v.provider.Name))
+		}
+	}
+
+	if unsupported == len(c.providers) {
+		return nil, errors.Wrapf(walletscreener.ErrChainNotSupported, "consensus: chain %s", chain)
+	}
+
+	if len(succeeded) < c.quorum {
+		return nil, errors.Wrapf(errors.WithReason(ErrQuorumNotReached, errors.Join(errs...)), "%d out of %d required providers succeeded", len(succeeded), c.quorum)
+	}
+
+	return mergeScreeningResults(chain, address, succeeded), nil
+}
+
+// screen screens wallet using provider p limiting screening duration to provider timeout.
+func (c *Consensus) screen(ctx context.Context, p *ConsensusProvider, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withProviderTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	return p.Provider.GetRiskCategories(ctx, chain, address)
+}
+
+// mergeScreeningResults merges successful providers responses into a single screening result.
+func mergeScreeningResults(chain walletscreener.Chain, address string, responses []*consensusResponse) *walletscreener.ScreeningResult {
+	merged := walletscreener.ScreeningResult{
+		Chain:   chain,
+		Address: address,
+	}
+
+	var (
+		names         []string
+		caseIDs       []string
+		score, weight float64
+		own           = newCategoryMerger()
+		sourceOfFunds = newCategoryMerger()
+	)
+
+	for _, v := range responses {
+		names = append(names, v.provider.Name)
+		if v.result.CaseID != "" {
+			caseIDs = append(caseIDs, v.provider.Name+":"+v.result.CaseID)
+		}
+
+		score += float64(v.result.Risk) * v.provider.weight()
+		weight += v.provider.weight()
+
+		if merged.Entity == "" {
+			merged.Entity = v.result.Entity
+		}
+
+		if merged.RequestedAt.IsZero() || v.result.RequestedAt.Before(merged.RequestedAt) {
+			merged.RequestedAt = v.result.RequestedAt
+		}
+
+		if v.result.RespondedAt.After(merged.RespondedAt) {
+			merged.RespondedAt = v.result.RespondedAt
+		}
+
+		own.merge(v.provider.Name, v.result.OwnCategories)
+		sourceOfFunds.merge(v.provider.Name, v.result.SourceOfFundsCategories)
+	}
+
+	merged.Provider = strings.Join(names, ",")
+	merged.CaseID = strings.Join(caseIDs, ",")
+	merged.Risk = int(math.Round(score / weight))
+	merged.OwnCategories = own.categories
+	merged.SourceOfFundsCategories = sourceOfFunds.categories
+
+	return &merged
+}
+
+// categoryMerger merges categories reported by multiple providers into union of categories.
+type categoryMerger struct {
+	index      map[string]*walletscreener.RiskCategory
+	categories []*walletscreener.RiskCategory
+}
+
+// newCategoryMerger constructs a new categoryMerger.
+func newCategoryMerger() *categoryMerger {
+	return &categoryMerger{
+		index: make(map[string]*walletscreener.RiskCategory),
+	}
+}
+
+// merge merges categories reported by provider.
+// Categories are matched case insensitively by name, matching categories keep the highest risk reported.
+func (m *categoryMerger) merge(provider string, categories []*walletscreener.RiskCategory) {
+	for _, v := range categories {
+		key := strings.ToLower(v.Name)
+
+		category, ok := m.index[key]
+		if !ok {
+			category = &walletscreener.RiskCategory{
+				Name:   v.Name,
+				Entity: v.Entity,
+			}
+			m.index[key] = category
+			m.categories = append(m.categories, category)
+		}
+
+		if v.Risk > category.Risk {
+			category.Risk = v.Risk
+		}
+
+		if category.Entity == "" {
+			category.Entity = v.Entity
+		}
+
+		if !slices.Contains(category.Providers, provider) {
+			category.Providers = append(category.Providers, provider)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package riskprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newCategoriesProvider returns provider responding with given risk score and own categories.
+func newCategoriesProvider(risk int, categories ...*walletscreener.RiskCategory) providerFunc {
+	return func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+		return &walletscreener.ScreeningResult{
+			Chain:         chain,
+			Address:       address,
+			Risk:          risk,
+			OwnCategories: categories,
+			RequestedAt:   time.Date(2023, 10, 4, 15, 18, risk%60, 0, time.UTC),
+			RespondedAt:   time.Date(2023, 10, 4, 15, 19, risk%60, 0, time.UTC),
+		}, nil
+	}
+}
+
+func TestConsensus(t *testing.T) {
+	const address = "0xe9e9afac38e64728f1afbb2b65dec7be7c704c05"
+
+	var testcases = []struct {
+		quorum    int
+		providers []*ConsensusProvider
+
+		result *walletscreener.ScreeningResult
+		err    error
+	}{
+		// categories are merged and scores weighted
+		{
+			quorum: 2,
+			providers: []*ConsensusProvider{
+				{Name: "a", Weight: 3, Provider: newCategoriesProvider(40, &walletscreener.RiskCategory{Name: "Sanctions", Risk: 40})},
+				{Name: "b", Weight: 1, Provider: newCategoriesProvider(20, &walletscreener.RiskCategory{Name: "sanctions", Risk: 80}, &walletscreener.RiskCategory{Name: "Mixer", Risk: 20})},
+			},
+			result: &walletscreener.ScreeningResult{
+				Chain:    walletscreener.ChainEthereum,
+				Address:  address,
+				Provider: "a,b",
+				Risk:     35,
+				OwnCategories: []*walletscreener.RiskCategory{
+					{Name: "Sanctions", Risk: 80, Providers: []string{"a", "b"}},
+					{Name: "Mixer", Risk: 20, Providers: []string{"b"}},
+				},
+				RequestedAt: time.Date(2023, 10, 4, 15, 18, 20, 0, time.UTC),
+				RespondedAt: time.Date(2023, 10, 4, 15, 19, 40, 0, time.UTC),
+			},
+		},
+		// partial failure within quorum
+		{
+			quorum: 1,
+			providers: []*ConsensusProvider{
+				{Name: "a", Provider: newTestProvider(0, errors.New("outage"))},
+				{Name: "b", Provider: newCategoriesProvider(20)},
+				{Name: "c", Provider: slowProvider, Timeout: time.Millisecond},
+			},
+			result: &walletscreener.ScreeningResult{
+				Chain:       walletscreener.ChainEthereum,
+				Address:     address,
+				Provider:    "b",
+				Risk:        20,
+				RequestedAt: time.Date(2023, 10, 4, 15, 18, 20, 0, time.UTC),
+				RespondedAt: time.Date(2023, 10, 4, 15, 19, 20, 0, time.UTC),
+			},
+		},
+		// quorum not reached
+		{
+			quorum: 2,
+			providers: []*ConsensusProvider{
+				{Name: "a", Provider: newTestProvider(0, errors.New("outage"))},
+				{Name: "b", Provider: newCategoriesProvider(20)},
+			},
+			err: ErrQuorumNotReached,
+		},
+		// none of providers support chain
+		{
+			quorum: 1,
+			providers: []*ConsensusProvider{
+				{Name: "a", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+				{Name: "b", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+			},
+			err: walletscreener.ErrChainNotSupported,
+		},
+	}
+
+	for i, tt := range testcases {
+		consensus, err := NewConsensus(log.Default(), tt.quorum, tt.providers...)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		result, err := consensus.GetRiskCategories(context.Background(), walletscreener.ChainEthereum, address)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+
+		if !cmp.Equal(result, tt.result) {
+			t.Errorf("#%d got %v, want %v", i, result, tt.result)
+		}
+	}
+}
+
+func TestNewConsensus(t *testing.T) {
+	providers := []*ConsensusProvider{
+		{Name: "a", Provider: newTestProvider(0, nil)},
+	}
+
+	for _, quorum := range []int{0, 2} {
+		if _, err := NewConsensus(log.Default(), quorum, providers...); err == nil {
+			t.Errorf("quorum %d got %v, want error", quorum, err)
+		}
+	}
+}
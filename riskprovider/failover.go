@@ -0,0 +1,86 @@
+package riskprovider
+
+import (
+	"context"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+)
+
+// FailoverProvider represents a risk provider taking part in Failover.
+type FailoverProvider struct {
+	Name     string                                     // Name of the provider recorded in screening results
+	Provider walletscreener.WalletRiskScreeningProvider // Provider implementation
+	Timeout  time.Duration                              // Maximum duration of a single screening, zero means no timeout
+}
+
+// Failover is an implementation of walletscreener.WalletRiskScreeningProvider
+// screening wallets using ordered list of providers, next provider is tried only when the previous one fails.
+type Failover struct {
+	providers []*FailoverProvider
+	logger    log.Logger
+}
+
+// NewFailover constructs and returns new Failover instance trying providers in given order.
+func NewFailover(logger log.Logger, providers ...*FailoverProvider) (*Failover, error) {
+	if len(providers) < 1 {
+		return nil, errors.New("riskprovider: failover requires at least one provider") // nothing to fail over to
+	}
+
+	return &Failover{
+		providers: providers,
+		logger:    logger,
+	}, nil
+}
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+// Screening result of the first provider succeeding is returned with its name recorded in the result.
+// walletscreener.ErrChainNotSupported is returned only if none of the providers support the chain.
+func (f *Failover) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	var (
+		errs        []error
+		unsupported int
+	)
+
+	for _, p := range f.providers {
+		result, err := f.screen(ctx, p, chain, address)
+		if err == nil {
+			if p.Name != "" {
+				result.Provider = p.Name
+			}
+			return result, nil
+		}
+
+		// caller gave up, there is no point trying other providers
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if errors.Is(err, walletscreener.ErrChainNotSupported) {
+			unsupported++
+			continue
+		}
+
+		f.logger.WithError(err).WithField("provider", p.Name).Warn("risk provider failed, failing over to the next one")
+		errs = append(errs, errors.Wrapf(err, "provider %s", p.Name))
+	}
+
+	if unsupported == len(f.providers) {
+		return nil, errors.Wrapf(walletscreener.ErrChainNotSupported, "failover: chain %s", chain)
+	}
+
+	return nil, errors.Wrap(errors.Join(errs...), "failover: all providers failed")
+}
+
+// screen screens wallet using provider p limiting screening duration to provider timeout.
+func (f *Failover) screen(ctx context.Context, p *FailoverProvider, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withProviderTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	return p.Provider.GetRiskCategories(ctx, chain, address)
+}
@@ -0,0 +1,125 @@
+package riskprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+)
+
+// providerFunc is an adapter allowing to use ordinary functions as walletscreener.WalletRiskScreeningProvider.
+type providerFunc func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error)
+
+// GetRiskCategories implements walletscreener.WalletRiskScreeningProvider.
+func (f providerFunc) GetRiskCategories(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	return f(ctx, chain, address)
+}
+
+// newTestProvider returns provider responding with given risk score or error.
+func newTestProvider(risk int, err error) providerFunc {
+	return func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &walletscreener.ScreeningResult{Chain: chain, Address: address, Risk: risk}, nil
+	}
+}
+
+// slowProvider blocks until context is done.
+var slowProvider = providerFunc(func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.ScreeningResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+})
+
+func TestFailover(t *testing.T) {
+	var testcases = []struct {
+		providers []*FailoverProvider
+
+		provider string
+		risk     int
+		err      error
+	}{
+		// first provider answers
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: newTestProvider(10, nil)},
+				{Name: "second", Provider: newTestProvider(20, nil)},
+			},
+			provider: "first",
+			risk:     10,
+		},
+		// first provider fails
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: newTestProvider(0, errors.New("outage"))},
+				{Name: "second", Provider: newTestProvider(20, nil)},
+			},
+			provider: "second",
+			risk:     20,
+		},
+		// first provider times out
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: slowProvider, Timeout: time.Millisecond},
+				{Name: "second", Provider: newTestProvider(20, nil)},
+			},
+			provider: "second",
+			risk:     20,
+		},
+		// first provider does not support chain
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+				{Name: "second", Provider: newTestProvider(20, nil)},
+			},
+			provider: "second",
+			risk:     20,
+		},
+		// none of providers support chain
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+				{Name: "second", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+			},
+			err: walletscreener.ErrChainNotSupported,
+		},
+		// all providers fail
+		{
+			providers: []*FailoverProvider{
+				{Name: "first", Provider: newTestProvider(0, walletscreener.ErrChainNotSupported)},
+				{Name: "second", Provider: newTestProvider(0, context.DeadlineExceeded)},
+			},
+			err: context.DeadlineExceeded,
+		},
+	}
+
+	for i, tt := range testcases {
+		failover, err := NewFailover(log.Default(), tt.providers...)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		result, err := failover.GetRiskCategories(context.Background(), walletscreener.ChainEthereum, "0xe9e9afac38e64728f1afbb2b65dec7be7c704c05")
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+
+		if tt.err != nil {
+			if errors.Is(tt.err, walletscreener.ErrChainNotSupported) != errors.Is(err, walletscreener.ErrChainNotSupported) {
+				t.Errorf("#%d got %v, want %v", i, err, tt.err)
+			}
+			continue
+		}
+
+		if result.Provider != tt.provider {
+			t.Errorf("#%d provider got %v, want %v", i, result.Provider, tt.provider)
+		}
+
+		if result.Risk != tt.risk {
+			t.Errorf("#%d risk got %v, want %v", i, result.Risk, tt.risk)
+		}
+	}
+}
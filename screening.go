@@ -0,0 +1,80 @@
+package walletscreener
+
+import (
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/slices"
+)
+
+// RiskCategory represents a risk category a wallet or its source of funds is associated with.
+type RiskCategory struct {
+	Name      string   // Risk category name, e.g. Sanctions
+	Entity    string   // Name of the entity behind the wallet, if known
+	Risk      int      // Risk score of the category in range 0-100
+	Providers []string // Names of providers reporting the category when results of multiple providers are merged
+}
+
+// ScreeningResult represents result of a wallet screening performed by WalletRiskScreeningProvider.
+type ScreeningResult struct {
+	Chain    Chain  // Chain of the screened wallet
+	Address  string // Address of the screened wallet
+	Provider string // Name of the provider which screened the wallet
+	Entity   string // Name of the entity behind the wallet, if known
+	Risk     int    // Overall risk score of the wallet in range 0-100
+
+	OwnCategories           []*RiskCategory // Categories the wallet itself is associated with
+	SourceOfFundsCategories []*RiskCategory // Categories the wallet's source of funds is associated with
+
+	CaseID      string    // Screening reference assigned by the provider
+	RequestedAt time.Time // Time screening was requested at
+	RespondedAt time.Time // Time provider responded at
+	Cached      bool      // Whether result was served from cache instead of screening the wallet
+
+	// Verdict and MatchedRules are outcome of Policy evaluation, these are not set by providers.
+	Verdict      Verdict  // Verdict of the screening
+	MatchedRules []string // Policy rules resulting in the verdict
+}
+
+// Clone returns a deep copy of the result sharing no categories or rules with it.
+func (r *ScreeningResult) Clone() *ScreeningResult {
+	result := *r
+	result.OwnCategories = cloneRiskCategories(r.OwnCategories)
+	result.SourceOfFundsCategories = cloneRiskCategories(r.SourceOfFundsCategories)
+	result.MatchedRules = cloneStrings(r.MatchedRules)
+	return &result
+}
+
+// cloneRiskCategories returns a deep copy of categories, nil categories are kept nil.
+func cloneRiskCategories(categories []*RiskCategory) []*RiskCategory {
+	if categories == nil {
+		return nil
+	}
+
+	result := make([]*RiskCategory, 0, len(categories))
+	for _, v := range categories {
+		category := *v
+		category.Providers = cloneStrings(v.Providers)
+		result = append(result, &category)
+	}
+	return result
+}
+
+// cloneStrings returns a copy of s, nil s is kept nil.
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// Categories returns sorted list of unique risk category names of own and source of funds categories.
+func (r *ScreeningResult) Categories() []string {
+	categories := []string{}
+	for _, v := range r.OwnCategories {
+		categories = append(categories, v.Name)
+	}
+	for _, v := range r.SourceOfFundsCategories {
+		categories = append(categories, v.Name)
+	}
+	return slices.Unique(categories)
+}
@@ -0,0 +1,82 @@
+package walletscreener
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// Store errors.
+var (
+	ErrScreeningNotFound = errors.New("screening not found")                                 // wallet has no stored screenings
+	ErrScreeningTampered = errors.New("screening failed verification against trusted state") // stored data does not match its proof
+	ErrProofNotSupported = errors.New("store is not able to prove screenings")               // store does not implement ScreeningProver
+	ErrInvalidCursor     = errors.New("screenings cursor is not valid")                      // cursor was not returned by the store
+)
+
+// ScreeningsQuery represents a query of wallet screenings history.
+type ScreeningsQuery struct {
+	Chain      Chain
+	Address    string
+	Cursor     string    // Position to continue from as returned along the previous page, empty for the first page
+	Since      time.Time // Screenings recorded at or after, unbounded if zero
+	Until      time.Time // Screenings recorded before, unbounded if zero
+	Descending bool      // Whether the most recent screenings come first
+	Limit      int       // Maximum number of screenings, unbounded if zero
+}
+
+// ScreeningsPage represents a page of wallet screenings history.
+type ScreeningsPage struct {
+	Screenings []*Screening
+	Next       string // Cursor of the next page, empty if there are no more screenings
+}
+
+// ScreeningStore represents storage of wallet screenings kept for audit history purposes.
+type ScreeningStore interface {
+	// StoreScreening stores screening of a wallet as a single record.
+	// StoreScreening implements StoreScreeningFunc.
+	StoreScreening(ctx context.Context, screening *Screening) error
+
+	// GetWalletScreenings returns a page of history of screenings of the wallet matching the query.
+	// Cursors are opaque and specific to the store, ErrInvalidCursor is returned if query cursor was not returned by the store.
+	// Stores able to prove screenings were not tampered with mark them as verified, ErrScreeningTampered is returned if proof does not hold.
+	// GetWalletScreenings implements GetWalletScreeningsFunc.
+	GetWalletScreenings(ctx context.Context, query *ScreeningsQuery) (*ScreeningsPage, error)
+
+	// GetLatestScreening returns the most recently stored screening of the wallet on the given chain.
+	// ErrScreeningNotFound is returned if wallet has no stored screenings.
+	GetLatestScreening(ctx context.Context, chain Chain, address string) (*Screening, error)
+
+	// ListWallets returns addresses of wallets on the given chain having stored screenings.
+	ListWallets(ctx context.Context, chain Chain) ([]string, error)
+}
+
+// ScreeningProof represents a self-contained proof that a screening was stored and has not been altered since.
+type ScreeningProof struct {
+	Screening *Screening      // Proven screening
+	Format    string          // Format of proof material, identifies how proof is verified
+	Proof     json.RawMessage // Proof material laid out as defined by the format
+}
+
+// ScreeningProver represents storage able to prove stored screenings.
+type ScreeningProver interface {
+	// GetScreeningProof returns proof of the screening identified by id of the wallet on the given chain.
+	// ErrScreeningNotFound is returned if wallet has no such screening.
+	// GetScreeningProof implements GetScreeningProofFunc.
+	GetScreeningProof(ctx context.Context, chain Chain, address string, id string) (*ScreeningProof, error)
+}
+
+// Matches returns whether screening was recorded within the time range of the query.
+func (q *ScreeningsQuery) Matches(screening *Screening) bool {
+	if !q.Since.IsZero() && screening.ScreenedAt.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && !screening.ScreenedAt.Before(q.Until) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultRenewalLeeway is a duration before token expiry when token is renewed.
+const DefaultRenewalLeeway = 30 * time.Second
+
+// renewalTimeout is a duration exchange is given to complete regardless of callers waiting for it.
+const renewalTimeout = 30 * time.Second
+
+// ExchangeFunc exchanges credentials, e.g. API key, for a new JWT token.
+type ExchangeFunc func(ctx context.Context) (string, error)
+
+// Manager manages JWT token obtained by ExchangeFunc.
+// Token is renewed before it expires and after being invalidated, concurrent renewals are merged into a single exchange.
+// Manager is safe for concurrent use.
+type Manager struct {
+	exchange ExchangeFunc
+	leeway   time.Duration
+	timeout  time.Duration // renewal timeout
+	now      func() time.Time
+
+	mu        sync.RWMutex
+	token     string
+	expiresAt time.Time // zero when token does not expire
+
+	renewal singleflight.Group
+}
+
+// NewManager constructs and returns new Manager renewing tokens leeway before they expire.
+func NewManager(exchange ExchangeFunc, leeway time.Duration) *Manager {
+	return &Manager{
+		exchange: exchange,
+		leeway:   leeway,
+		timeout:  renewalTimeout,
+		now:      time.Now,
+	}
+}
+
+// Token returns a valid token, exchanging for a new one if current token is missing or about to expire.
+// Exchange shared by concurrent callers is made using its own context with renewal timeout, so that it is not interrupted
+// by the caller which started it giving up. Each caller waits for the exchange or until its own context is done.
+func (m *Manager) Token(ctx context.Context) (string, error) {
+	if token, ok := m.current(); ok {
+		return token, nil
+	}
+
+	ch := m.renewal.DoChan("token", func() (interface{}, error) {
+		// token might have been renewed while waiting
+		if token, ok := m.current(); ok {
+			return token, nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		return m.renew(ctx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	}
+}
+
+// Invalidate discards token, e.g. after being rejected by API, so that next call to Token exchanges for a new one.
+// Token is discarded only if it is still current, tokens renewed in the meantime are kept.
+func (m *Manager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token == token {
+		m.token = ""
+		m.expiresAt = time.Time{}
+	}
+}
+
+// current returns current token and whether it is valid for at least leeway duration.
+func (m *Manager) current() (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.token == "" {
+		return "", false
+	}
+
+	if !m.expiresAt.IsZero() && !m.now().Add(m.leeway).Before(m.expiresAt) {
+		return "", false
+	}
+
+	return m.token, true
+}
+
+// renew exchanges for a new token and makes it current.
+func (m *Manager) renew(ctx context.Context) (string, error) {
+	tkn, err := m.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := Parse(tkn)
+	if err != nil {
+		return "", err
+	}
+
+	var expiresAt time.Time
+	if token.Claims.ExpiresAt != nil {
+		expiresAt = token.Claims.ExpiresAt.Time
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.token = tkn
+	m.expiresAt = expiresAt
+
+	return tkn, nil
+}
@@ -0,0 +1,191 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newTestToken returns signed token expiring at given time, zero time means token never expires.
+func newTestToken(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+
+	claims := &Claims{Sub: "hi@deividaspetraitis.lt"}
+	if !expiresAt.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	return token
+}
+
+func TestManagerToken(t *testing.T) {
+	now := time.Now()
+
+	var testcases = []struct {
+		expiresAt time.Time
+		advance   time.Duration
+		exchanges int32
+	}{
+		// token does not expire
+		{
+			advance:   time.Hour,
+			exchanges: 1,
+		},
+		// token is valid
+		{
+			expiresAt: now.Add(time.Hour),
+			advance:   time.Minute,
+			exchanges: 1,
+		},
+		// token is about to expire
+		{
+			expiresAt: now.Add(time.Hour),
+			advance:   time.Hour - DefaultRenewalLeeway,
+			exchanges: 2,
+		},
+	}
+
+	for i, tt := range testcases {
+		var exchanges int32
+		manager := NewManager(func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&exchanges, 1)
+			return newTestToken(t, tt.expiresAt), nil
+		}, DefaultRenewalLeeway)
+		manager.now = func() time.Time { return now }
+
+		if _, err := manager.Token(context.Background()); err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		manager.now = func() time.Time { return now.Add(tt.advance) }
+
+		if _, err := manager.Token(context.Background()); err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		if exchanges != tt.exchanges {
+			t.Errorf("#%d exchanges got %v, want %v", i, exchanges, tt.exchanges)
+		}
+	}
+}
+
+func TestManagerTokenConcurrent(t *testing.T) {
+	var exchanges int32
+	release := make(chan struct{})
+	manager := NewManager(func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&exchanges, 1)
+		<-release
+		return newTestToken(t, time.Time{}), nil
+	}, DefaultRenewalLeeway)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := manager.Token(context.Background()); err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+		}()
+	}
+
+	// let goroutines join in-flight exchange
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if exchanges != 1 {
+		t.Errorf("exchanges got %v, want %v", exchanges, 1)
+	}
+}
+
+func TestManagerTokenCallerCanceled(t *testing.T) {
+	release := make(chan struct{})
+	manager := NewManager(func(ctx context.Context) (string, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return newTestToken(t, time.Time{}), nil
+	}, DefaultRenewalLeeway)
+
+	// the first caller starting exchange gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := manager.Token(ctx)
+		canceled <- err
+	}()
+
+	// let the first caller start exchange and the second one join it
+	time.Sleep(10 * time.Millisecond)
+	waiting := make(chan error)
+	go func() {
+		_, err := manager.Token(context.Background())
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if err := <-waiting; err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+}
+
+func TestManagerInvalidate(t *testing.T) {
+	var exchanges int32
+	manager := NewManager(func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&exchanges, 1)
+		return newTestToken(t, time.Now().Add(time.Duration(atomic.LoadInt32(&exchanges))*time.Hour)), nil
+	}, DefaultRenewalLeeway)
+
+	stale, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	manager.Invalidate(stale)
+
+	renewed, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if renewed == stale {
+		t.Errorf("got stale token, want renewed token")
+	}
+
+	// invalidating stale token again must keep the renewed one
+	manager.Invalidate(stale)
+
+	if token, _ := manager.Token(context.Background()); token != renewed || exchanges != 2 {
+		t.Errorf("got %v exchanges, want %v", exchanges, 2)
+	}
+}
+
+func TestManagerExchangeError(t *testing.T) {
+	exchangeErr := errors.New("exchange error")
+	manager := NewManager(func(ctx context.Context) (string, error) {
+		return "", exchangeErr
+	}, DefaultRenewalLeeway)
+
+	if _, err := manager.Token(context.Background()); !errors.Is(err, exchangeErr) {
+		t.Errorf("got %v, want %v", err, exchangeErr)
+	}
+}
@@ -0,0 +1,228 @@
+package validator
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Address validation errors.
+var (
+	ErrAddressEmpty    = errors.New("address is empty")
+	ErrAddressLength   = errors.New("address has invalid length")
+	ErrAddressPrefix   = errors.New("address has invalid prefix")
+	ErrAddressEncoding = errors.New("address has invalid encoding")
+	ErrAddressChecksum = errors.New("address checksum is not valid")
+	ErrAddressVersion  = errors.New("address has invalid version")
+)
+
+// EVM hexadecimal address is derived from the last 20 bytes
+// of the public key controlling the account with 0x appended in front.
+// e.g., 0x71C7656EC7ab88b098defB751B7401B5f6d8976F
+const evmAddressLength = 42
+
+// EVMAddress validates address of EVM compatible chain such as Ethereum or Polygon.
+// Mixed case addresses must contain a valid EIP-55 checksum, all lower or upper case addresses are accepted as is.
+func EVMAddress(address string) error {
+	if len(address) == 0 {
+		return ErrAddressEmpty
+	}
+
+	if len(address) != evmAddressLength {
+		return errors.Wrapf(ErrAddressLength, "expected %d characters, got %d", evmAddressLength, len(address))
+	}
+
+	if !strings.HasPrefix(address, "0x") {
+		return errors.Wrap(ErrAddressPrefix, "expected 0x prefix")
+	}
+
+	digits := address[2:]
+	if _, err := hex.DecodeString(digits); err != nil {
+		return errors.Wrap(ErrAddressEncoding, "address is not hexadecimal")
+	}
+
+	if strings.ToLower(digits) == digits || strings.ToUpper(digits) == digits {
+		return nil // checksum is not present
+	}
+
+	if eip55Checksum(digits) != digits {
+		return errors.Wrap(ErrAddressChecksum, "EIP-55 checksum mismatch")
+	}
+
+	return nil
+}
+
+// NormalizeEVMAddress returns valid EVM address in its EIP-55 checksummed form,
+// so that the same wallet is identified by the same address regardless of case it was given in.
+func NormalizeEVMAddress(address string) string {
+	return address[:2] + eip55Checksum(address[2:])
+}
+
+// eip55Checksum returns EIP-55 mixed case checksum encoding of hexadecimal address digits.
+func eip55Checksum(digits string) string {
+	lower := strings.ToLower(digits)
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	sum := hash.Sum(nil)
+
+	result := []byte(lower)
+	for i, c := range result {
+		if c < 'a' {
+			continue // digit
+		}
+
+		// uppercase letter if corresponding nibble of the hash is >= 8
+		nibble := sum[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			result[i] = c - 'a' + 'A'
+		}
+	}
+
+	return string(result)
+}
+
+// Bitcoin mainnet address parameters.
+const (
+	bitcoinP2PKHVersion = 0x00 // legacy pay to public key hash addresses, e.g. 1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2
+	bitcoinP2SHVersion  = 0x05 // pay to script hash addresses, e.g. 3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy
+	bitcoinBech32HRP    = "bc" // segregated witness addresses, e.g. bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq
+)
+
+// BitcoinAddress validates Bitcoin mainnet address.
+// Base58Check (P2PKH, P2SH), Bech32 (SegWit v0) and Bech32m (SegWit v1+, e.g. Taproot) addresses are supported.
+func BitcoinAddress(address string) error {
+	if len(address) == 0 {
+		return ErrAddressEmpty
+	}
+
+	if strings.HasPrefix(strings.ToLower(address), bitcoinBech32HRP+"1") {
+		return bitcoinSegwitAddress(address)
+	}
+
+	version, payload, err := base58CheckDecode(address)
+	if err != nil {
+		return err
+	}
+
+	if version != bitcoinP2PKHVersion && version != bitcoinP2SHVersion {
+		return errors.Wrapf(ErrAddressVersion, "unexpected version byte 0x%02x", version)
+	}
+
+	if len(payload) != 20 {
+		return errors.Wrapf(ErrAddressLength, "expected 20 bytes hash, got %d", len(payload))
+	}
+
+	return nil
+}
+
+// NormalizeBitcoinAddress returns valid Bitcoin address in its canonical form.
+// Bech32 addresses may be given in either case and are lower cased, Base58Check addresses are case sensitive and returned as is.
+func NormalizeBitcoinAddress(address string) string {
+	if strings.HasPrefix(strings.ToLower(address), bitcoinBech32HRP+"1") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// bitcoinSegwitAddress validates Bitcoin segregated witness address as defined in BIP-173 and BIP-350.
+func bitcoinSegwitAddress(address string) error {
+	hrp, data, encoding, err := bech32Decode(address)
+	if err != nil {
+		return err
+	}
+
+	if hrp != bitcoinBech32HRP {
+		return errors.Wrapf(ErrAddressPrefix, "expected %s human readable part, got %s", bitcoinBech32HRP, hrp)
+	}
+
+	if len(data) < 1 {
+		return errors.Wrap(ErrAddressLength, "witness version is missing")
+	}
+
+	version := data[0]
+	if version > 16 {
+		return errors.Wrapf(ErrAddressVersion, "unexpected witness version %d", version)
+	}
+
+	program, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return err
+	}
+
+	if len(program) < 2 || len(program) > 40 {
+		return errors.Wrapf(ErrAddressLength, "witness program must be between 2 and 40 bytes, got %d", len(program))
+	}
+
+	switch {
+	case version == 0 && encoding != bech32:
+		return errors.Wrap(ErrAddressEncoding, "witness version 0 address must use bech32 encoding")
+	case version == 0 && len(program) != 20 && len(program) != 32:
+		return errors.Wrapf(ErrAddressLength, "witness version 0 program must be 20 or 32 bytes, got %d", len(program))
+	case version != 0 && encoding != bech32m:
+		return errors.Wrapf(ErrAddressEncoding, "witness version %d address must use bech32m encoding", version)
+	}
+
+	return nil
+}
+
+// tronAddressVersion is a version byte of Tron mainnet addresses, e.g. TNPeeaaFB7K9cmo4uQpcU32zGK8G1NYqeL
+const tronAddressVersion = 0x41
+
+// TronAddress validates Base58Check encoded Tron mainnet address.
+func TronAddress(address string) error {
+	if len(address) == 0 {
+		return ErrAddressEmpty
+	}
+
+	if address[0] != 'T' {
+		return errors.Wrap(ErrAddressPrefix, "expected T prefix")
+	}
+
+	version, payload, err := base58CheckDecode(address)
+	if err != nil {
+		return err
+	}
+
+	if version != tronAddressVersion {
+		return errors.Wrapf(ErrAddressVersion, "unexpected version byte 0x%02x", version)
+	}
+
+	if len(payload) != 20 {
+		return errors.Wrapf(ErrAddressLength, "expected 20 bytes hash, got %d", len(payload))
+	}
+
+	return nil
+}
+
+// solanaAddressLength is a length of Solana public key in bytes.
+const solanaAddressLength = 32
+
+// SolanaAddress validates Base58 encoded Solana address, e.g. 7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV
+func SolanaAddress(address string) error {
+	if len(address) == 0 {
+		return ErrAddressEmpty
+	}
+
+	// 32 bytes encode into 32 to 44 base58 characters
+	if len(address) < 32 || len(address) > 44 {
+		return errors.Wrapf(ErrAddressLength, "expected 32 to 44 characters, got %d", len(address))
+	}
+
+	decoded, err := base58Decode(address)
+	if err != nil {
+		return err
+	}
+
+	if len(decoded) != solanaAddressLength {
+		return errors.Wrapf(ErrAddressLength, "expected %d bytes public key, got %d", solanaAddressLength, len(decoded))
+	}
+
+	return nil
+}
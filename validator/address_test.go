@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+func TestEVMAddress(t *testing.T) {
+	var testcases = []struct {
+		address string
+		err     error
+	}{
+		{"", ErrAddressEmpty},
+		{"0x", ErrAddressLength},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAedaa", ErrAddressLength},
+		{"005aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrAddressPrefix},
+		{"0xZZZZb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrAddressEncoding},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", ErrAddressChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", nil},
+		{"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB", nil},
+		{"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", nil},
+		{"0xe9e9afac38e64728f1afbb2b65dec7be7c704c05", nil},
+		{"0xE9E9AFAC38E64728F1AFBB2B65DEC7BE7C704C05", nil},
+	}
+
+	for _, tt := range testcases {
+		if err := EVMAddress(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("address %s got %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestNormalizeEVMAddress(t *testing.T) {
+	var testcases = []struct {
+		address  string
+		expected string
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+	}
+
+	for _, tt := range testcases {
+		if got := NormalizeEVMAddress(tt.address); got != tt.expected {
+			t.Errorf("got %v, want %v", got, tt.expected)
+		}
+	}
+}
+
+func TestBitcoinAddress(t *testing.T) {
+	var testcases = []struct {
+		address string
+		err     error
+	}{
+		{"", ErrAddressEmpty},
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", nil},
+		{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", nil},
+		{"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", nil},
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", nil},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", nil},
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", ErrAddressChecksum},
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN0", ErrAddressEncoding},
+		{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", ErrAddressVersion},                                          // testnet
+		{"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdp", ErrAddressChecksum},                                 // altered checksum
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", ErrAddressEncoding},                                 // v0 with bech32m
+		{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7k7grplx", ErrAddressEncoding}, // v1 with bech32
+		{"bc1QAR0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", ErrAddressEncoding},                                 // mixed case
+	}
+
+	for _, tt := range testcases {
+		if err := BitcoinAddress(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("address %s got %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestNormalizeBitcoinAddress(t *testing.T) {
+	var testcases = []struct {
+		address  string
+		expected string
+	}{
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2"},
+		{"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+	}
+
+	for _, tt := range testcases {
+		if got := NormalizeBitcoinAddress(tt.address); got != tt.expected {
+			t.Errorf("got %v, want %v", got, tt.expected)
+		}
+	}
+}
+
+func TestTronAddress(t *testing.T) {
+	var testcases = []struct {
+		address string
+		err     error
+	}{
+		{"", ErrAddressEmpty},
+		{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", nil},
+		{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", ErrAddressChecksum},
+		{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", ErrAddressPrefix},
+		{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6O", ErrAddressEncoding},
+	}
+
+	for _, tt := range testcases {
+		if err := TronAddress(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("address %s got %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestSolanaAddress(t *testing.T) {
+	var testcases = []struct {
+		address string
+		err     error
+	}{
+		{"", ErrAddressEmpty},
+		{"7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV", nil},
+		{"So11111111111111111111111111111111111111112", nil},
+		{"11111111111111111111111111111111", nil},
+		{"7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLt0", ErrAddressEncoding},
+		{"7EcDhSYGxXyscszYEp35KHN8vvw3", ErrAddressLength},
+	}
+
+	for _, tt := range testcases {
+		if err := SolanaAddress(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("address %s got %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
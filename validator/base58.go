@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet, shared by Tron and Solana.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Indexes maps ASCII characters to their base58 alphabet index, -1 for invalid characters.
+var base58Indexes = func() [256]int {
+	var indexes [256]int
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		indexes[c] = i
+	}
+	return indexes
+}()
+
+// base58Decode decodes base58 encoded string s.
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+
+	for i := 0; i < len(s); i++ {
+		index := base58Indexes[s[i]]
+		if index < 0 {
+			return nil, errors.Wrapf(ErrAddressEncoding, "invalid base58 character %q at position %d", s[i], i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(index)))
+	}
+
+	// every leading '1' stands for a leading zero byte
+	var zeros int
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// base58CheckDecode decodes Base58Check encoded string s and verifies its checksum.
+// It returns version byte and payload.
+func base58CheckDecode(s string) (byte, []byte, error) {
+	decoded, err := base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(decoded) < 5 {
+		return 0, nil, errors.Wrapf(ErrAddressLength, "decoded address is %d bytes long", len(decoded))
+	}
+
+	data, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	if expected := doubleSHA256(data); string(expected[:4]) != string(checksum) {
+		return 0, nil, errors.Wrap(ErrAddressChecksum, "base58check checksum mismatch")
+	}
+
+	return data[0], data[1:], nil
+}
+
+// doubleSHA256 returns SHA256(SHA256(b)).
+func doubleSHA256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// bech32Charset is the character set used by Bech32 and Bech32m encodings, see BIP-173.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Encoding identifies checksum variant of Bech32 encoded string.
+type bech32Encoding int
+
+const (
+	bech32  bech32Encoding = iota + 1 // BIP-173
+	bech32m                           // BIP-350
+)
+
+// Checksum constants of the encodings.
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32Polymod computes BCH checksum over values.
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand expands human readable part for checksum computation.
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// bech32Decode decodes Bech32 or Bech32m string s.
+// It returns human readable part, data part without checksum as 5-bit groups and detected encoding.
+func bech32Decode(s string) (string, []byte, bech32Encoding, error) {
+	if len(s) > 90 {
+		return "", nil, 0, errors.Wrapf(ErrAddressLength, "bech32 string must be at most 90 characters long, got %d", len(s))
+	}
+
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.Wrap(ErrAddressEncoding, "bech32 string must not be mixed case")
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, 0, errors.Wrap(ErrAddressEncoding, "bech32 separator is missing or misplaced")
+	}
+
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, errors.Wrapf(ErrAddressEncoding, "invalid bech32 human readable part character %q", hrp[i])
+		}
+	}
+
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		index := strings.IndexByte(bech32Charset, s[i])
+		if index < 0 {
+			return "", nil, 0, errors.Wrapf(ErrAddressEncoding, "invalid bech32 character %q at position %d", s[i], i)
+		}
+		data = append(data, byte(index))
+	}
+
+	var encoding bech32Encoding
+	switch bech32Polymod(append(bech32HRPExpand(hrp), data...)) {
+	case bech32Const:
+		encoding = bech32
+	case bech32mConst:
+		encoding = bech32m
+	default:
+		return "", nil, 0, errors.Wrap(ErrAddressChecksum, "bech32 checksum mismatch")
+	}
+
+	return hrp, data[:len(data)-6], encoding, nil
+}
+
+// convertBits regroups data of fromBits groups into toBits groups.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var (
+		acc    uint32
+		bits   uint
+		result []byte
+	)
+
+	maxv := uint32(1)<<toBits - 1
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, errors.Wrap(ErrAddressEncoding, "invalid data range")
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.Wrap(ErrAddressEncoding, "invalid padding")
+	}
+
+	return result, nil
+}
@@ -2,54 +2,226 @@ package walletscreener
 
 import (
 	"context"
+	"time"
 
 	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// RiskCategorySource represents whether risk category was assigned to the wallet itself or its source of funds.
+type RiskCategorySource string
+
+// Risk category sources.
+const (
+	RiskCategorySourceOwn           RiskCategorySource = "own"
+	RiskCategorySourceSourceOfFunds RiskCategorySource = "source_of_funds"
 )
 
-// HistoricalRiskCategory represents wallet historical risk category entity.
-type HistoricalRiskCategory struct {
-	Category string // Risk category
-	Revision uint64 //  Revision of the category
+// Screening represents a stored screening of a wallet, every screening is a separate record in wallet history.
+type Screening struct {
+	ID         string    // Unique identifier of the screening
+	Revision   uint64    // Revision of the wallet record, assigned by the store when screening is read
+	ScreenedAt time.Time // Time the screening was recorded at
+	Verified   bool      // Whether screening was cryptographically verified against trusted state of the store
+
+	ScreeningResult
+
+	Change *RiskChange // Change since the previous screening of the wallet, nil for the first one
+}
+
+// NewScreening constructs a new Screening record of result identified by a random ID.
+func NewScreening(result *ScreeningResult) *Screening {
+	return &Screening{
+		ID:              uuid.NewString(),
+		ScreenedAt:      time.Now().UTC(),
+		ScreeningResult: *result,
+	}
+}
+
+// Clone returns a deep copy of the screening sharing no categories, rules or change with it.
+func (s *Screening) Clone() *Screening {
+	screening := *s
+	screening.ScreeningResult = *s.ScreeningResult.Clone()
+
+	if s.Change != nil {
+		change := *s.Change
+		change.AddedCategories = cloneStrings(s.Change.AddedCategories)
+		change.RemovedCategories = cloneStrings(s.Change.RemovedCategories)
+		screening.Change = &change
+	}
+
+	return &screening
+}
+
+// Age returns how long ago screening was recorded at the given time.
+func (s *Screening) Age(now time.Time) time.Duration {
+	return now.Sub(s.ScreenedAt)
 }
 
-// StoreWalletRiskCategoriesFunc stores risk categories for a given wallet address into database.
-// This function is atomic, failure to store single category will result in failure storing the rest categories.
-type StoreWalletRiskCategoriesFunc func(ctx context.Context, address string, categories []string) error
+// StoreScreeningFunc stores screening of a given wallet into database as a single record.
+type StoreScreeningFunc func(ctx context.Context, screening *Screening) error
 
-// ScreenWalletRiskCategories screens a wallet to fetch risk categories list for the given address from RiskProvider.
-// Fetched categories will be stored into database for future reference.
-func ScreenWalletRiskCategories(ctx context.Context, riskprovider WalletRiskScreeningProvider, storeRiskCategories StoreWalletRiskCategoriesFunc, address string) ([]string, error) {
-	categories, err := riskprovider.GetRiskCategories(ctx, address)
+// ScreenWalletRiskCategories screens a wallet to fetch risk score and categories for the given address on the given chain from RiskProvider.
+// Screening result is evaluated against policy and along the verdict will be stored into database for future reference.
+// Stored screening records its change from the previous screening of the wallet retrieved by getLatest.
+// Results served from cache are stored same as fresh ones, Cached only marks that provider was not called for them.
+func ScreenWalletRiskCategories(ctx context.Context, riskprovider WalletRiskScreeningProvider, policy *Policy, getLatest GetLatestScreeningFunc, storeScreening StoreScreeningFunc, chain Chain, address string) (*Screening, error) {
+	result, err := riskprovider.GetRiskCategories(ctx, chain, address)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := storeRiskCategories(ctx, address, categories); err != nil {
+	decision := policy.Evaluate(result)
+	result.Verdict = decision.Verdict
+	result.MatchedRules = decision.MatchedRules
+
+	screening := NewScreening(result)
+
+	previous, err := getLatest(ctx, chain, address)
+	switch {
+	case errors.Is(err, ErrScreeningNotFound):
+		// the first screening of the wallet has nothing to change from
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to fetch previous screening")
+	default:
+		screening.Change = NewRiskChange(previous, screening)
+	}
+
+	if err := storeScreening(ctx, screening); err != nil {
 		return nil, err
 	}
 
-	return categories, nil
+	return screening, nil
+}
+
+// DefaultScreeningTimeout is a duration screening shared by ScreeningGroup callers is given to complete.
+const DefaultScreeningTimeout = time.Minute
+
+// ScreeningGroup deduplicates concurrent screenings of the same wallet.
+// Callers screening a wallet while screening of the same wallet is in flight share its provider call, stored record and result.
+// Callers bypassing cached results share screenings only with other callers bypassing them.
+// The zero value is ready to use.
+type ScreeningGroup struct {
+	Timeout time.Duration // Timeout of shared screening, DefaultScreeningTimeout if not set
+
+	group singleflight.Group
 }
 
-// GetWalletRiskCategories retrieves list of risk categories as slice of strings along revisions
-// as slice of uint64 for given wallet address from the database.
-// Length of categories and revisions always are the same and are indexed in same order.
-type GetWalletRiskCategoriesFunc func(ctx context.Context, address string) ([]string, []uint64, error)
+// ScreenWalletRiskCategories screens a wallet same as ScreenWalletRiskCategories unless screening of the wallet is already in flight.
+// Shared screening carries values of the first caller context, e.g. cache bypass, but it is not canceled along it,
+// instead it is given its own timeout so that callers giving up do not fail others. Each caller waits for it or until its own context is done.
+func (g *ScreeningGroup) ScreenWalletRiskCategories(ctx context.Context, riskprovider WalletRiskScreeningProvider, policy *Policy, getLatest GetLatestScreeningFunc, storeScreening StoreScreeningFunc, chain Chain, address string) (*Screening, error) {
+	key := chain.String() + ":" + address
+	if IsCacheBypassed(ctx) {
+		key += ":nocache"
+	}
+
+	ch := g.group.DoChan(key, func() (interface{}, error) {
+		timeout := g.Timeout
+		if timeout <= 0 {
+			timeout = DefaultScreeningTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(detachedContext{ctx}, timeout)
+		defer cancel()
+
+		return ScreenWalletRiskCategories(ctx, riskprovider, policy, getLatest, storeScreening, chain, address)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case v := <-ch:
+		if v.Err != nil {
+			return nil, v.Err
+		}
+
+		// every caller receives its own copy of shared screening
+		return v.Val.(*Screening).Clone(), nil
+	}
+}
 
-// GetWalletRiskCategoriesHistory retrieves history of risk categories for given wallet address.
-func GetWalletRiskCategoriesHistory(ctx context.Context, getRiskCategories GetWalletRiskCategoriesFunc, address string) ([]*HistoricalRiskCategory, error) {
-	categories, revision, err := getRiskCategories(ctx, address)
+// detachedContext carries values of its parent context but it is never canceled nor has a deadline.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline implements context.Context.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Limits of screenings returned per page of wallet history.
+const (
+	DefaultScreeningsLimit = 100  // used when query has no limit
+	MaxScreeningsLimit     = 1000 // greater limits are capped
+)
+
+// GetWalletScreeningsFunc retrieves a page of stored screenings matching the query from the database.
+type GetWalletScreeningsFunc func(ctx context.Context, query *ScreeningsQuery) (*ScreeningsPage, error)
+
+// GetWalletScreeningsHistory retrieves a page of history of screenings matching the query.
+// Query limit defaults to DefaultScreeningsLimit and is capped at MaxScreeningsLimit.
+func GetWalletScreeningsHistory(ctx context.Context, getScreenings GetWalletScreeningsFunc, query ScreeningsQuery) (*ScreeningsPage, error) {
+	if query.Limit < 1 {
+		query.Limit = DefaultScreeningsLimit
+	}
+
+	if query.Limit > MaxScreeningsLimit {
+		query.Limit = MaxScreeningsLimit
+	}
+
+	page, err := getScreenings(ctx, &query)
+	if errors.Is(err, ErrScreeningTampered) || errors.Is(err, ErrInvalidCursor) {
+		return nil, err
+	}
 	if err != nil {
-		return nil, errors.New("failed to fetch historical categories")
+		return nil, errors.New("failed to fetch historical screenings")
 	}
 
-	var result []*HistoricalRiskCategory
-	for i, v := range categories {
-		result = append(result, &HistoricalRiskCategory{
-			Category: v,
-			Revision: revision[i],
-		})
+	return page, nil
+}
+
+// GetLatestScreeningFunc retrieves the most recently stored screening of given wallet address on the given chain from the database.
+type GetLatestScreeningFunc func(ctx context.Context, chain Chain, address string) (*Screening, error)
+
+// GetLatestScreening retrieves the most recently stored screening of given wallet address on the given chain without screening it again.
+func GetLatestScreening(ctx context.Context, getLatest GetLatestScreeningFunc, chain Chain, address string) (*Screening, error) {
+	screening, err := getLatest(ctx, chain, address)
+	if errors.Is(err, ErrScreeningNotFound) || errors.Is(err, ErrScreeningTampered) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch latest screening")
+	}
+
+	return screening, nil
+}
+
+// GetScreeningProofFunc retrieves proof of the stored screening identified by id of given wallet address on the given chain.
+type GetScreeningProofFunc func(ctx context.Context, chain Chain, address string, id string) (*ScreeningProof, error)
+
+// GetScreeningProof retrieves proof of the stored screening identified by id of given wallet address on the given chain.
+func GetScreeningProof(ctx context.Context, getProof GetScreeningProofFunc, chain Chain, address string, id string) (*ScreeningProof, error) {
+	proof, err := getProof(ctx, chain, address, id)
+	if errors.Is(err, ErrScreeningNotFound) || errors.Is(err, ErrScreeningTampered) || errors.Is(err, ErrProofNotSupported) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch screening proof")
 	}
 
-	return result, nil
+	return proof, nil
 }
@@ -0,0 +1,422 @@
+package walletscreener
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// providerFunc is an adapter allowing use of ordinary function as WalletRiskScreeningProvider.
+type providerFunc func(ctx context.Context, chain Chain, address string) (*ScreeningResult, error)
+
+// GetRiskCategories implements WalletRiskScreeningProvider.
+func (f providerFunc) GetRiskCategories(ctx context.Context, chain Chain, address string) (*ScreeningResult, error) {
+	return f(ctx, chain, address)
+}
+
+// noScreenings retrieves latest screening of a wallet never screened before.
+func noScreenings(ctx context.Context, chain Chain, address string) (*Screening, error) {
+	return nil, ErrScreeningNotFound
+}
+
+// blockingProvider returns provider counting calls and responding once release is closed.
+func blockingProvider(calls *int32, release <-chan struct{}) providerFunc {
+	return func(ctx context.Context, chain Chain, address string) (*ScreeningResult, error) {
+		atomic.AddInt32(calls, 1)
+		<-release
+		return &ScreeningResult{Chain: chain, Address: address, Risk: 10}, nil
+	}
+}
+
+func TestScreenWalletRiskCategoriesStoresScreening(t *testing.T) {
+	var testcases = []struct {
+		result *ScreeningResult
+		stored bool
+	}{
+		// clean result is stored as well
+		{
+			result: &ScreeningResult{Chain: ChainEthereum, Address: "a"},
+			stored: true,
+		},
+		// cached result is stored as well, provider was not called for it only
+		{
+			result: &ScreeningResult{Chain: ChainEthereum, Address: "a", Cached: true},
+			stored: true,
+		},
+	}
+
+	policy, _ := NewPolicy(nil)
+
+	for i, tt := range testcases {
+		var stored *Screening
+
+		provider := providerFunc(func(ctx context.Context, chain Chain, address string) (*ScreeningResult, error) {
+			return tt.result, nil
+		})
+		store := func(ctx context.Context, screening *Screening) error {
+			stored = screening
+			return nil
+		}
+
+		screening, err := ScreenWalletRiskCategories(context.Background(), provider, policy, noScreenings, store, ChainEthereum, "a")
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		if (stored != nil) != tt.stored {
+			t.Errorf("#%d stored got %v, want %v", i, stored != nil, tt.stored)
+		}
+
+		if hasID := screening.ID != ""; hasID != tt.stored {
+			t.Errorf("#%d screening ID got %q, want ID %v", i, screening.ID, tt.stored)
+		}
+
+		if stored != nil && stored.ID != screening.ID {
+			t.Errorf("#%d stored ID got %v, want %v", i, stored.ID, screening.ID)
+		}
+	}
+}
+
+func TestScreenWalletRiskCategoriesRiskChange(t *testing.T) {
+	previous := &Screening{
+		ID: "previous",
+		ScreeningResult: ScreeningResult{
+			Chain:         ChainEthereum,
+			Address:       "a",
+			Risk:          20,
+			OwnCategories: []*RiskCategory{{Name: "Gambling"}},
+			Verdict:       VerdictAllow,
+		},
+	}
+
+	var testcases = []struct {
+		getLatest GetLatestScreeningFunc
+
+		change *RiskChange
+		err    error
+	}{
+		// change from the previous screening is recorded
+		{
+			getLatest: func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+				return previous, nil
+			},
+			change: &RiskChange{
+				PreviousID:        "previous",
+				PreviousVerdict:   VerdictAllow,
+				AddedCategories:   []string{"Sanctions"},
+				RemovedCategories: []string{"Gambling"},
+				RiskDelta:         80,
+			},
+		},
+		// the first screening has no change
+		{
+			getLatest: noScreenings,
+		},
+		// change is not recorded against tampered history
+		{
+			getLatest: func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+				return nil, ErrScreeningTampered
+			},
+			err: ErrScreeningTampered,
+		},
+	}
+
+	policy, _ := NewPolicy(nil)
+	provider := providerFunc(func(ctx context.Context, chain Chain, address string) (*ScreeningResult, error) {
+		return &ScreeningResult{Chain: chain, Address: address, Risk: 100, OwnCategories: []*RiskCategory{{Name: "Sanctions"}}}, nil
+	})
+
+	for i, tt := range testcases {
+		var stored *Screening
+		store := func(ctx context.Context, screening *Screening) error {
+			stored = screening
+			return nil
+		}
+
+		_, err := ScreenWalletRiskCategories(context.Background(), provider, policy, tt.getLatest, store, ChainEthereum, "a")
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("#%d got %v, want %v", i, err, tt.err)
+		}
+
+		if tt.err != nil {
+			if stored != nil {
+				t.Errorf("#%d got stored %+v, want %v", i, stored, nil)
+			}
+			continue
+		}
+
+		if diff := cmp.Diff(tt.change, stored.Change); diff != "" {
+			t.Errorf("#%d change mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestScreeningGroupDeduplicatesConcurrentScreenings(t *testing.T) {
+	var (
+		screenings       ScreeningGroup
+		calls, stored    int32
+		release          = make(chan struct{})
+		provider         = blockingProvider(&calls, release)
+		policy, _        = NewPolicy(nil)
+		wg               sync.WaitGroup
+		addresses        = []string{"a", "a", "a", "a", "b", "b"}
+		results          = make([]*Screening, len(addresses))
+		storeScreeningFn = func(ctx context.Context, screening *Screening) error {
+			atomic.AddInt32(&stored, 1)
+			return nil
+		}
+	)
+
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+
+			result, err := screenings.ScreenWalletRiskCategories(context.Background(), provider, policy, noScreenings, storeScreeningFn, ChainEthereum, address)
+			if err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+			results[i] = result
+		}(i, address)
+	}
+
+	// let goroutines join in-flight screenings
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// one screening per unique address
+	if calls != 2 {
+		t.Errorf("provider calls got %v, want %v", calls, 2)
+	}
+
+	if stored != 2 {
+		t.Errorf("stored results got %v, want %v", stored, 2)
+	}
+
+	for i, result := range results {
+		if result == nil || result.Address != addresses[i] || result.Verdict != VerdictAllow {
+			t.Errorf("#%d got %+v, want screening result of %s", i, result, addresses[i])
+		}
+	}
+
+	if results[0] == results[1] {
+		t.Errorf("got shared result, want copy per caller")
+	}
+
+	if results[0].ID != results[1].ID || results[0].ID == results[4].ID {
+		t.Errorf("got screening IDs %v, want one screening per unique address", []string{results[0].ID, results[1].ID, results[4].ID})
+	}
+
+	// screenings following completed ones are not deduplicated
+	if _, err := screenings.ScreenWalletRiskCategories(context.Background(), provider, policy, noScreenings, storeScreeningFn, ChainEthereum, "a"); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+
+	if calls != 3 {
+		t.Errorf("provider calls got %v, want %v", calls, 3)
+	}
+}
+
+func TestScreeningGroupCallerContext(t *testing.T) {
+	var (
+		screenings ScreeningGroup
+		calls      int32
+		release    = make(chan struct{})
+		provider   = blockingProvider(&calls, release)
+		policy, _  = NewPolicy(nil)
+		store      = func(ctx context.Context, screening *Screening) error { return nil }
+	)
+	defer close(release)
+
+	go screenings.ScreenWalletRiskCategories(context.Background(), provider, policy, noScreenings, store, ChainEthereum, "a")
+	time.Sleep(10 * time.Millisecond)
+
+	// waiting caller gives up once its context is done, leaving shared screening in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := screenings.ScreenWalletRiskCategories(ctx, provider, policy, noScreenings, store, ChainEthereum, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("provider calls got %v, want %v", calls, 1)
+	}
+}
+
+func TestScreeningGroupFirstCallerCanceled(t *testing.T) {
+	var (
+		screenings ScreeningGroup
+		calls      int32
+		release    = make(chan struct{})
+		provider   = blockingProvider(&calls, release)
+		policy, _  = NewPolicy(nil)
+		store      = func(ctx context.Context, screening *Screening) error { return ctx.Err() }
+		first      = make(chan error, 1)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, err := screenings.ScreenWalletRiskCategories(ctx, provider, policy, noScreenings, store, ChainEthereum, "a")
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := screenings.ScreenWalletRiskCategories(context.Background(), provider, policy, noScreenings, store, ChainEthereum, "a")
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// first caller giving up does not cancel shared screening
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("provider calls got %v, want %v", calls, 1)
+	}
+}
+
+func TestScreeningGroupCacheBypass(t *testing.T) {
+	var (
+		screenings ScreeningGroup
+		calls      int32
+		release    = make(chan struct{})
+		provider   = blockingProvider(&calls, release)
+		policy, _  = NewPolicy(nil)
+		store      = func(ctx context.Context, screening *Screening) error { return nil }
+		wg         sync.WaitGroup
+		contexts   = []context.Context{
+			context.Background(),
+			context.Background(),
+			WithCacheBypass(context.Background()),
+			WithCacheBypass(context.Background()),
+		}
+	)
+
+	for _, ctx := range contexts {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+
+			if _, err := screenings.ScreenWalletRiskCategories(ctx, provider, policy, noScreenings, store, ChainEthereum, "a"); err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+		}(ctx)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// callers bypassing cache are not served screening which might come from cache
+	if calls != 2 {
+		t.Errorf("provider calls got %v, want %v", calls, 2)
+	}
+}
+
+func TestScreeningGroupResultCopy(t *testing.T) {
+	var (
+		screenings ScreeningGroup
+		release    = make(chan struct{})
+		policy, _  = NewPolicy(nil)
+		store      = func(ctx context.Context, screening *Screening) error { return nil }
+		latest     = func(ctx context.Context, chain Chain, address string) (*Screening, error) {
+			return &Screening{ID: "1", ScreeningResult: ScreeningResult{Verdict: VerdictAllow}}, nil
+		}
+		provider = func(ctx context.Context, chain Chain, address string) (*ScreeningResult, error) {
+			<-release
+			return &ScreeningResult{
+				Chain:         chain,
+				Address:       address,
+				OwnCategories: []*RiskCategory{{Name: "Gambling", Risk: 10, Providers: []string{"blockmate"}}},
+			}, nil
+		}
+		wg      sync.WaitGroup
+		results = make([]*Screening, 2)
+	)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			result, err := screenings.ScreenWalletRiskCategories(context.Background(), providerFunc(provider), policy, latest, store, ChainEthereum, "a")
+			if err != nil {
+				t.Errorf("got %v, want %v", err, nil)
+			}
+			results[i] = result
+		}(i)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if results[0] == nil || results[1] == nil || results[0].Change == nil || results[1].Change == nil {
+		t.Fatalf("got %+v, want screenings with risk change", results)
+	}
+
+	// modifying result of one caller does not affect the others
+	results[0].OwnCategories[0].Name = "Sanctions"
+	results[0].OwnCategories[0].Providers[0] = "other"
+	results[0].Change.AddedCategories[0] = "Sanctions"
+	results[0].Change.PreviousID = "2"
+
+	if name := results[1].OwnCategories[0].Name; name != "Gambling" {
+		t.Errorf("category got %v, want %v", name, "Gambling")
+	}
+
+	if provider := results[1].OwnCategories[0].Providers[0]; provider != "blockmate" {
+		t.Errorf("category provider got %v, want %v", provider, "blockmate")
+	}
+
+	if added := results[1].Change.AddedCategories; !cmp.Equal(added, []string{"Gambling"}) {
+		t.Errorf("added categories got %v, want %v", added, []string{"Gambling"})
+	}
+
+	if id := results[1].Change.PreviousID; id != "1" {
+		t.Errorf("previous ID got %v, want %v", id, "1")
+	}
+}
+
+func TestGetWalletScreeningsHistoryLimit(t *testing.T) {
+	var testcases = []struct {
+		limit    int
+		expected int
+	}{
+		{0, DefaultScreeningsLimit},
+		{10, 10},
+		{MaxScreeningsLimit + 1, MaxScreeningsLimit},
+	}
+
+	for _, tt := range testcases {
+		var limit int
+		_, err := GetWalletScreeningsHistory(context.Background(), func(ctx context.Context, query *ScreeningsQuery) (*ScreeningsPage, error) {
+			limit = query.Limit
+			return &ScreeningsPage{}, nil
+		}, ScreeningsQuery{Chain: ChainEthereum, Address: "a", Limit: tt.limit})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+
+		if limit != tt.expected {
+			t.Errorf("limit %d got %v, want %v", tt.limit, limit, tt.expected)
+		}
+	}
+}
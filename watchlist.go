@@ -0,0 +1,134 @@
+package walletscreener
+
+import (
+	"context"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// MinWatchInterval is the shortest interval a watched wallet is re-screened at.
+// Re-screening more often burns provider quota without practical benefit.
+const MinWatchInterval = time.Hour
+
+// Watchlist errors.
+var (
+	ErrWatchNotFound        = errors.New("wallet is not watched")                                 // watchlist does not hold such wallet
+	ErrWatchIntervalInvalid = errors.Newf("watch interval must be at least %s", MinWatchInterval) // interval is shorter than MinWatchInterval
+)
+
+// Watch represents a wallet re-screened periodically.
+type Watch struct {
+	Chain     Chain
+	Address   string
+	Interval  time.Duration // How often wallet is re-screened
+	CreatedAt time.Time
+
+	NextAt         time.Time // When wallet is due to be re-screened
+	LastScreenedAt time.Time // When wallet was last re-screened successfully, zero until then
+	LastError      string    // Why the last re-screening failed, empty if it succeeded
+}
+
+// WatchlistStore represents storage of watched wallets.
+type WatchlistStore interface {
+	// PutWatch stores watch of the wallet replacing the previous one.
+	// PutWatch implements PutWatchFunc.
+	PutWatch(ctx context.Context, watch *Watch) error
+
+	// GetWatch returns watch of the wallet on the given chain, ErrWatchNotFound is returned if wallet is not watched.
+	// GetWatch implements GetWatchFunc.
+	GetWatch(ctx context.Context, chain Chain, address string) (*Watch, error)
+
+	// DeleteWatch stops watching the wallet on the given chain, ErrWatchNotFound is returned if wallet is not watched.
+	// DeleteWatch implements DeleteWatchFunc.
+	DeleteWatch(ctx context.Context, chain Chain, address string) error
+
+	// ListWatches returns every watched wallet.
+	// ListWatches implements ListWatchesFunc.
+	ListWatches(ctx context.Context) ([]*Watch, error)
+}
+
+// PutWatchFunc stores watch of a wallet.
+type PutWatchFunc func(ctx context.Context, watch *Watch) error
+
+// GetWatchFunc retrieves watch of a wallet.
+type GetWatchFunc func(ctx context.Context, chain Chain, address string) (*Watch, error)
+
+// DeleteWatchFunc stops watching a wallet.
+type DeleteWatchFunc func(ctx context.Context, chain Chain, address string) error
+
+// ListWatchesFunc retrieves every watched wallet.
+type ListWatchesFunc func(ctx context.Context) ([]*Watch, error)
+
+// JitterFunc returns random delay of re-screening by the interval so that wallets registered together spread over time.
+type JitterFunc func(interval time.Duration) time.Duration
+
+// WatchWallet registers wallet to be re-screened every interval starting from now, first re-screening is delayed by jitter too.
+// Wallet already watched keeps its history and is rescheduled by the new interval counting from its last re-screening.
+func WatchWallet(ctx context.Context, getWatch GetWatchFunc, putWatch PutWatchFunc, jitter JitterFunc, chain Chain, address string, interval time.Duration, now time.Time) (*Watch, error) {
+	if interval < MinWatchInterval {
+		return nil, errors.Wrapf(ErrWatchIntervalInvalid, "got %s", interval)
+	}
+
+	watch, err := getWatch(ctx, chain, address)
+	switch {
+	case errors.Is(err, ErrWatchNotFound):
+		watch = &Watch{
+			Chain:     chain,
+			Address:   address,
+			CreatedAt: now,
+		}
+	case err != nil:
+		return nil, errors.New("failed to fetch watch")
+	}
+
+	from := now
+	if !watch.LastScreenedAt.IsZero() {
+		from = watch.LastScreenedAt
+	}
+
+	watch.Interval = interval
+	watch.NextAt = from.Add(interval + jitter(interval))
+
+	if err := putWatch(ctx, watch); err != nil {
+		return nil, errors.New("failed to store watch")
+	}
+
+	return watch, nil
+}
+
+// GetWatch retrieves watch of the wallet on the given chain.
+func GetWatch(ctx context.Context, getWatch GetWatchFunc, chain Chain, address string) (*Watch, error) {
+	watch, err := getWatch(ctx, chain, address)
+	if errors.Is(err, ErrWatchNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch watch")
+	}
+
+	return watch, nil
+}
+
+// UnwatchWallet stops re-screening the wallet on the given chain, screenings stored so far are kept.
+func UnwatchWallet(ctx context.Context, deleteWatch DeleteWatchFunc, chain Chain, address string) error {
+	err := deleteWatch(ctx, chain, address)
+	if errors.Is(err, ErrWatchNotFound) {
+		return err
+	}
+	if err != nil {
+		return errors.New("failed to delete watch")
+	}
+
+	return nil
+}
+
+// ListWatches retrieves every watched wallet.
+func ListWatches(ctx context.Context, listWatches ListWatchesFunc) ([]*Watch, error) {
+	watches, err := listWatches(ctx)
+	if err != nil {
+		return nil, errors.New("failed to list watches")
+	}
+
+	return watches, nil
+}
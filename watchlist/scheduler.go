@@ -0,0 +1,223 @@
+package watchlist
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults of watchlist configuration.
+const (
+	DefaultPoll        = 30 * time.Second
+	DefaultConcurrency = 4
+	DefaultJitter      = 0.1
+	DefaultRateLimit   = 60
+	DefaultRetryDelay  = 5 * time.Minute
+)
+
+// Config represents watchlist scheduler configuration, defaults are used for values not set.
+type Config struct {
+	Poll        time.Duration `mapstructure:"poll"`        // How often watchlist is checked for wallets due
+	Concurrency int           `mapstructure:"concurrency"` // Wallets re-screened at once
+	Jitter      float64       `mapstructure:"jitter"`      // Fraction of interval re-screenings are randomly delayed by, 0 disables jitter and negative value is unset
+	RateLimit   int           `mapstructure:"ratelimit"`   // Re-screenings per minute, keeps provider quota for requests
+	RetryDelay  time.Duration `mapstructure:"retrydelay"`  // How soon failed re-screening is retried, at most interval of the wallet
+}
+
+// withDefaults returns copy of the configuration with defaults set for values not set, cfg may be nil.
+func (cfg *Config) withDefaults() Config {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Poll <= 0 {
+		c.Poll = DefaultPoll
+	}
+	if c.Concurrency < 1 {
+		c.Concurrency = DefaultConcurrency
+	}
+	if cfg == nil || c.Jitter < 0 {
+		c.Jitter = DefaultJitter
+	}
+	if c.RateLimit < 1 {
+		c.RateLimit = DefaultRateLimit
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+	return c
+}
+
+// Scheduler re-screens watched wallets once they are due.
+// Re-screenings bypass cached results so that every one of them is stored into history.
+// Next re-screening is delayed by random jitter so that wallets registered together spread over time.
+type Scheduler struct {
+	watchlist walletscreener.WatchlistStore
+	screen    walletscreener.ScreenWalletFunc
+	cfg       Config
+	logger    log.Logger
+	limiter   *rate.Limiter
+
+	now  func() time.Time
+	rand func() float64 // random number in [0, 1) scaling jitter
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler constructs and returns new Scheduler re-screening wallets of watchlist by screen, cfg may be nil.
+func NewScheduler(watchlist walletscreener.WatchlistStore, screen walletscreener.ScreenWalletFunc, cfg *Config, logger log.Logger) *Scheduler {
+	c := cfg.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		watchlist: watchlist,
+		screen:    screen,
+		cfg:       c,
+		logger:    logger,
+		limiter:   rate.NewLimiter(rate.Every(time.Minute/time.Duration(c.RateLimit)), 1),
+		now:       func() time.Time { return time.Now().UTC() },
+		rand:      rand.Float64,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Jitter returns random delay of re-screening by the interval, up to configured fraction of it.
+// Jitter implements walletscreener.JitterFunc.
+func (s *Scheduler) Jitter(interval time.Duration) time.Duration {
+	return time.Duration(s.rand() * s.cfg.Jitter * float64(interval))
+}
+
+// Start starts re-screening wallets until Shutdown.
+func (s *Scheduler) Start() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.cfg.Poll)
+		defer ticker.Stop()
+
+		for {
+			s.run()
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops scheduler and waits until re-screenings in progress are interrupted.
+// Interrupted wallets are left due and re-screened once scheduler is started again.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "re-screenings were not interrupted in time")
+	}
+}
+
+// run re-screens wallets due by now, most overdue first, and waits for them to finish.
+func (s *Scheduler) run() {
+	watches, err := s.watchlist.ListWatches(s.ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("unable to list watched wallets")
+		return
+	}
+
+	now := s.now()
+
+	var due []*walletscreener.Watch
+	for _, v := range watches {
+		if !v.NextAt.After(now) {
+			due = append(due, v)
+		}
+	}
+
+	if len(due) < 1 {
+		return
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAt.Before(due[j].NextAt)
+	})
+
+	wallets := make([]walletscreener.Wallet, len(due))
+	for i, v := range due {
+		wallets[i] = walletscreener.Wallet{Chain: v.Chain, Address: v.Address}
+	}
+
+	// wallets due are screened same as a batch, each one waits for its share of provider quota
+	screen := func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return s.screen(ctx, chain, address)
+	}
+
+	batch := &walletscreener.BatchConfig{Concurrency: s.cfg.Concurrency, MaxWallets: len(wallets)}
+
+	err = walletscreener.ScreenWalletsEach(walletscreener.WithCacheBypass(s.ctx), screen, batch, wallets, func(i int, result *walletscreener.WalletScreening) {
+		// wallets interrupted by shutdown are left due
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		s.record(result)
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("unable to re-screen watched wallets")
+	}
+}
+
+// record records outcome of re-screening into watch of the wallet and schedules the next one.
+// Wallets unwatched while being re-screened are left unwatched.
+func (s *Scheduler) record(result *walletscreener.WalletScreening) {
+	// outcome is recorded even if scheduler is shut down meanwhile
+	ctx := context.Background()
+
+	watch, err := s.watchlist.GetWatch(ctx, result.Chain, result.Address)
+	if errors.Is(err, walletscreener.ErrWatchNotFound) {
+		return
+	}
+	if err != nil {
+		s.logger.WithError(err).Errorf("unable to fetch watch of wallet %s on chain %s", result.Address, result.Chain)
+		return
+	}
+
+	now := s.now()
+
+	switch {
+	case result.Err != nil:
+		s.logger.WithError(result.Err).Errorf("unable to re-screen wallet %s on chain %s", result.Address, result.Chain)
+
+		delay := s.cfg.RetryDelay
+		if delay > watch.Interval {
+			delay = watch.Interval
+		}
+
+		watch.LastError = result.Err.Error()
+		watch.NextAt = now.Add(delay)
+	default:
+		watch.LastError = ""
+		watch.LastScreenedAt = now
+		watch.NextAt = now.Add(watch.Interval + s.Jitter(watch.Interval))
+	}
+
+	if err := s.watchlist.PutWatch(ctx, watch); err != nil {
+		s.logger.WithError(err).Errorf("unable to schedule wallet %s on chain %s", result.Address, result.Chain)
+	}
+}
@@ -0,0 +1,202 @@
+package watchlist
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/memory"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	"github.com/deividaspetraitis/wallet-screener/log"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+	created := now.Add(-48 * time.Hour)
+
+	store := memory.NewStore()
+	for _, v := range []*walletscreener.Watch{
+		{Chain: walletscreener.ChainEthereum, Address: "due", Interval: 24 * time.Hour, CreatedAt: created, NextAt: now},
+		{Chain: walletscreener.ChainEthereum, Address: "failing", Interval: 2 * time.Hour, CreatedAt: created, NextAt: now.Add(-time.Hour)},
+		{Chain: walletscreener.ChainEthereum, Address: "scheduled", Interval: 24 * time.Hour, CreatedAt: created, NextAt: now.Add(time.Second)},
+	} {
+		if err := store.PutWatch(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		screened []string
+	)
+	screen := func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !walletscreener.IsCacheBypassed(ctx) {
+			t.Errorf("got cached screening of %s, want cache bypassed", address)
+		}
+
+		screened = append(screened, address)
+
+		if address == "failing" {
+			return nil, errors.New("failed to store screening")
+		}
+		return &walletscreener.Screening{ID: address}, nil
+	}
+
+	scheduler := NewScheduler(store, screen, &Config{Concurrency: 1, Jitter: 0.1, RateLimit: 60000}, log.Default())
+	scheduler.now = func() time.Time { return now }
+	scheduler.rand = func() float64 { return 0.5 }
+
+	scheduler.run()
+
+	// most overdue wallet is re-screened first
+	if diff := cmp.Diff([]string{"failing", "due"}, screened); diff != "" {
+		t.Errorf("screened mismatch (-want +got):\n%s", diff)
+	}
+
+	var testcases = []struct {
+		address string
+		watch   *walletscreener.Watch
+	}{
+		// next re-screening is delayed by half of jitter
+		{
+			address: "due",
+			watch: &walletscreener.Watch{
+				Chain:          walletscreener.ChainEthereum,
+				Address:        "due",
+				Interval:       24 * time.Hour,
+				CreatedAt:      created,
+				NextAt:         now.Add(24*time.Hour + 72*time.Minute),
+				LastScreenedAt: now,
+			},
+		},
+		// failed re-screening is retried
+		{
+			address: "failing",
+			watch: &walletscreener.Watch{
+				Chain:     walletscreener.ChainEthereum,
+				Address:   "failing",
+				Interval:  2 * time.Hour,
+				CreatedAt: created,
+				NextAt:    now.Add(DefaultRetryDelay),
+				LastError: "failed to store screening",
+			},
+		},
+		// wallet not due is left as it is
+		{
+			address: "scheduled",
+			watch: &walletscreener.Watch{
+				Chain:     walletscreener.ChainEthereum,
+				Address:   "scheduled",
+				Interval:  24 * time.Hour,
+				CreatedAt: created,
+				NextAt:    now.Add(time.Second),
+			},
+		},
+	}
+
+	for i, tt := range testcases {
+		watch, err := store.GetWatch(ctx, walletscreener.ChainEthereum, tt.address)
+		if err != nil {
+			t.Fatalf("#%d got %v, want %v", i, err, nil)
+		}
+
+		if diff := cmp.Diff(tt.watch, watch); diff != "" {
+			t.Errorf("#%d watch mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	var testcases = []struct {
+		cfg      *Config
+		expected time.Duration
+	}{
+		{cfg: nil, expected: 30 * time.Minute},
+		{cfg: &Config{}, expected: 0},
+		{cfg: &Config{Jitter: -1}, expected: 30 * time.Minute},
+		{cfg: &Config{Jitter: 0.2}, expected: time.Hour},
+	}
+
+	for i, tt := range testcases {
+		scheduler := NewScheduler(memory.NewStore(), nil, tt.cfg, log.Default())
+		scheduler.rand = func() float64 { return 0.5 }
+
+		if jitter := scheduler.Jitter(10 * time.Hour); jitter != tt.expected {
+			t.Errorf("#%d got %v, want %v", i, jitter, tt.expected)
+		}
+	}
+}
+
+func TestSchedulerUnwatched(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+
+	store := memory.NewStore()
+	if err := store.PutWatch(ctx, &walletscreener.Watch{Chain: walletscreener.ChainEthereum, Address: "a", Interval: time.Hour, NextAt: now}); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// wallet is unwatched while being re-screened
+	screen := func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		if err := store.DeleteWatch(ctx, chain, address); err != nil {
+			t.Errorf("got %v, want %v", err, nil)
+		}
+		return &walletscreener.Screening{ID: address}, nil
+	}
+
+	scheduler := NewScheduler(store, screen, &Config{RateLimit: 60000}, log.Default())
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.run()
+
+	if _, err := store.GetWatch(ctx, walletscreener.ChainEthereum, "a"); !errors.Is(err, walletscreener.ErrWatchNotFound) {
+		t.Errorf("got %v, want %v", err, walletscreener.ErrWatchNotFound)
+	}
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	store := memory.NewStore()
+	if err := store.PutWatch(ctx, &walletscreener.Watch{Chain: walletscreener.ChainEthereum, Address: "a", Interval: time.Hour, NextAt: now}); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	started := make(chan struct{})
+	screen := func(ctx context.Context, chain walletscreener.Chain, address string) (*walletscreener.Screening, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	scheduler := NewScheduler(store, screen, nil, log.Default())
+	scheduler.Start()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := scheduler.Shutdown(ctx); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	// interrupted wallet is left due
+	watch, err := store.GetWatch(ctx, walletscreener.ChainEthereum, "a")
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	if !watch.NextAt.Equal(now) || watch.LastError != "" {
+		t.Errorf("got %+v, want watch left due", watch)
+	}
+}
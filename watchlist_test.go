@@ -0,0 +1,77 @@
+package walletscreener
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+func TestWatchWallet(t *testing.T) {
+	now := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+	screened := now.Add(-time.Hour)
+
+	var testcases = []struct {
+		watch    *Watch // already stored watch
+		interval time.Duration
+
+		nextAt    time.Time
+		createdAt time.Time
+		err       error
+	}{
+		// new watch is due in interval from now delayed by jitter
+		{
+			interval:  24 * time.Hour,
+			nextAt:    now.Add(24*time.Hour + 144*time.Minute),
+			createdAt: now,
+		},
+		// watched wallet is rescheduled from its last re-screening
+		{
+			watch:     &Watch{Chain: ChainEthereum, Address: "a", Interval: 24 * time.Hour, CreatedAt: screened.Add(-time.Hour), LastScreenedAt: screened},
+			interval:  2 * time.Hour,
+			nextAt:    screened.Add(2*time.Hour + 12*time.Minute),
+			createdAt: screened.Add(-time.Hour),
+		},
+		// interval shorter than allowed
+		{
+			interval: time.Minute,
+			err:      ErrWatchIntervalInvalid,
+		},
+	}
+
+	for i, tt := range testcases {
+		getWatch := func(ctx context.Context, chain Chain, address string) (*Watch, error) {
+			if tt.watch == nil {
+				return nil, ErrWatchNotFound
+			}
+			return tt.watch, nil
+		}
+
+		var stored *Watch
+		putWatch := func(ctx context.Context, watch *Watch) error {
+			stored = watch
+			return nil
+		}
+
+		jitter := func(interval time.Duration) time.Duration {
+			return interval / 10
+		}
+
+		watch, err := WatchWallet(context.Background(), getWatch, putWatch, jitter, ChainEthereum, "a", tt.interval, now)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("#%d got %v, want %v", i, err, tt.err)
+		}
+
+		if tt.err != nil {
+			if stored != nil {
+				t.Errorf("#%d got stored %+v, want %v", i, stored, nil)
+			}
+			continue
+		}
+
+		if watch != stored || watch.Interval != tt.interval || !watch.NextAt.Equal(tt.nextAt) || !watch.CreatedAt.Equal(tt.createdAt) {
+			t.Errorf("#%d got %+v, want interval %s due at %s created at %s", i, watch, tt.interval, tt.nextAt, tt.createdAt)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package walletscreener
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/uuid"
+)
+
+// Webhook errors.
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")                 // store does not hold such subscription
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")                     // subscription has no such delivery
+	ErrWebhookURLInvalid    = errors.New("webhook url must be absolute http or https url") // subscription cannot be delivered to
+	ErrWebhookEventInvalid  = errors.New("webhook event is not supported")                 // subscription filters unknown event
+)
+
+// WebhookEvent represents an event of a screening subscriptions are notified about.
+type WebhookEvent string
+
+// Webhook events.
+const (
+	WebhookEventBlocked           WebhookEvent = "screening.blocked"            // wallet got block verdict it did not have before
+	WebhookEventCategoriesChanged WebhookEvent = "screening.categories_changed" // wallet gained or lost risk categories
+)
+
+// webhookEvents contains supported webhook events.
+var webhookEvents = map[WebhookEvent]bool{
+	WebhookEventBlocked:           true,
+	WebhookEventCategoriesChanged: true,
+}
+
+// ScreeningEvents returns events stored screening is notified as.
+// Block verdict is an event only when wallet gets it, re-screenings keeping the wallet blocked are not notified again.
+func ScreeningEvents(screening *Screening) []WebhookEvent {
+	var events []WebhookEvent
+
+	if screening.Verdict == VerdictBlock && (screening.Change == nil || screening.Change.PreviousVerdict != VerdictBlock) {
+		events = append(events, WebhookEventBlocked)
+	}
+
+	if change := screening.Change; change != nil && (len(change.AddedCategories) > 0 || len(change.RemovedCategories) > 0) {
+		events = append(events, WebhookEventCategoriesChanged)
+	}
+
+	return events
+}
+
+// Subscription represents an endpoint notified about screening events.
+type Subscription struct {
+	ID        string
+	URL       string         // Endpoint payloads are posted to
+	Secret    string         // Key payloads are signed with
+	Events    []WebhookEvent // Events endpoint is notified about, every event if empty
+	CreatedAt time.Time
+}
+
+// Subscribed returns whether subscription is notified about the event.
+func (s *Subscription) Subscribed(event WebhookEvent) bool {
+	if len(s.Events) < 1 {
+		return true
+	}
+
+	for _, v := range s.Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSubscription constructs a new Subscription of endpoint identified by a random ID.
+// Random secret is generated if secret is empty.
+func NewSubscription(endpoint, secret string, events []WebhookEvent) (*Subscription, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Wrapf(ErrWebhookURLInvalid, "got %q", endpoint)
+	}
+
+	for _, v := range events {
+		if !webhookEvents[v] {
+			return nil, errors.Wrapf(ErrWebhookEventInvalid, "got %q", v)
+		}
+	}
+
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, errors.Wrap(err, "failed to generate webhook secret")
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	return &Subscription{
+		ID:        uuid.NewString(),
+		URL:       endpoint,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// DeliveryStatus represents status of a webhook delivery.
+type DeliveryStatus string
+
+// Statuses of a delivery.
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"   // waiting for the next attempt
+	DeliveryStatusDelivered DeliveryStatus = "delivered" // endpoint accepted the payload
+	DeliveryStatusDead      DeliveryStatus = "dead"      // attempts were exhausted or subscription was deleted, kept until redelivered
+)
+
+// DeliveryAttempt represents a single attempt to deliver payload kept in delivery log.
+type DeliveryAttempt struct {
+	At         time.Time
+	StatusCode int           // HTTP response status code, zero if no response was received
+	Error      string        // Why the attempt failed, empty if it succeeded
+	Duration   time.Duration // How long the attempt took
+}
+
+// Delivery represents notification of a subscription about an event of a screening.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	Event          WebhookEvent
+	ScreeningID    string
+	Payload        []byte // Body posted to the endpoint, same for every attempt
+	Status         DeliveryStatus
+	Attempts       int               // Attempts made since delivery was created or redelivered
+	Log            []DeliveryAttempt // Every attempt made, oldest first
+	NextAttemptAt  time.Time         // When pending delivery is due to be attempted
+	CreatedAt      time.Time
+	DeliveredAt    time.Time // Zero until endpoint accepts the payload
+}
+
+// NewDelivery constructs a new pending Delivery due now identified by a random ID.
+func NewDelivery(subscription *Subscription, event WebhookEvent, screening *Screening) *Delivery {
+	now := time.Now().UTC()
+	return &Delivery{
+		ID:             uuid.NewString(),
+		SubscriptionID: subscription.ID,
+		Event:          event,
+		ScreeningID:    screening.ID,
+		Status:         DeliveryStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// DeliveriesQuery represents a query of webhook deliveries.
+type DeliveriesQuery struct {
+	SubscriptionID string         // Deliveries of the subscription, every subscription if empty
+	Status         DeliveryStatus // Deliveries of the status, every status if empty
+	Descending     bool           // Whether the most recently created deliveries come first
+	Limit          int            // Maximum number of deliveries, unbounded if zero
+}
+
+// SortDeliveries sorts deliveries by their creation, the most recent first if descending.
+// Deliveries created at the same time are sorted by their IDs.
+func SortDeliveries(deliveries []*Delivery, descending bool) {
+	sort.Slice(deliveries, func(i, j int) bool {
+		a, b := deliveries[i], deliveries[j]
+		if descending {
+			a, b = b, a
+		}
+
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// WebhookStore represents storage of webhook subscriptions and their deliveries.
+type WebhookStore interface {
+	// PutSubscription stores subscription replacing the previous one.
+	// PutSubscription implements PutSubscriptionFunc.
+	PutSubscription(ctx context.Context, subscription *Subscription) error
+
+	// GetSubscription returns subscription identified by id, ErrSubscriptionNotFound is returned if there is no such subscription.
+	// GetSubscription implements GetSubscriptionFunc.
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+
+	// DeleteSubscription deletes subscription identified by id, ErrSubscriptionNotFound is returned if there is no such subscription.
+	// Deliveries of the subscription are kept.
+	// DeleteSubscription implements DeleteSubscriptionFunc.
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// ListSubscriptions returns every subscription.
+	// ListSubscriptions implements ListSubscriptionsFunc.
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+
+	// PutDelivery stores delivery replacing the previous one.
+	// PutDelivery implements PutDeliveryFunc.
+	PutDelivery(ctx context.Context, delivery *Delivery) error
+
+	// GetDelivery returns delivery identified by id of the given subscription, ErrDeliveryNotFound is returned if there is no such delivery.
+	// GetDelivery implements GetDeliveryFunc.
+	GetDelivery(ctx context.Context, subscriptionID, id string) (*Delivery, error)
+
+	// ListDeliveries returns deliveries matching the query ordered by their creation.
+	// ListDeliveries implements ListDeliveriesFunc.
+	ListDeliveries(ctx context.Context, query *DeliveriesQuery) ([]*Delivery, error)
+
+	// ListDueDeliveries returns at most limit pending deliveries due by the given time, the most overdue first.
+	// Deliveries not pending are not read at all.
+	// ListDueDeliveries implements ListDueDeliveriesFunc.
+	ListDueDeliveries(ctx context.Context, dueBy time.Time, limit int) ([]*Delivery, error)
+}
+
+// PutSubscriptionFunc stores a webhook subscription.
+type PutSubscriptionFunc func(ctx context.Context, subscription *Subscription) error
+
+// GetSubscriptionFunc retrieves a webhook subscription.
+type GetSubscriptionFunc func(ctx context.Context, id string) (*Subscription, error)
+
+// DeleteSubscriptionFunc deletes a webhook subscription.
+type DeleteSubscriptionFunc func(ctx context.Context, id string) error
+
+// ListSubscriptionsFunc retrieves every webhook subscription.
+type ListSubscriptionsFunc func(ctx context.Context) ([]*Subscription, error)
+
+// PutDeliveryFunc stores a webhook delivery.
+type PutDeliveryFunc func(ctx context.Context, delivery *Delivery) error
+
+// GetDeliveryFunc retrieves a webhook delivery.
+type GetDeliveryFunc func(ctx context.Context, subscriptionID, id string) (*Delivery, error)
+
+// ListDeliveriesFunc retrieves webhook deliveries matching the query.
+type ListDeliveriesFunc func(ctx context.Context, query *DeliveriesQuery) ([]*Delivery, error)
+
+// ListDueDeliveriesFunc retrieves pending webhook deliveries due by the given time.
+type ListDueDeliveriesFunc func(ctx context.Context, dueBy time.Time, limit int) ([]*Delivery, error)
+
+// NotifyScreeningFunc notifies subscriptions about stored screening.
+type NotifyScreeningFunc func(ctx context.Context, screening *Screening)
+
+// WithNotify returns StoreScreeningFunc storing screening by storeScreening and notifying about it by notify once it is stored.
+func WithNotify(storeScreening StoreScreeningFunc, notify NotifyScreeningFunc) StoreScreeningFunc {
+	return func(ctx context.Context, screening *Screening) error {
+		if err := storeScreening(ctx, screening); err != nil {
+			return err
+		}
+
+		notify(ctx, screening)
+
+		return nil
+	}
+}
+
+// CreateSubscription subscribes endpoint to be notified about events, every event if events are empty.
+// Random secret is generated if secret is empty.
+func CreateSubscription(ctx context.Context, putSubscription PutSubscriptionFunc, endpoint, secret string, events []WebhookEvent) (*Subscription, error) {
+	subscription, err := NewSubscription(endpoint, secret, events)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := putSubscription(ctx, subscription); err != nil {
+		return nil, errors.New("failed to store webhook subscription")
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription retrieves subscription identified by id.
+func GetSubscription(ctx context.Context, getSubscription GetSubscriptionFunc, id string) (*Subscription, error) {
+	subscription, err := getSubscription(ctx, id)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch webhook subscription")
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription stops notifying subscription identified by id, its pending deliveries become dead once attempted.
+func DeleteSubscription(ctx context.Context, deleteSubscription DeleteSubscriptionFunc, id string) error {
+	err := deleteSubscription(ctx, id)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return err
+	}
+	if err != nil {
+		return errors.New("failed to delete webhook subscription")
+	}
+
+	return nil
+}
+
+// ListSubscriptions retrieves every subscription.
+func ListSubscriptions(ctx context.Context, listSubscriptions ListSubscriptionsFunc) ([]*Subscription, error) {
+	subscriptions, err := listSubscriptions(ctx)
+	if err != nil {
+		return nil, errors.New("failed to list webhook subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+// Limits of deliveries returned by ListDeliveries.
+const (
+	DefaultDeliveriesLimit = 100  // used when query has no limit
+	MaxDeliveriesLimit     = 1000 // greater limits are capped
+)
+
+// ListDeliveries retrieves deliveries of the subscription matching the query, the most recent first.
+// Query limit defaults to DefaultDeliveriesLimit and is capped at MaxDeliveriesLimit.
+func ListDeliveries(ctx context.Context, getSubscription GetSubscriptionFunc, listDeliveries ListDeliveriesFunc, query DeliveriesQuery) ([]*Delivery, error) {
+	if _, err := GetSubscription(ctx, getSubscription, query.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	if query.Limit < 1 {
+		query.Limit = DefaultDeliveriesLimit
+	}
+
+	if query.Limit > MaxDeliveriesLimit {
+		query.Limit = MaxDeliveriesLimit
+	}
+
+	query.Descending = true
+
+	deliveries, err := listDeliveries(ctx, &query)
+	if err != nil {
+		return nil, errors.New("failed to list webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverDelivery schedules delivery of the subscription to be attempted again now with a fresh number of attempts.
+// Delivery log is kept.
+func RedeliverDelivery(ctx context.Context, getDelivery GetDeliveryFunc, putDelivery PutDeliveryFunc, subscriptionID, id string, now time.Time) (*Delivery, error) {
+	delivery, err := getDelivery(ctx, subscriptionID, id)
+	if errors.Is(err, ErrDeliveryNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch webhook delivery")
+	}
+
+	delivery.Status = DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.DeliveredAt = time.Time{}
+
+	if err := putDelivery(ctx, delivery); err != nil {
+		return nil, errors.New("failed to store webhook delivery")
+	}
+
+	return delivery, nil
+}
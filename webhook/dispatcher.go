@@ -0,0 +1,346 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	ihttp "github.com/deividaspetraitis/wallet-screener/http"
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+// Defaults of webhooks configuration.
+const (
+	DefaultPoll             = 5 * time.Second
+	DefaultConcurrency      = 4
+	DefaultBatch            = 100
+	DefaultTimeout          = 10 * time.Second
+	DefaultRetryMaxAttempts = 8
+	DefaultRetryBaseDelay   = 30 * time.Second
+	DefaultRetryMaxDelay    = time.Hour
+)
+
+// Config represents webhooks configuration, defaults are used for values not set.
+type Config struct {
+	Poll        time.Duration     `mapstructure:"poll"`        // How often pending deliveries are checked for ones due
+	Concurrency int               `mapstructure:"concurrency"` // Deliveries attempted at once
+	Batch       int               `mapstructure:"batch"`       // Deliveries due read per poll, the rest are attempted once these are done
+	Timeout     time.Duration     `mapstructure:"timeout"`     // How long endpoint is given to respond
+	Retry       ihttp.RetryPolicy `mapstructure:"retry"`       // Backoff of failed deliveries, delivery is dead once its attempts are exhausted
+}
+
+// withDefaults returns copy of the configuration with defaults set for values not set, cfg may be nil.
+func (cfg *Config) withDefaults() Config {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Poll <= 0 {
+		c.Poll = DefaultPoll
+	}
+	if c.Concurrency < 1 {
+		c.Concurrency = DefaultConcurrency
+	}
+	if c.Batch < 1 {
+		c.Batch = DefaultBatch
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Retry.MaxAttempts < 1 {
+		c.Retry.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if c.Retry.BaseDelay <= 0 {
+		c.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+	if c.Retry.MaxDelay <= 0 {
+		c.Retry.MaxDelay = DefaultRetryMaxDelay
+	}
+	return c
+}
+
+// Dispatcher notifies webhook subscriptions about screening events.
+// Deliveries are persisted before they are attempted so that pending ones survive restarts,
+// failed deliveries are retried with backoff until their attempts are exhausted and they become dead.
+type Dispatcher struct {
+	webhooks walletscreener.WebhookStore
+	cfg      Config
+	logger   log.Logger
+	client   *http.Client // sends deliveries to endpoints of every subscription
+
+	now func() time.Time
+
+	wake   chan struct{} // signals deliveries were created
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDispatcher constructs and returns new Dispatcher delivering payloads of deliveries stored in webhooks, cfg may be nil.
+func NewDispatcher(webhooks walletscreener.WebhookStore, cfg *Config, logger log.Logger) *Dispatcher {
+	c := cfg.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		webhooks: webhooks,
+		cfg:      c,
+		logger:   logger,
+		client:   newClient(c.Timeout),
+		now:      func() time.Time { return time.Now().UTC() },
+		wake:     make(chan struct{}, 1),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Notify creates deliveries of screening events for subscriptions filtering them.
+// Notify implements walletscreener.NotifyScreeningFunc, failures are logged since screening is stored already.
+func (d *Dispatcher) Notify(ctx context.Context, screening *walletscreener.Screening) {
+	events := walletscreener.ScreeningEvents(screening)
+	if len(events) < 1 {
+		return
+	}
+
+	// deliveries are created even if caller gives up on the screening meanwhile
+	ctx = context.Background()
+
+	subscriptions, err := d.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		d.logger.WithError(err).Errorf("unable to list webhook subscriptions notified about screening %s", screening.ID)
+		return
+	}
+
+	var created bool
+	for _, subscription := range subscriptions {
+		for _, event := range events {
+			if !subscription.Subscribed(event) {
+				continue
+			}
+
+			delivery := walletscreener.NewDelivery(subscription, event, screening)
+
+			delivery.Payload, err = api.NewWebhookPayload(delivery, screening)
+			if err != nil {
+				d.logger.WithError(err).Errorf("unable to encode %s payload of screening %s", event, screening.ID)
+				continue
+			}
+
+			if err := d.webhooks.PutDelivery(ctx, delivery); err != nil {
+				d.logger.WithError(err).Errorf("unable to store %s delivery of screening %s to subscription %s", event, screening.ID, subscription.ID)
+				continue
+			}
+
+			created = true
+		}
+	}
+
+	if created {
+		select {
+		case d.wake <- struct{}{}:
+		default: // dispatcher is woken already
+		}
+	}
+}
+
+// Start starts delivering pending deliveries until Shutdown.
+func (d *Dispatcher) Start() {
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.cfg.Poll)
+		defer ticker.Stop()
+
+		for {
+			d.run()
+
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Shutdown stops dispatcher and waits until attempts in progress are interrupted.
+// Interrupted deliveries are left pending and attempted once dispatcher is started again.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.cancel()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "webhook deliveries were not interrupted in time")
+	}
+}
+
+// run attempts batches of deliveries due by now, most overdue first, until none are due.
+func (d *Dispatcher) run() {
+	for d.ctx.Err() == nil {
+		due, err := d.webhooks.ListDueDeliveries(d.ctx, d.now(), d.cfg.Batch)
+		if err != nil {
+			if d.ctx.Err() == nil {
+				d.logger.WithError(err).Error("unable to list due webhook deliveries")
+			}
+			return
+		}
+
+		// deliveries left due are read by the next batch, unless some were not recorded and would be read again
+		if recorded := d.attempt(due); len(due) < d.cfg.Batch || recorded < len(due) {
+			return
+		}
+	}
+}
+
+// attempt attempts deliveries, waits for them to finish and returns how many of them were recorded.
+func (d *Dispatcher) attempt(due []*walletscreener.Delivery) int {
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, d.cfg.Concurrency)
+		recorded int32
+	)
+	for _, v := range due {
+		// deliveries not attempted before shutdown are left pending
+		if d.ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery *walletscreener.Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if d.deliver(delivery) {
+				atomic.AddInt32(&recorded, 1)
+			}
+		}(v)
+	}
+	wg.Wait()
+
+	return int(atomic.LoadInt32(&recorded))
+}
+
+// deliver makes an attempt of the delivery and records its outcome.
+// Deliveries of deleted subscriptions become dead without an attempt, false is returned if outcome was not recorded.
+func (d *Dispatcher) deliver(delivery *walletscreener.Delivery) bool {
+	// store is accessed regardless of shutdown, only attempts interrupted by it are not recorded
+	ctx := context.Background()
+
+	subscription, err := d.webhooks.GetSubscription(ctx, delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, walletscreener.ErrSubscriptionNotFound):
+		delivery.Status = walletscreener.DeliveryStatusDead
+		delivery.Log = append(delivery.Log, walletscreener.DeliveryAttempt{At: d.now(), Error: "subscription was deleted"})
+	case err != nil:
+		d.logger.WithError(err).Errorf("unable to fetch subscription %s of delivery %s", delivery.SubscriptionID, delivery.ID)
+		return false
+	default:
+		attempt, err := d.send(subscription, delivery)
+
+		// deliveries interrupted by shutdown are left pending
+		if d.ctx.Err() != nil {
+			return false
+		}
+
+		delivery.Attempts++
+		delivery.Log = append(delivery.Log, attempt)
+
+		switch {
+		case err == nil:
+			delivery.Status = walletscreener.DeliveryStatusDelivered
+			delivery.DeliveredAt = attempt.At
+		case delivery.Attempts >= d.cfg.Retry.MaxAttempts:
+			d.logger.WithError(err).Warnf("webhook delivery %s to subscription %s is dead after %d attempts", delivery.ID, subscription.ID, delivery.Attempts)
+			delivery.Status = walletscreener.DeliveryStatusDead
+		default:
+			delivery.NextAttemptAt = attempt.At.Add(d.retryDelay(delivery.Attempts, err))
+		}
+	}
+
+	if err := d.webhooks.PutDelivery(ctx, delivery); err != nil {
+		d.logger.WithError(err).Errorf("unable to record webhook delivery %s", delivery.ID)
+		return false
+	}
+
+	return true
+}
+
+// newClient returns HTTP client sending deliveries, endpoints are given timeout to respond.
+// Redirects are not followed so that signed payloads are posted only to URLs of subscriptions, redirect responses fail the attempt.
+func newClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// send posts payload of the delivery to endpoint of the subscription signed by its secret and returns the attempt made.
+// Any 2xx response acknowledges the delivery, other responses result in *ihttp.ResponseError.
+func (d *Dispatcher) send(subscription *walletscreener.Subscription, delivery *walletscreener.Delivery) (walletscreener.DeliveryAttempt, error) {
+	attempt := walletscreener.DeliveryAttempt{At: d.now()}
+
+	// endpoint is requested by the URL of the subscription as it is
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, attempt.At, delivery.Payload))
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, delivery.ID)
+
+	started := time.Now()
+	res, err := d.client.Do(req)
+	attempt.Duration = time.Since(started)
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	attempt.StatusCode = res.StatusCode
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		err := ihttp.NewResponseError(res)
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+	defer res.Body.Close()
+
+	// drain the body allowing connection to be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+
+	return attempt, nil
+}
+
+// retryDelay returns delay before the next attempt of delivery which failed with err after given number of attempts.
+// Endpoints asking to retry later are respected up to maximum delay of the retry policy.
+func (d *Dispatcher) retryDelay(attempts int, err error) time.Duration {
+	var responseErr *ihttp.ResponseError
+	if errors.As(err, &responseErr) {
+		if delay, ok := responseErr.RetryAfter(); ok {
+			if delay > d.cfg.Retry.MaxDelay {
+				delay = d.cfg.Retry.MaxDelay
+			}
+			return delay
+		}
+	}
+
+	return d.cfg.Retry.Backoff(attempts)
+}
@@ -0,0 +1,363 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener"
+	"github.com/deividaspetraitis/wallet-screener/database/memory"
+	"github.com/deividaspetraitis/wallet-screener/errors"
+	ihttp "github.com/deividaspetraitis/wallet-screener/http"
+	"github.com/deividaspetraitis/wallet-screener/log"
+	"github.com/deividaspetraitis/wallet-screener/pkg/api/v1"
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Date(2023, 10, 4, 15, 18, 23, 0, time.UTC)
+	payload := []byte(`{"event":"screening.blocked"}`)
+	signature := Sign("secret", now, payload)
+
+	var testcases = []struct {
+		secret    string
+		signature string
+		payload   []byte
+		now       time.Time
+		err       error
+	}{
+		{"secret", signature, payload, now.Add(time.Minute), nil},
+		{"secret", signature, payload, now.Add(time.Hour), ErrSignatureInvalid},                        // replayed
+		{"another", signature, payload, now, ErrSignatureInvalid},                                      // signed by another secret
+		{"secret", signature, []byte(`{"event":"tampered"}`), now, ErrSignatureInvalid},                // tampered with
+		{"secret", "v1=" + strings.SplitN(signature, ",v1=", 2)[1], payload, now, ErrSignatureInvalid}, // timestamp is missing
+		{"secret", strings.SplitN(signature, ",", 2)[0] + ",v1=zz", payload, now, ErrSignatureInvalid}, // signature is not hex
+	}
+
+	for _, tc := range testcases {
+		if err := VerifySignature(tc.secret, tc.signature, tc.payload, tc.now, 5*time.Minute); !errors.Is(err, tc.err) {
+			t.Errorf("got %v, want %v", err, tc.err)
+		}
+	}
+}
+
+// request represents a request received by the endpoint.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// newEndpoint returns a webhook endpoint responding with given status code and header along requests it received.
+func newEndpoint(t *testing.T, statusCode int, header http.Header) (*httptest.Server, func() []request) {
+	var (
+		mu       sync.Mutex
+		requests []request
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("got %v, want %v", err, nil)
+		}
+
+		mu.Lock()
+		requests = append(requests, request{header: r.Header, body: body})
+		mu.Unlock()
+
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Minute)
+
+	endpoint, received := newEndpoint(t, http.StatusNoContent, nil)
+	failing, _ := newEndpoint(t, http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"120"}})
+
+	store := memory.NewStore()
+	for _, v := range []*walletscreener.Subscription{
+		{ID: "all", URL: endpoint.URL, Secret: "secret"},
+		{ID: "changes", URL: endpoint.URL, Secret: "secret", Events: []walletscreener.WebhookEvent{walletscreener.WebhookEventCategoriesChanged}},
+		{ID: "failing", URL: failing.URL, Secret: "secret", Events: []walletscreener.WebhookEvent{walletscreener.WebhookEventBlocked}},
+	} {
+		if err := store.PutSubscription(ctx, v); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	// delivery of a subscription deleted meanwhile
+	if err := store.PutDelivery(ctx, &walletscreener.Delivery{
+		ID:             "deleted",
+		SubscriptionID: "deleted",
+		Event:          walletscreener.WebhookEventBlocked,
+		Payload:        []byte(`{}`),
+		Status:         walletscreener.DeliveryStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	dispatcher := NewDispatcher(store, nil, log.Default())
+	dispatcher.now = func() time.Time { return now }
+
+	// the first screening of the wallet is blocked
+	screening := &walletscreener.Screening{
+		ID: "screening",
+		ScreeningResult: walletscreener.ScreeningResult{
+			Chain:   walletscreener.ChainEthereum,
+			Address: "0x71C7656EC7ab88b098defB751B7401B5f6d8976F",
+			Verdict: walletscreener.VerdictBlock,
+		},
+	}
+	dispatcher.Notify(ctx, screening)
+
+	dispatcher.run()
+
+	requests := received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want %d", len(requests), 1)
+	}
+
+	var payload api.WebhookPayload
+	if err := json.Unmarshal(requests[0].body, &payload); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if payload.Event != walletscreener.WebhookEventBlocked || payload.Screening.ID != screening.ID {
+		t.Errorf("got %s of screening %s, want %s of screening %s", payload.Event, payload.Screening.ID, walletscreener.WebhookEventBlocked, screening.ID)
+	}
+
+	header := requests[0].header
+	if got := header.Get(EventHeader); got != string(walletscreener.WebhookEventBlocked) {
+		t.Errorf("got %v, want %v", got, walletscreener.WebhookEventBlocked)
+	}
+	if got := header.Get(DeliveryHeader); got != payload.ID {
+		t.Errorf("got %v, want %v", got, payload.ID)
+	}
+	if err := VerifySignature("secret", header.Get(SignatureHeader), requests[0].body, now, time.Minute); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+
+	var testcases = []struct {
+		subscription  string
+		status        walletscreener.DeliveryStatus
+		attempts      int
+		statusCode    int
+		nextAttemptAt time.Time
+	}{
+		{"all", walletscreener.DeliveryStatusDelivered, 1, http.StatusNoContent, now},
+		{"failing", walletscreener.DeliveryStatusPending, 1, http.StatusServiceUnavailable, now.Add(120 * time.Second)}, // endpoint asked to retry later
+		{"deleted", walletscreener.DeliveryStatusDead, 0, 0, now},
+	}
+
+	for _, tc := range testcases {
+		deliveries, err := store.ListDeliveries(ctx, &walletscreener.DeliveriesQuery{SubscriptionID: tc.subscription})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		if len(deliveries) != 1 {
+			t.Fatalf("got %d deliveries of %s, want %d", len(deliveries), tc.subscription, 1)
+		}
+
+		delivery := deliveries[0]
+		if delivery.Status != tc.status {
+			t.Errorf("got %v, want %v", delivery.Status, tc.status)
+		}
+		if delivery.Attempts != tc.attempts {
+			t.Errorf("got %v, want %v", delivery.Attempts, tc.attempts)
+		}
+		if len(delivery.Log) != 1 || delivery.Log[0].StatusCode != tc.statusCode {
+			t.Errorf("got %+v, want single attempt with %d status code", delivery.Log, tc.statusCode)
+		}
+		if !delivery.NextAttemptAt.Equal(tc.nextAttemptAt) && tc.status == walletscreener.DeliveryStatusPending {
+			t.Errorf("got %v, want %v", delivery.NextAttemptAt, tc.nextAttemptAt)
+		}
+	}
+
+	// subscription filtering other events is not notified
+	deliveries, err := store.ListDeliveries(ctx, &walletscreener.DeliveriesQuery{SubscriptionID: "changes"})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if len(deliveries) != 0 {
+		t.Errorf("got %d deliveries, want %d", len(deliveries), 0)
+	}
+}
+
+func TestDispatcherDead(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Minute)
+
+	endpoint, received := newEndpoint(t, http.StatusInternalServerError, nil)
+
+	store := memory.NewStore()
+	if err := store.PutSubscription(ctx, &walletscreener.Subscription{ID: "failing", URL: endpoint.URL, Secret: "secret"}); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	dispatcher := NewDispatcher(store, &Config{Retry: ihttp.RetryPolicy{MaxAttempts: 2}}, log.Default())
+	dispatcher.now = func() time.Time { return now }
+
+	dispatcher.Notify(ctx, &walletscreener.Screening{
+		ID:              "screening",
+		ScreeningResult: walletscreener.ScreeningResult{Verdict: walletscreener.VerdictBlock},
+	})
+
+	var testcases = []struct {
+		status   walletscreener.DeliveryStatus
+		attempts int
+	}{
+		{walletscreener.DeliveryStatusPending, 1},
+		{walletscreener.DeliveryStatusDead, 2}, // attempts are exhausted
+		{walletscreener.DeliveryStatusDead, 2}, // dead delivery is not attempted again
+	}
+
+	for _, tc := range testcases {
+		dispatcher.run()
+
+		deliveries, err := store.ListDeliveries(ctx, &walletscreener.DeliveriesQuery{})
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+		if len(deliveries) != 1 {
+			t.Fatalf("got %d deliveries, want %d", len(deliveries), 1)
+		}
+
+		if got := deliveries[0]; got.Status != tc.status || got.Attempts != tc.attempts || len(got.Log) != tc.attempts {
+			t.Errorf("got %s after %d attempts, want %s after %d attempts", got.Status, got.Attempts, tc.status, tc.attempts)
+		}
+
+		// retry is due by the next run
+		now = now.Add(DefaultRetryMaxDelay)
+	}
+
+	if got := len(received()); got != 2 {
+		t.Errorf("got %d requests, want %d", got, 2)
+	}
+}
+
+func TestDispatcherBatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Minute)
+
+	endpoint, received := newEndpoint(t, http.StatusInternalServerError, nil)
+
+	store := memory.NewStore()
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		if err := store.PutSubscription(ctx, &walletscreener.Subscription{ID: id, URL: endpoint.URL, Secret: "secret"}); err != nil {
+			t.Fatalf("got %v, want %v", err, nil)
+		}
+	}
+
+	dispatcher := NewDispatcher(store, &Config{Batch: 2}, log.Default())
+	dispatcher.now = func() time.Time { return now }
+
+	dispatcher.Notify(ctx, &walletscreener.Screening{
+		ID:              "screening",
+		ScreeningResult: walletscreener.ScreeningResult{Verdict: walletscreener.VerdictBlock},
+	})
+
+	dispatcher.run()
+
+	// every delivery due is attempted once batch by batch, failed ones are not due again until retried
+	if got := len(received()); got != 5 {
+		t.Errorf("got %d requests, want %d", got, 5)
+	}
+
+	due, err := store.ListDueDeliveries(ctx, now, 0)
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if len(due) != 0 {
+		t.Errorf("got %d deliveries due, want %d", len(due), 0)
+	}
+}
+
+func TestDispatcherRedirect(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Add(time.Minute)
+
+	target, received := newEndpoint(t, http.StatusOK, nil)
+	redirecting, _ := newEndpoint(t, http.StatusTemporaryRedirect, http.Header{"Location": []string{target.URL}})
+
+	store := memory.NewStore()
+	if err := store.PutSubscription(ctx, &walletscreener.Subscription{ID: "redirecting", URL: redirecting.URL, Secret: "secret"}); err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+
+	dispatcher := NewDispatcher(store, nil, log.Default())
+	dispatcher.now = func() time.Time { return now }
+
+	dispatcher.Notify(ctx, &walletscreener.Screening{
+		ID:              "screening",
+		ScreeningResult: walletscreener.ScreeningResult{Verdict: walletscreener.VerdictBlock},
+	})
+
+	dispatcher.run()
+
+	// signed payload is not posted anywhere but to the URL of the subscription
+	if got := len(received()); got != 0 {
+		t.Errorf("got %d requests, want %d", got, 0)
+	}
+
+	deliveries, err := store.ListDeliveries(ctx, &walletscreener.DeliveriesQuery{})
+	if err != nil {
+		t.Fatalf("got %v, want %v", err, nil)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want %d", len(deliveries), 1)
+	}
+
+	if got := deliveries[0]; got.Status != walletscreener.DeliveryStatusPending || len(got.Log) != 1 || got.Log[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("got %s with log %+v, want %s after single attempt with %d status code", got.Status, got.Log, walletscreener.DeliveryStatusPending, http.StatusTemporaryRedirect)
+	}
+}
+
+func TestDispatcherRetryDelay(t *testing.T) {
+	dispatcher := NewDispatcher(memory.NewStore(), &Config{Retry: ihttp.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Hour}}, log.Default())
+
+	var testcases = []struct {
+		retryAfter string
+		expected   time.Duration
+	}{
+		{"120", 2 * time.Minute},
+		{"3600", time.Hour},
+		{"86400", time.Hour}, // endpoint asking for longer delay is retried by maximum delay
+	}
+
+	for i, tt := range testcases {
+		err := &ihttp.ResponseError{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{tt.retryAfter}}}
+
+		if delay := dispatcher.retryDelay(1, err); delay != tt.expected {
+			t.Errorf("#%d got %v, want %v", i, delay, tt.expected)
+		}
+	}
+}
+
+func TestDispatcherShutdown(t *testing.T) {
+	dispatcher := NewDispatcher(memory.NewStore(), &Config{Poll: time.Millisecond}, log.Default())
+	dispatcher.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		t.Errorf("got %v, want %v", err, nil)
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+)
+
+// Headers of requests delivering webhook payloads.
+const (
+	SignatureHeader = "X-Webhook-Signature" // signature of the payload, see Sign
+	EventHeader     = "X-Webhook-Event"     // event payload notifies about
+	DeliveryHeader  = "X-Webhook-Delivery"  // ID of the delivery, same for every attempt
+)
+
+// ErrSignatureInvalid is returned when signature does not prove payload was sent by the service.
+var ErrSignatureInvalid = errors.New("webhook signature is not valid")
+
+// Sign returns signature of payload sent at the given time laid out as t={unix timestamp},v1={hex HMAC-SHA256}.
+// HMAC is keyed by secret of the subscription and computed over {unix timestamp}.{payload},
+// signing the timestamp allows receivers to reject replayed payloads.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, payload))
+}
+
+// VerifySignature verifies signature of payload as produced by Sign.
+// Signatures made more than tolerance away from now are rejected, tolerance of zero accepts signatures made at any time.
+func VerifySignature(secret, signature string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var t, v1 string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return errors.Wrap(ErrSignatureInvalid, "timestamp is missing")
+	}
+
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return errors.Wrapf(ErrSignatureInvalid, "timestamp is %s away", age)
+		}
+	}
+
+	expected, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(expected, mac(secret, t, payload)) {
+		return errors.Wrap(ErrSignatureInvalid, "signature does not match payload")
+	}
+
+	return nil
+}
+
+// mac returns HMAC-SHA256 of payload sent at timestamp t keyed by secret.
+func mac(secret, t string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t + "."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
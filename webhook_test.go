@@ -0,0 +1,90 @@
+package walletscreener
+
+import (
+	"testing"
+
+	"github.com/deividaspetraitis/wallet-screener/errors"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScreeningEvents(t *testing.T) {
+	var testcases = []struct {
+		verdict Verdict
+		change  *RiskChange
+
+		events []WebhookEvent
+	}{
+		// the first screening of the wallet is blocked
+		{
+			verdict: VerdictBlock,
+			events:  []WebhookEvent{WebhookEventBlocked},
+		},
+		// wallet gets blocked by a new category
+		{
+			verdict: VerdictBlock,
+			change:  &RiskChange{PreviousVerdict: VerdictReview, AddedCategories: []string{"sanctions"}},
+			events:  []WebhookEvent{WebhookEventBlocked, WebhookEventCategoriesChanged},
+		},
+		// wallet stays blocked
+		{
+			verdict: VerdictBlock,
+			change:  &RiskChange{PreviousVerdict: VerdictBlock, RiskDelta: 5},
+		},
+		// wallet is no longer flagged
+		{
+			verdict: VerdictAllow,
+			change:  &RiskChange{PreviousVerdict: VerdictReview, RemovedCategories: []string{"gambling"}},
+			events:  []WebhookEvent{WebhookEventCategoriesChanged},
+		},
+		// the first screening of the wallet is allowed
+		{
+			verdict: VerdictAllow,
+		},
+	}
+
+	for i, tc := range testcases {
+		screening := &Screening{ScreeningResult: ScreeningResult{Verdict: tc.verdict}, Change: tc.change}
+		if diff := cmp.Diff(tc.events, ScreeningEvents(screening)); diff != "" {
+			t.Errorf("#%d ScreeningEvents() mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestNewSubscription(t *testing.T) {
+	var testcases = []struct {
+		url    string
+		secret string
+		events []WebhookEvent
+
+		err error
+	}{
+		{url: "https://example.com/hook", secret: "secret", events: []WebhookEvent{WebhookEventBlocked}},
+		{url: "http://localhost:8080"},
+		{url: "ftp://example.com/hook", err: ErrWebhookURLInvalid},
+		{url: "/hook", err: ErrWebhookURLInvalid},
+		{url: "https://example.com/hook", events: []WebhookEvent{"screening.allowed"}, err: ErrWebhookEventInvalid},
+	}
+
+	for i, tc := range testcases {
+		subscription, err := NewSubscription(tc.url, tc.secret, tc.events)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("#%d got %v, want %v", i, err, tc.err)
+		}
+		if err != nil {
+			continue
+		}
+
+		// secret is generated if not given
+		if tc.secret != "" && subscription.Secret != tc.secret || tc.secret == "" && len(subscription.Secret) != 64 {
+			t.Errorf("#%d got secret %q, want %q or generated one", i, subscription.Secret, tc.secret)
+		}
+
+		for _, event := range []WebhookEvent{WebhookEventBlocked, WebhookEventCategoriesChanged} {
+			want := len(tc.events) == 0 || event == WebhookEventBlocked
+			if got := subscription.Subscribed(event); got != want {
+				t.Errorf("#%d Subscribed(%s) got %v, want %v", i, event, got, want)
+			}
+		}
+	}
+}